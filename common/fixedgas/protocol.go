@@ -158,4 +158,13 @@ const (
 	// EIP-3860 to limit size of initcode
 	MaxInitCodeSize = 2 * MaxCodeSize // Maximum initcode to permit in a creation transaction and create instructions
 	InitCodeWordGas = 2
+
+	// EIP-4844: Shard Blob Transactions
+	FieldElementsPerBlob        = 4096                              // Number of field elements in a blob
+	BlobSize                    = FieldElementsPerBlob * 32         // Size of a blob in bytes, each field element is 32 bytes
+	BlobGasPerBlob       uint64 = 1 << 17                           // Gas consumption of a single data blob (== blob byte size)
+	MaxBlobsPerBlock     uint64 = 6                                 // Maximum number of data blobs in a single block
+	MaxBlobGasPerBlock   uint64 = MaxBlobsPerBlock * BlobGasPerBlob // Maximum consumable blob gas for data blobs per block
+	MinBlobGasPrice      uint64 = 1                                 // Minimum price for blob gas, fee per blob gas can never go below it
+	BlobVersionedHashKZG byte   = 0x01                              // Version byte of the versioned hash of KZG commitment
 )
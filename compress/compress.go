@@ -47,6 +47,12 @@ import (
 // `Next` or `NextUncompressed` function on the decompressor.
 // After that, `Compress` function needs to be called to perform the compression
 // and eventually create output file
+//
+// Output is reproducible: it depends only on added words, minPatternScore and seed dictionary, not on amount of
+// workers, scheduling or timing of tmp files - per-worker results are merged by commutative operations
+// (pattern scores and uses are summed, words are re-ordered by their number) and workers don't carry
+// state between superstrings. It's what makes info-hashes of independently built snapshots equal,
+// please keep this property (see TestCompressReproducible).
 type Compressor struct {
 	ctx              context.Context
 	wg               *sync.WaitGroup
@@ -65,6 +71,10 @@ type Compressor struct {
 	wordsCount       uint64
 	superstringCount uint64
 	superstringLen   int
+	superstringLimit int         // see superstringLimit const, can be decreased in tests
+	seedDict         *Dictionary // see SeedDictionary and ReuseDictionary
+	reuseDict        bool
+	dict             *Dictionary // dictionary used by last Compress
 	workers          int
 	Ratio            CompressionRatio
 	lvl              log.Lvl
@@ -111,6 +121,7 @@ func NewCompressor(ctx context.Context, logPrefix, outputFile, tmpDir string, mi
 		suffixCollectors: suffixCollectors,
 		lvl:              lvl,
 		wg:               wg,
+		superstringLimit: superstringLimit,
 	}, nil
 }
 
@@ -136,8 +147,11 @@ func (c *Compressor) AddWord(word []byte) error {
 	}
 
 	c.wordsCount++
+	if c.reuseDict { // no need to sample words - dictionary is given
+		return c.uncompressedFile.Append(word)
+	}
 	l := 2*len(word) + 2
-	if c.superstringLen+l > superstringLimit {
+	if c.superstringLen+l > c.superstringLimit {
 		if c.superstringCount%samplingFactor == 0 {
 			c.superstrings <- c.superstring
 		}
@@ -182,11 +196,14 @@ func (c *Compressor) Compress() error {
 		log.Log(c.lvl, fmt.Sprintf("[%s] BuildDict start", c.logPrefix), "workers", c.workers)
 	}
 	t := time.Now()
-	db, err := DictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.lvl)
-	if err != nil {
-
+	var db *DictionaryBuilder
+	var err error
+	if c.reuseDict {
+		db = c.seedDict.builder()
+	} else if db, err = dictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.seedDict, c.lvl); err != nil {
 		return err
 	}
+	c.dict = dictionaryFromBuilder(db)
 	if c.trace {
 		_, fileName := filepath.Split(c.outputFile)
 		if err := PersistDictrionary(filepath.Join(c.tmpDir, fileName)+".dictionary.txt", db); err != nil {
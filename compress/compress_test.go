@@ -18,9 +18,11 @@ package compress
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"hash/crc32"
 	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
@@ -166,3 +168,92 @@ func TestCompressDict1(t *testing.T) {
 		t.Errorf("result file hash changed, %d", cs)
 	}
 }
+
+func TestCompressReproducible(t *testing.T) {
+	tmpDir := t.TempDir()
+	compress := func(workers int) [32]byte {
+		file := filepath.Join(tmpDir, fmt.Sprintf("compressed%d", workers))
+		c, err := NewCompressor(context.Background(), t.Name(), file, tmpDir, 1, workers, log.LvlDebug)
+		require.NoError(t, err)
+		defer c.Close()
+		c.superstringLimit = 4 * 1024 // many superstrings - to get work for all workers
+		r := rand.New(rand.NewSource(42))
+		for i := 0; i < 20_000; i++ {
+			word := []byte(fmt.Sprintf("%d longlongword %d %x", i%17, i, r.Uint32()%1024))
+			switch i % 10 {
+			case 0:
+				require.NoError(t, c.AddWord(nil))
+			case 1:
+				require.NoError(t, c.AddUncompressedWord(word))
+			default:
+				require.NoError(t, c.AddWord(word))
+			}
+		}
+		require.NoError(t, c.Compress())
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		return sha256.Sum256(data)
+	}
+	expected := compress(1)
+	for _, workers := range []int{2, 3, 8} {
+		require.Equal(t, expected, compress(workers), "workers=%d", workers)
+	}
+}
+
+func TestCompressDictionaryReuse(t *testing.T) {
+	tmpDir := t.TempDir()
+	words := func(from int) [][]byte {
+		var res [][]byte
+		for i := from; i < from+1_000; i++ {
+			res = append(res, []byte(fmt.Sprintf("%d longlongword %d", i%31, i)))
+		}
+		return res
+	}
+	compress := func(name string, words [][]byte, setDict func(c *Compressor)) (*Decompressor, *Dictionary) {
+		file := filepath.Join(tmpDir, name)
+		c, err := NewCompressor(context.Background(), t.Name(), file, tmpDir, 1, 2, log.LvlDebug)
+		require.NoError(t, err)
+		defer c.Close()
+		setDict(c)
+		for _, w := range words {
+			require.NoError(t, c.AddWord(w))
+		}
+		require.NoError(t, c.Compress())
+		d, err := NewDecompressor(file)
+		require.NoError(t, err)
+		t.Cleanup(func() { d.Close() })
+		return d, c.Dictionary()
+	}
+	check := func(d *Decompressor, words [][]byte) {
+		t.Helper()
+		g := d.MakeGetter()
+		for _, w := range words {
+			require.True(t, g.HasNext())
+			word, _ := g.Next(nil)
+			require.Equal(t, w, word)
+		}
+		require.False(t, g.HasNext())
+	}
+
+	d1, dict := compress("seg1", words(0), func(c *Compressor) {})
+	check(d1, words(0))
+	require.NotZero(t, dict.Len())
+
+	dictFile := filepath.Join(tmpDir, "seg1.dict")
+	require.NoError(t, dict.Save(dictFile))
+	loaded, err := LoadDictionary(dictFile)
+	require.NoError(t, err)
+	require.Equal(t, dict, loaded)
+
+	// .seg file is self-contained: dictionary file is not needed for decompression
+	require.NoError(t, os.Remove(dictFile))
+	d2, reused := compress("seg2", words(1_000), func(c *Compressor) { c.ReuseDictionary(loaded) })
+	check(d2, words(1_000))
+	require.Equal(t, loaded, reused)
+
+	d3, seeded := compress("seg3", words(2_000), func(c *Compressor) { c.SeedDictionary(loaded) })
+	check(d3, words(2_000))
+	require.NotZero(t, seeded.Len())
+	_, seeded2 := compress("seg4", words(2_000), func(c *Compressor) { c.SeedDictionary(loaded) })
+	require.Equal(t, seeded, seeded2)
+}
@@ -110,6 +110,7 @@ type Decompressor struct {
 	modTime         time.Time
 	wordsCount      uint64
 	emptyWordsCount uint64
+	offsetIndex     *OffsetIndex // optional, see BuildOffsetIndex
 
 	filePath, fileName string
 }
@@ -400,10 +401,12 @@ func (d *Decompressor) EnableWillNeed() *Decompressor {
 type Getter struct {
 	patternDict *patternTable
 	posDict     *posTable
+	offsetIndex *OffsetIndex
 	fName       string
 	data        []byte
 	dataP       uint64
 	dataBit     int // Value 0..7 - position of the bit
+	wordsCount  uint64
 	trace       bool
 }
 
@@ -512,6 +515,8 @@ func (d *Decompressor) MakeGetter() *Getter {
 		posDict:     d.posDict,
 		data:        d.data[d.wordsStart:],
 		patternDict: d.dict,
+		offsetIndex: d.offsetIndex,
+		wordsCount:  d.wordsCount,
 		fName:       d.fileName,
 	}
 }
@@ -579,9 +584,10 @@ func (g *Getter) Next(buf []byte) ([]byte, uint64) {
 		}
 		lastUncovered = bufPos + len(g.nextPattern())
 	}
-	if int(wordLen) > lastUncovered {
-		dif := wordLen - uint64(lastUncovered)
-		copy(buf[lastUncovered:wordLen], g.data[postLoopPos:postLoopPos+dif])
+	// word is appended to buf, so it ends at the end of buf (not at wordLen)
+	if wordEnd := len(buf); wordEnd > lastUncovered {
+		dif := uint64(wordEnd - lastUncovered)
+		copy(buf[lastUncovered:wordEnd], g.data[postLoopPos:postLoopPos+dif])
 		postLoopPos += dif
 	}
 	g.dataP = postLoopPos
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -254,6 +255,87 @@ func TestUncompressed(t *testing.T) {
 	}
 }
 
+func TestDecompressWordByOrdinal(t *testing.T) {
+	d := prepareLoremDict(t)
+	defer d.Close()
+	for _, step := range []uint64{0, 1, 3, 1000} {
+		if step > 0 {
+			d.BuildOffsetIndex(step)
+			require.True(t, d.HasOffsetIndex())
+		}
+		for i := range loremStrings {
+			word, err := d.Word(uint64(i), nil)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("%s %d", loremStrings[i], i), string(word))
+		}
+		_, err := d.Word(uint64(d.Count()), nil)
+		require.Error(t, err)
+	}
+
+	var words []string
+	err := d.ForEachInRange(5, 10, func(i uint64, word []byte) error {
+		require.Equal(t, fmt.Sprintf("%s %d", loremStrings[i], i), string(word))
+		words = append(words, string(word))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(words))
+	require.Error(t, d.ForEachInRange(5, uint64(d.Count())+1, func(uint64, []byte) error { return nil }))
+}
+
+func TestDecompressWordsStream(t *testing.T) {
+	d := prepareLoremDict(t)
+	defer d.Close()
+
+	// export whole file, small reads must not break words encoding
+	r, err := d.WordsReader(0, uint64(d.Count()))
+	require.NoError(t, err)
+	var stream bytes.Buffer
+	buf := make([]byte, 3)
+	for {
+		n, err := r.Read(buf)
+		stream.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	// import into new file
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "rebuilt")
+	c, err := NewCompressor(context.Background(), t.Name(), file, tmpDir, 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.ImportWords(bytes.NewReader(stream.Bytes()), true))
+	require.NoError(t, c.Compress())
+	d2, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d2.Close()
+	require.Equal(t, d.Count(), d2.Count())
+	g := d2.MakeGetter()
+	for i := 0; g.HasNext(); i++ {
+		word, _ := g.Next(nil)
+		require.Equal(t, fmt.Sprintf("%s %d", loremStrings[i], i), string(word))
+	}
+
+	// export of range matches words of the range
+	r, err = d.WordsReader(3, 7)
+	require.NoError(t, err)
+	var i uint64 = 3
+	err = ReadWords(r, func(word []byte) error {
+		require.Equal(t, fmt.Sprintf("%s %d", loremStrings[i], i), string(word))
+		i++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), i)
+
+	// truncated stream
+	err = ReadWords(bytes.NewReader(stream.Bytes()[:stream.Len()-1]), func([]byte) error { return nil })
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
 const lorem = `Lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et
 dolore magna aliqua Ut enim ad minim veniam quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo
 consequat Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur
@@ -0,0 +1,164 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ledgerwatch/erigon-lib/etl"
+)
+
+// Dictionary - patterns (with their scores) chosen by DictionaryBuilder for one segment.
+// Building of dictionary is the most expensive part of compression, and segments of same type
+// (for example, block ranges of headers) have very similar dictionaries - so dictionary of one segment
+// can be exported by Compressor.Dictionary and given to compressors of next segments:
+//   - Compressor.SeedDictionary - dictionary is still built, but scores of seed patterns are added to scores of sampled patterns
+//   - Compressor.ReuseDictionary - dictionary building is skipped, seed patterns are used as is
+//
+// Dictionary is only input of compression: .seg file still contains all patterns it uses,
+// so Decompressor doesn't need dictionary file.
+type Dictionary struct {
+	patterns []*Pattern // sorted by dictionaryBuilderLess (ascending)
+}
+
+func (d *Dictionary) Len() int { return len(d.patterns) }
+
+// ForEach - visits patterns in order of decreasing score
+func (d *Dictionary) ForEach(f func(score uint64, word []byte)) {
+	for i := len(d.patterns); i > 0; i-- {
+		f(d.patterns[i-1].score, d.patterns[i-1].word)
+	}
+}
+
+// builder - makes DictionaryBuilder which can be consumed by reducedict (it closes builder after use)
+func (d *Dictionary) builder() *DictionaryBuilder {
+	items := make([]*Pattern, len(d.patterns))
+	copy(items, d.patterns)
+	return &DictionaryBuilder{items: items, limit: maxDictPatterns}
+}
+
+func dictionaryFromBuilder(db *DictionaryBuilder) *Dictionary {
+	patterns := make([]*Pattern, len(db.items))
+	for i, p := range db.items {
+		patterns[i] = &Pattern{word: p.word, score: p.score}
+	}
+	return &Dictionary{patterns: patterns}
+}
+
+// Save - persists dictionary as sequence of: uvarint(score), uvarint(len(word)), word.
+// File is written to fileName+".tmp" and renamed, so partially written dictionary never appears.
+func (d *Dictionary) Save(fileName string) error {
+	tmpFileName := fileName + ".tmp"
+	defer os.Remove(tmpFileName)
+	f, err := os.Create(tmpFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, etl.BufIOSize)
+	var numBuf [binary.MaxVarintLen64]byte
+	for i := len(d.patterns); i > 0; i-- {
+		p := d.patterns[i-1]
+		n := binary.PutUvarint(numBuf[:], p.score)
+		if _, err = w.Write(numBuf[:n]); err != nil {
+			return err
+		}
+		n = binary.PutUvarint(numBuf[:], uint64(len(p.word)))
+		if _, err = w.Write(numBuf[:n]); err != nil {
+			return err
+		}
+		if _, err = w.Write(p.word); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFileName, fileName)
+}
+
+// LoadDictionary - reads dictionary persisted by Dictionary.Save
+func LoadDictionary(fileName string) (*Dictionary, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, etl.BufIOSize)
+	d := &Dictionary{}
+	for {
+		score, err := binary.ReadUvarint(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%w: dictionary %s: reading score", err, fileName)
+		}
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dictionary %s: reading pattern length", unexpectedEOF(err), fileName)
+		}
+		if l > superstringLimit {
+			return nil, fmt.Errorf("dictionary %s: pattern length %d is too big", fileName, l)
+		}
+		word := make([]byte, l)
+		if _, err = io.ReadFull(r, word); err != nil {
+			return nil, fmt.Errorf("%w: dictionary %s: reading pattern", unexpectedEOF(err), fileName)
+		}
+		d.patterns = append(d.patterns, &Pattern{word: word, score: score})
+	}
+	if len(d.patterns) > maxDictPatterns {
+		return nil, fmt.Errorf("dictionary %s: has %d patterns, max is %d", fileName, len(d.patterns), maxDictPatterns)
+	}
+	slices.SortFunc(d.patterns, dictionaryBuilderLess)
+	return d, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// SeedDictionary - scores of patterns of given dictionary are added to scores of patterns
+// sampled from words of this compressor. Doesn't change the format of output file.
+func (c *Compressor) SeedDictionary(d *Dictionary) {
+	c.seedDict, c.reuseDict = d, false
+}
+
+// ReuseDictionary - patterns of given dictionary are used as is, and words are not sampled at all.
+// Must be called before first AddWord.
+func (c *Compressor) ReuseDictionary(d *Dictionary) {
+	c.seedDict, c.reuseDict = d, true
+}
+
+// Dictionary - returns dictionary used by the last Compress call, nil if Compress wasn't called
+func (c *Compressor) Dictionary() *Dictionary { return c.dict }
@@ -804,6 +804,7 @@ func processSuperstring(superstringCh chan []byte, dictCollector *etl.Collector,
 			   defined for this substring, we put zero. */
 			if inv[i] == int32(n-1) {
 				k = 0
+				lcp[inv[i]] = 0 // lcp is re-used between superstrings, stale value would make scores depend on previous superstring
 				continue
 			}
 
@@ -917,6 +918,11 @@ func processSuperstring(superstringCh chan []byte, dictCollector *etl.Collector,
 }
 
 func DictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir string, collectors []*etl.Collector, lvl log.Lvl) (*DictionaryBuilder, error) {
+	return dictionaryBuilderFromCollectors(ctx, logPrefix, tmpDir, collectors, nil, lvl)
+}
+
+// dictionaryBuilderFromCollectors - if seed is not nil, scores of its patterns are added to scores of collected patterns
+func dictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir string, collectors []*etl.Collector, seed *Dictionary, lvl log.Lvl) (*DictionaryBuilder, error) {
 	dictCollector := etl.NewCollector(logPrefix+"_collectDict", tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer dictCollector.Close()
 	dictCollector.LogLvl(lvl)
@@ -931,6 +937,13 @@ func DictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir stri
 	if err := dictAggregator.finish(); err != nil {
 		return nil, err
 	}
+	if seed != nil {
+		for _, p := range seed.patterns {
+			if err := dictAggregator.processWord(p.word, p.score); err != nil {
+				return nil, err
+			}
+		}
+	}
 	db := &DictionaryBuilder{limit: maxDictPatterns} // Only collect 1m words with highest scores
 	if err := dictCollector.Load(nil, "", db.loadFunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
 		return nil, err
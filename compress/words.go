@@ -0,0 +1,204 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// OffsetIndex - optional in-memory index of word offsets of Decompressor. It keeps offset of every
+// step-th word, so ordinal access to any word costs at most step-1 skips.
+type OffsetIndex struct {
+	offsets []uint64
+	step    uint64
+}
+
+// BuildOffsetIndex - scans all words of the file and attaches OffsetIndex to decompressor.
+// step=1 stores offset of every word (8 bytes per word), bigger step trades memory for skips.
+// Must be called before making getters, which will use the index.
+func (d *Decompressor) BuildOffsetIndex(step uint64) {
+	if step == 0 {
+		step = 1
+	}
+	idx := &OffsetIndex{step: step, offsets: make([]uint64, 0, d.wordsCount/step+1)}
+	g := d.MakeGetter()
+	var offset uint64
+	for i := uint64(0); g.HasNext(); i++ {
+		if i%step == 0 {
+			idx.offsets = append(idx.offsets, offset)
+		}
+		offset = g.Skip()
+	}
+	d.offsetIndex = idx
+}
+
+func (d *Decompressor) HasOffsetIndex() bool { return d.offsetIndex != nil }
+
+// ResetToWord moves getter to the beginning of the i-th word (counting from 0). Uses offset index
+// of decompressor if it was built, otherwise skips words from the beginning of the file.
+func (g *Getter) ResetToWord(i uint64) error {
+	if i > g.wordsCount {
+		return fmt.Errorf("word %d is out of range, file %s has %d words", i, g.fName, g.wordsCount)
+	}
+	var from uint64
+	if idx := g.offsetIndex; idx != nil && len(idx.offsets) > 0 {
+		k := i / idx.step
+		if k >= uint64(len(idx.offsets)) { // i == wordsCount
+			k = uint64(len(idx.offsets)) - 1
+		}
+		from = k * idx.step
+		g.Reset(idx.offsets[k])
+	} else {
+		g.Reset(0)
+	}
+	for ; from < i; from++ {
+		g.Skip()
+	}
+	return nil
+}
+
+// Word - extracts i-th word (counting from 0) and appends it to the given buf
+func (d *Decompressor) Word(i uint64, buf []byte) ([]byte, error) {
+	if i >= d.wordsCount {
+		return nil, fmt.Errorf("word %d is out of range, file %s has %d words", i, d.fileName, d.wordsCount)
+	}
+	g := d.MakeGetter()
+	if err := g.ResetToWord(i); err != nil {
+		return nil, err
+	}
+	buf, _ = g.Next(buf)
+	return buf, nil
+}
+
+// ForEachInRange - calls f for words with ordinal numbers in [from, to). Word passed to f is valid
+// only until f returns.
+func (d *Decompressor) ForEachInRange(from, to uint64, f func(i uint64, word []byte) error) error {
+	if from > to || to > d.wordsCount {
+		return fmt.Errorf("range [%d, %d) is out of range, file %s has %d words", from, to, d.fileName, d.wordsCount)
+	}
+	g := d.MakeGetter()
+	if err := g.ResetToWord(from); err != nil {
+		return err
+	}
+	var word []byte
+	for i := from; i < to; i++ {
+		word, _ = g.Next(word[:0])
+		if err := f(i, word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WordsReader - io.Reader over words [from, to) of decompressor, producing length-prefixed stream:
+// each word is encoded as uvarint of its length followed by the word itself.
+// The stream can be turned back into a segment by Compressor.ImportWords
+type WordsReader struct {
+	g     *Getter
+	i, to uint64
+	buf   []byte // encoded current word
+	pos   int    // position in buf of the first not yet read byte
+}
+
+func (d *Decompressor) WordsReader(from, to uint64) (*WordsReader, error) {
+	if from > to || to > d.wordsCount {
+		return nil, fmt.Errorf("range [%d, %d) is out of range, file %s has %d words", from, to, d.fileName, d.wordsCount)
+	}
+	g := d.MakeGetter()
+	if err := g.ResetToWord(from); err != nil {
+		return nil, err
+	}
+	return &WordsReader{g: g, i: from, to: to, buf: make([]byte, 0, 256)}, nil
+}
+
+func (r *WordsReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if r.pos == len(r.buf) {
+			if r.i >= r.to {
+				if n == 0 {
+					return 0, io.EOF
+				}
+				return n, nil
+			}
+			r.nextWord()
+		}
+		copied := copy(p[n:], r.buf[r.pos:])
+		r.pos += copied
+		n += copied
+	}
+	return n, nil
+}
+
+// nextWord - decompresses next word right after space reserved for its length, then puts length
+// right before the word: to avoid copying of the word
+func (r *WordsReader) nextWord() {
+	r.buf, _ = r.g.Next(r.buf[:binary.MaxVarintLen64])
+	wordLen := uint64(len(r.buf) - binary.MaxVarintLen64)
+	var lenBuf [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(lenBuf[:], wordLen)
+	r.pos = binary.MaxVarintLen64 - l
+	copy(r.buf[r.pos:], lenBuf[:l])
+	r.i++
+}
+
+// ReadWords - reads length-prefixed stream of words (as produced by WordsReader) and calls f
+// for each word. Word passed to f is valid only until f returns.
+func ReadWords(r io.Reader, f func(word []byte) error) error {
+	br, ok := r.(interface {
+		io.Reader
+		io.ByteReader
+	})
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	var word []byte
+	for {
+		l, err := binary.ReadUvarint(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading word length: %w", err)
+		}
+		if uint64(cap(word)) < l {
+			word = make([]byte, l)
+		}
+		word = word[:l]
+		if _, err = io.ReadFull(br, word); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("reading word of length %d: %w", l, err)
+		}
+		if err = f(word); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportWords - adds all words of length-prefixed stream (as produced by WordsReader) to compressor.
+// Compressed file doesn't track which words were compressed, so it's up to caller to know it.
+func (c *Compressor) ImportWords(r io.Reader, compressed bool) error {
+	if compressed {
+		return ReadWords(r, c.AddWord)
+	}
+	return ReadWords(r, c.AddUncompressedWord)
+}
@@ -0,0 +1,59 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kzg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+)
+
+var (
+	ctx              *gokzg4844.Context
+	ctxErr           error
+	initCtx          sync.Once
+	trustedSetupFile string
+)
+
+// SetTrustedSetupFilePath - json file with KZG trusted setup, must be called before first use of Ctx.
+// Without it - test setup of go-kzg-4844 is used (secret is known, suitable only for devnets)
+func SetTrustedSetupFilePath(path string) { trustedSetupFile = path }
+
+// Ctx - lazily initialized KZG context (parsing of trusted setup takes seconds)
+func Ctx() (*gokzg4844.Context, error) {
+	initCtx.Do(func() {
+		if trustedSetupFile == "" {
+			ctx, ctxErr = gokzg4844.NewContext4096Insecure1337()
+			return
+		}
+		file, err := os.ReadFile(trustedSetupFile)
+		if err != nil {
+			ctxErr = fmt.Errorf("kzg: reading trusted setup: %w", err)
+			return
+		}
+		setup := &gokzg4844.JSONTrustedSetup{}
+		if err = json.Unmarshal(file, setup); err != nil {
+			ctxErr = fmt.Errorf("kzg: parsing trusted setup %s: %w", trustedSetupFile, err)
+			return
+		}
+		ctx, ctxErr = gokzg4844.NewContext4096(setup)
+	})
+	return ctx, ctxErr
+}
@@ -22,6 +22,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -84,6 +86,16 @@ func New(ctx context.Context, cfg *downloadercfg.Cfg) (*Downloader, error) {
 	if err := moveFromTmp(cfg.DataDir); err != nil {
 		return nil, err
 	}
+	webSeeds := make([]string, len(cfg.WebSeeds))
+	for i, u := range cfg.WebSeeds {
+		if !strings.HasSuffix(u, "/") { // BEP 19: file path is appended only to url with trailing slash
+			u += "/"
+		}
+		webSeeds[i] = u
+	}
+	cfgCopy := *cfg // don't modify caller's cfg
+	cfg = &cfgCopy
+	cfg.WebSeeds = webSeeds
 
 	db, c, m, torrentClient, err := openClient(cfg.ClientConfig)
 	if err != nil {
@@ -140,6 +152,7 @@ func (d *Downloader) mainLoop(ctx context.Context, silent bool) {
 				if err := sem.Acquire(ctx, 1); err != nil {
 					return
 				}
+				addWebSeeds(t, d.cfg.WebSeeds)
 				t.AllowDataDownload()
 				t.DownloadAll()
 				go func(t *torrent.Torrent) {
@@ -302,16 +315,59 @@ func moveFromTmp(snapDir string) error {
 	return nil
 }
 
-func (d *Downloader) verify() error {
-	total := 0
+// verify - verifies files by piece hashes, files which were not changed since previous verification are not re-hashed.
+// names - files to verify (all if empty)
+func (d *Downloader) verify(ctx context.Context, names []string) ([]VerifyResult, error) {
+	torrents := map[string]*torrent.Torrent{}
 	for _, t := range d.torrentClient.Torrents() {
 		select {
 		case <-t.GotInfo():
-			total += t.NumPieces()
+			torrents[t.Info().Name] = t
 		default:
 			continue
 		}
 	}
+	if len(names) == 0 {
+		for name := range torrents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	results := make([]VerifyResult, len(names))
+	var toVerify []int
+	total := 0
+	if err := d.db.View(ctx, func(tx kv.Tx) error {
+		for i, name := range names {
+			t, ok := torrents[name]
+			if !ok {
+				return fmt.Errorf("verify: %s has no torrent or its metadata", name)
+			}
+			fi, err := os.Stat(filepath.Join(d.SnapDir(), name))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			prev, err := readVerifyResult(tx, name)
+			if err != nil {
+				return err
+			}
+			if prev != nil && prev.unchanged(t.InfoHash(), fi) {
+				prev.Cached = true
+				results[i] = *prev
+				continue
+			}
+			results[i] = VerifyResult{Name: name, InfoHash: t.InfoHash(), Pieces: t.NumPieces()}
+			if fi != nil {
+				results[i].Size, results[i].ModTime = fi.Size(), fi.ModTime()
+			}
+			toVerify = append(toVerify, i)
+			total += t.NumPieces()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
 	logInterval := 20 * time.Second
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
@@ -319,14 +375,16 @@ func (d *Downloader) verify() error {
 	wg := &sync.WaitGroup{}
 	j := atomic.Int64{}
 
-	for _, t := range d.torrentClient.Torrents() {
+	for _, i := range toVerify {
 		wg.Add(1)
-		go func(t *torrent.Torrent) {
+		go func(r *VerifyResult, t *torrent.Torrent) {
 			defer wg.Done()
-			<-t.GotInfo()
 			for i := 0; i < t.NumPieces(); i++ {
 				j.Add(1)
 				t.Piece(i).VerifyData()
+				if !t.PieceState(i).Complete {
+					r.BadPieces++
+				}
 
 				select {
 				case <-logEvery.C:
@@ -335,30 +393,45 @@ func (d *Downloader) verify() error {
 				}
 				//<-t.Complete.On()
 			}
-		}(t)
+			r.VerifiedAt = time.Now()
+		}(&results[i], torrents[names[i]])
 	}
 	wg.Wait()
 
-	// force fsync of db. to not loose results of validation on power-off
-	return d.db.Update(context.Background(), func(tx kv.RwTx) error { return nil })
+	// also forces fsync of db. to not loose results of validation on power-off
+	if err := d.db.Update(ctx, func(tx kv.RwTx) error {
+		for _, i := range toVerify {
+			if err := writeVerifyResult(tx, &results[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
 func (d *Downloader) addSegments() error {
 	logEvery := time.NewTicker(20 * time.Second)
 	defer logEvery.Stop()
-	_, err := BuildTorrentFilesIfNeed(context.Background(), d.SnapDir())
+	// unknown files are quarantined before .torrent files are built for them, mismatched - after
+	files, err := seedableFiles(d.SnapDir())
 	if err != nil {
 		return err
 	}
-	files, err := seedableSegmentFiles(d.SnapDir())
-	if err != nil {
-		return fmt.Errorf("seedableSegmentFiles: %w", err)
+	if _, err = d.applyManifest(files); err != nil {
+		return err
 	}
-	files2, err := seedableHistorySnapshots(d.SnapDir())
-	if err != nil {
-		return fmt.Errorf("seedableHistorySnapshots: %w", err)
+	if _, err = BuildTorrentFilesIfNeed(context.Background(), d.SnapDir()); err != nil {
+		return err
+	}
+	if files, err = seedableFiles(d.SnapDir()); err != nil {
+		return err
+	}
+	if files, err = d.applyManifest(files); err != nil {
+		return err
 	}
-	files = append(files, files2...)
 	wg := &sync.WaitGroup{}
 	i := atomic.Int64{}
 	for _, f := range files {
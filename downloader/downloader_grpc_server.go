@@ -19,10 +19,12 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/anacrolix/torrent"
 	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloader"
 	prototypes "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
@@ -50,6 +52,7 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 
 	torrentClient := s.d.Torrent()
 	snapDir := s.d.SnapDir()
+	manifest := s.d.cfg.Manifest
 	for i, it := range request.Items {
 		select {
 		case <-logEvery.C:
@@ -60,7 +63,7 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 		if it.TorrentHash == nil {
 			// if we dont have the torrent hash then we seed a new snapshot
 			log.Info("[snapshots] seeding a new snapshot")
-			ok, err := seedNewSnapshot(it, torrentClient, snapDir)
+			ok, err := seedNewSnapshot(it, torrentClient, snapDir, manifest)
 			if err != nil {
 				return nil, err
 			}
@@ -72,7 +75,7 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 			continue
 		}
 
-		_, err := createMagnetLinkWithInfoHash(it.TorrentHash, torrentClient, snapDir)
+		_, err := createMagnetLinkWithInfoHash(ctx, it.TorrentHash, it.Path, torrentClient, snapDir, manifest, s.d.cfg.WebSeeds)
 		if err != nil {
 			return nil, err
 		}
@@ -81,12 +84,18 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 	return &emptypb.Empty{}, nil
 }
 
-func (s *GrpcServer) Verify(ctx context.Context, request *proto_downloader.VerifyRequest) (*emptypb.Empty, error) {
-	err := s.d.verify()
+// Verify - verifies files of request (all files if request has no files), reports result of every file and files
+// which violate manifest
+func (s *GrpcServer) Verify(ctx context.Context, request *proto_downloader.VerifyRequest) (*proto_downloader.VerifyReply, error) {
+	violations, err := s.d.VerifyManifest()
 	if err != nil {
 		return nil, err
 	}
-	return &emptypb.Empty{}, nil
+	results, err := s.d.verify(ctx, request.Files)
+	if err != nil {
+		return nil, err
+	}
+	return verifyReply(violations, results), nil
 }
 
 func (s *GrpcServer) Stats(ctx context.Context, request *proto_downloader.StatsRequest) (*proto_downloader.StatsReply, error) {
@@ -115,11 +124,25 @@ func Proto2InfoHash(in *prototypes.H160) metainfo.Hash {
 // decides what we do depending on wether we have the .seg file or the .torrent file
 // have .torrent no .seg => get .seg file from .torrent
 // have .seg no .torrent => get .torrent from .seg
-func seedNewSnapshot(it *proto_downloader.DownloadItem, torrentClient *torrent.Client, snapDir string) (bool, error) {
+// files which violate manifest are refused
+func seedNewSnapshot(it *proto_downloader.DownloadItem, torrentClient *torrent.Client, snapDir string, manifest downloadercfg.Manifest) (bool, error) {
+	if manifest != nil && !manifest.Has(it.Path) {
+		log.Warn("[snapshots] refuse to seed", "err", fmt.Errorf("%w: %s", downloadercfg.ErrNotInManifest, it.Path))
+		return false, nil
+	}
 	// if we dont have the torrent file we build it if we have the .seg file
 	if err := buildTorrentIfNeed(it.Path, snapDir); err != nil {
 		return false, err
 	}
+	if manifest != nil {
+		if err := checkManifestFile(manifest, snapDir, it.Path); err != nil {
+			if !isManifestViolation(err) {
+				return false, err
+			}
+			log.Warn("[snapshots] refuse to seed", "err", err)
+			return false, nil
+		}
+	}
 
 	// we add the .seg file we have and create the .torrent file if we dont have it
 	ok, err := AddSegment(it.Path, snapDir, torrentClient)
@@ -137,12 +160,22 @@ func seedNewSnapshot(it *proto_downloader.DownloadItem, torrentClient *torrent.C
 }
 
 // we dont have .seg or .torrent so we get them through the torrent hash
-func createMagnetLinkWithInfoHash(hash *prototypes.H160, torrentClient *torrent.Client, snapDir string) (bool, error) {
+// info-hashes which are not in manifest are refused
+// .torrent of file (name from manifest, or path of request) is fetched from webseeds, otherwise metadata is received from peers
+func createMagnetLinkWithInfoHash(ctx context.Context, hash *prototypes.H160, path string, torrentClient *torrent.Client, snapDir string, manifest downloadercfg.Manifest, webSeeds []string) (bool, error) {
 	mi := &metainfo.MetaInfo{AnnounceList: Trackers}
 	if hash == nil {
 		return false, nil
 	}
 	infoHash := Proto2InfoHash(hash)
+	name := path
+	if manifest != nil {
+		var ok bool
+		if name, ok = manifest.ByInfoHash(infoHash); !ok {
+			log.Warn("[snapshots] refuse to download", "err", fmt.Errorf("%w: info-hash %x", downloadercfg.ErrNotInManifest, infoHash))
+			return false, nil
+		}
+	}
 	//log.Debug("[downloader] downloading torrent and seg file", "hash", infoHash)
 
 	if _, ok := torrentClient.Torrent(infoHash); ok {
@@ -150,6 +183,22 @@ func createMagnetLinkWithInfoHash(hash *prototypes.H160, torrentClient *torrent.
 		return true, nil
 	}
 
+	if name != "" && len(webSeeds) > 0 {
+		wsMi, err := fetchTorrentFile(ctx, webSeeds, name, infoHash)
+		if err == nil {
+			info, err := wsMi.UnmarshalInfo()
+			if err != nil {
+				return false, err
+			}
+			if err = CreateTorrentFileIfNotExists(snapDir, &info, wsMi); err != nil {
+				return false, err
+			}
+			_, err = AddTorrentFile(filepath.Join(snapDir, info.Name+".torrent"), torrentClient)
+			return false, err
+		}
+		log.Warn("[snapshots] fetch .torrent from webseeds", "file", name, "err", err)
+	}
+
 	magnet := mi.Magnet(&infoHash, nil)
 	t, err := torrentClient.AddMagnet(magnet.String())
 	if err != nil {
@@ -161,6 +210,13 @@ func createMagnetLinkWithInfoHash(hash *prototypes.H160, torrentClient *torrent.
 	go func(t *torrent.Torrent) {
 		<-t.GotInfo()
 
+		if manifest != nil {
+			if err := manifest.Check(t.Info().Name, t.InfoHash(), t.Info().TotalLength()); err != nil || t.Info().Name != name {
+				log.Warn("[snapshots] drop torrent", "name", t.Info().Name, "expected", name, "err", err)
+				t.Drop()
+				return
+			}
+		}
 		mi := t.Metainfo()
 		if err := CreateTorrentFileIfNotExists(snapDir, t.Info(), &mi); err != nil {
 			log.Warn("[downloader] create torrent file", "err", err)
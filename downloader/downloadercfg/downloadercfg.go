@@ -42,6 +42,10 @@ const DefaultNetworkChunkSize = 512 * 1024
 type Cfg struct {
 	*torrent.ClientConfig
 	DownloadSlots int
+	// Manifest - if set, only files of manifest are seeded and downloaded (see LoadManifest)
+	Manifest Manifest
+	// WebSeeds - base URLs of HTTP mirrors of snapshots dir of chain, pieces are downloaded from them if there are no peers
+	WebSeeds []string
 }
 
 func Default() *torrent.ClientConfig {
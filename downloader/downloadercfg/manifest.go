@@ -0,0 +1,95 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloadercfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// Manifest - signed-off list of snapshot files of chain: file name (relative to snapshots dir) -> info-hash and size.
+// Downloader seeds and downloads only files of manifest: unknown and mismatched files are moved to quarantine.
+// nil Manifest - all files are trusted. Manifest is provided by user of downloader (see LoadManifest and Cfg.Manifest).
+type Manifest map[string]ManifestItem
+
+type ManifestItem struct {
+	InfoHash metainfo.Hash `json:"hash"`
+	Size     int64         `json:"size"`
+}
+
+var (
+	ErrNotInManifest    = errors.New("file is not in manifest")
+	ErrManifestMismatch = errors.New("file doesn't match manifest")
+)
+
+func LoadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest - json object: {"<file name>": {"hash": "<hex of info-hash>", "size": <bytes>}, ...}
+func ParseManifest(data []byte) (Manifest, error) {
+	m := Manifest{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m Manifest) Has(name string) bool {
+	_, ok := m[name]
+	return ok
+}
+
+// CheckSize - cheap check of data file, before .torrent is built
+func (m Manifest) CheckSize(name string, size int64) error {
+	item, ok := m[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotInManifest, name)
+	}
+	if item.Size != size {
+		return fmt.Errorf("%w: %s has size %d, expected %d", ErrManifestMismatch, name, size, item.Size)
+	}
+	return nil
+}
+
+func (m Manifest) Check(name string, infoHash metainfo.Hash, size int64) error {
+	if err := m.CheckSize(name, size); err != nil {
+		return err
+	}
+	if expect := m[name].InfoHash; expect != infoHash {
+		return fmt.Errorf("%w: %s has info-hash %x, expected %x", ErrManifestMismatch, name, infoHash, expect)
+	}
+	return nil
+}
+
+// ByInfoHash - name of manifest's file with given info-hash
+func (m Manifest) ByInfoHash(infoHash metainfo.Hash) (string, bool) {
+	for name, item := range m {
+		if item.InfoHash == infoHash {
+			return name, true
+		}
+	}
+	return "", false
+}
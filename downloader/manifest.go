@@ -0,0 +1,129 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/anacrolix/torrent/metainfo"
+	dir2 "github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// QuarantineDir - sub-dir of snapshots dir, files which violate manifest are moved there (keeping relative path)
+const QuarantineDir = "quarantine"
+
+// ManifestViolation - file of snapshots dir which is unknown to manifest or doesn't match it
+type ManifestViolation struct {
+	Name string
+	Err  error
+}
+
+func isManifestViolation(err error) bool {
+	return errors.Is(err, downloadercfg.ErrNotInManifest) || errors.Is(err, downloadercfg.ErrManifestMismatch)
+}
+
+// checkManifestFile - data file must be in manifest and have same size, its .torrent (if exists) must have same info-hash
+func checkManifestFile(m downloadercfg.Manifest, snapDir, name string) error {
+	fPath := filepath.Join(snapDir, name)
+	st, err := os.Stat(fPath)
+	if err != nil {
+		return err
+	}
+	if err := m.CheckSize(name, st.Size()); err != nil {
+		return err
+	}
+	if !dir2.FileExist(fPath + ".torrent") {
+		return nil
+	}
+	mi, err := metainfo.LoadFromFile(fPath + ".torrent")
+	if err != nil {
+		return err
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return err
+	}
+	return m.Check(name, mi.HashInfoBytes(), info.TotalLength())
+}
+
+// quarantine - moves data file and its .torrent to QuarantineDir
+func quarantine(snapDir, name string) error {
+	for _, fName := range []string{name, name + ".torrent"} {
+		src := filepath.Join(snapDir, fName)
+		if !dir2.FileExist(src) {
+			continue
+		}
+		dst := filepath.Join(snapDir, QuarantineDir, fName)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyManifest - moves files which violate manifest to quarantine, returns rest of files
+func (d *Downloader) applyManifest(files []string) ([]string, error) {
+	if d.cfg.Manifest == nil {
+		return files, nil
+	}
+	res := make([]string, 0, len(files))
+	for _, f := range files {
+		err := checkManifestFile(d.cfg.Manifest, d.SnapDir(), f)
+		if err == nil {
+			res = append(res, f)
+			continue
+		}
+		if !isManifestViolation(err) {
+			return nil, err
+		}
+		log.Warn("[snapshots] quarantine", "file", f, "err", err)
+		if err := quarantine(d.SnapDir(), f); err != nil {
+			return nil, fmt.Errorf("quarantine %s: %w", f, err)
+		}
+	}
+	return res, nil
+}
+
+// VerifyManifest - checks seedable files of snapshots dir against manifest, files are not moved.
+// Files of manifest which are not downloaded yet are not violations.
+func (d *Downloader) VerifyManifest() ([]ManifestViolation, error) {
+	if d.cfg.Manifest == nil {
+		return nil, nil
+	}
+	files, err := seedableFiles(d.SnapDir())
+	if err != nil {
+		return nil, err
+	}
+	var violations []ManifestViolation
+	for _, f := range files {
+		if err := checkManifestFile(d.cfg.Manifest, d.SnapDir(), f); err != nil {
+			if !isManifestViolation(err) {
+				return nil, err
+			}
+			violations = append(violations, ManifestViolation{Name: f, Err: err})
+		}
+	}
+	return violations, nil
+}
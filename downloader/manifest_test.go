@@ -0,0 +1,78 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	snapDir := t.TempDir()
+	good, mismatched, unknown := snaptype.SegmentFileName(0, 500_000, snaptype.Headers), snaptype.SegmentFileName(0, 500_000, snaptype.Bodies), snaptype.SegmentFileName(500_000, 1_000_000, snaptype.Headers)
+	for _, name := range []string{good, mismatched, unknown} {
+		require.NoError(t, os.WriteFile(filepath.Join(snapDir, name), []byte(name), 0o644))
+	}
+	require.NoError(t, buildTorrentIfNeed(good, snapDir))
+	mi, err := metainfo.LoadFromFile(filepath.Join(snapDir, good+".torrent"))
+	require.NoError(t, err)
+
+	hash := mi.HashInfoBytes().HexString()
+	manifest, err := downloadercfg.ParseManifest([]byte(fmt.Sprintf(`{"%s": {"hash": "%s", "size": %d}, "%s": {"hash": "%s", "size": %d}}`,
+		good, hash, len(good), mismatched, hash, len(mismatched))))
+	require.NoError(t, err)
+	require.Equal(t, mi.HashInfoBytes(), manifest[good].InfoHash)
+
+	require.NoError(t, checkManifestFile(manifest, snapDir, good))
+	require.NoError(t, checkManifestFile(manifest, snapDir, mismatched)) // name and size are correct, no .torrent yet
+	require.ErrorIs(t, checkManifestFile(manifest, snapDir, unknown), downloadercfg.ErrNotInManifest)
+	require.NoError(t, buildTorrentIfNeed(mismatched, snapDir))
+	require.ErrorIs(t, checkManifestFile(manifest, snapDir, mismatched), downloadercfg.ErrManifestMismatch)
+
+	d := &Downloader{cfg: &downloadercfg.Cfg{ClientConfig: downloadercfg.Default(), Manifest: manifest}, clientLock: &sync.RWMutex{}}
+	d.cfg.DataDir = snapDir
+	violations, err := d.VerifyManifest()
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	got := verifyReply(violations, nil).ManifestViolations
+	require.Len(t, got, 2)
+	for i := range violations {
+		require.Equal(t, violations[i].Name, got[i].Name)
+		require.Equal(t, violations[i].Err.Error(), got[i].Error)
+	}
+
+	files, err := d.applyManifest([]string{good, mismatched, unknown})
+	require.NoError(t, err)
+	require.Equal(t, []string{good}, files)
+	require.True(t, dir.FileExist(filepath.Join(snapDir, good+".torrent")))
+	for _, name := range []string{mismatched, mismatched + ".torrent", unknown} {
+		require.False(t, dir.FileExist(filepath.Join(snapDir, name)))
+		require.True(t, dir.FileExist(filepath.Join(snapDir, QuarantineDir, name)))
+	}
+	violations, err = d.VerifyManifest()
+	require.NoError(t, err)
+	require.Empty(t, violations)
+}
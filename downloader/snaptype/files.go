@@ -30,59 +30,139 @@ import (
 
 type Type int
 
+// Types of Erigon, other types are added by RegisterType
 const (
 	Headers Type = iota
 	Bodies
 	Transactions
+	// NumberOfTypes - number of types of Erigon, returned by ParseFileType for unknown type. Types added by
+	// RegisterType follow it.
 	NumberOfTypes
 )
 
+type IdxType string
+
+const (
+	Transactions2Block IdxType = "transactions-to-block"
+)
+
+func (it IdxType) String() string { return string(it) }
+
+type typeInfo struct {
+	name     string
+	indices  []IdxType
+	versions []uint8 // ascending
+}
+
+var types = []typeInfo{
+	Headers:       {name: "headers", versions: []uint8{1}},
+	Bodies:        {name: "bodies", versions: []uint8{1}},
+	Transactions:  {name: "transactions", indices: []IdxType{Transactions2Block}, versions: []uint8{1}},
+	NumberOfTypes: {}, // not a type
+}
+
+// AllSnapshotTypes - types of Erigon
+var AllSnapshotTypes = []Type{Headers, Bodies, Transactions}
+
+// Types of BSC block data
+var (
+	Receipts        = RegisterType("receipts")
+	ParliaSnapshots = RegisterType("parlia") // kv.ParliaSnapshot
+	BlobSidecars    = RegisterType("blobsidecars")
+)
+
+// BscSnapshotTypes - types of BSC chains
+var BscSnapshotTypes = []Type{Headers, Bodies, Transactions, Receipts, ParliaSnapshots, BlobSidecars}
+
+// RegisterType - adds type of .seg files, files of registered types are parsed by ParseFileName. Each type has index
+// with name of type, extraIndices - additional indices of type (like Transactions2Block). Must be called on init.
+func RegisterType(name string, extraIndices ...IdxType) Type {
+	if name == "" || strings.ContainsAny(name, "-.") {
+		panic(fmt.Sprintf("invalid snapshot type name: %q", name))
+	}
+	if _, ok := ParseFileType(name); ok {
+		panic(fmt.Sprintf("snapshot type already registered: %s", name))
+	}
+	t := Type(len(types))
+	types = append(types, typeInfo{name: name, indices: extraIndices, versions: []uint8{1}})
+	return t
+}
+
 func (ft Type) String() string {
-	switch ft {
-	case Headers:
-		return "headers"
-	case Bodies:
-		return "bodies"
-	case Transactions:
-		return "transactions"
-	default:
+	if ft < 0 || int(ft) >= len(types) || ft == NumberOfTypes {
 		panic(fmt.Sprintf("unknown file type: %d", ft))
 	}
+	return types[ft].name
 }
 
-func ParseFileType(s string) (Type, bool) {
-	switch s {
-	case "headers":
-		return Headers, true
-	case "bodies":
-		return Bodies, true
-	case "transactions":
-		return Transactions, true
-	default:
-		return NumberOfTypes, false
+// Indices - indices of type, first is index with name of type
+func (ft Type) Indices() []IdxType {
+	return append([]IdxType{IdxType(ft.String())}, types[ft].indices...)
+}
+
+// SetVersions - versions of files of type which this binary can read (v1 by default), files of other versions are
+// ignored by ParseFileName. New files are written with latest version. Must be called on init.
+func SetVersions(ft Type, versions ...uint8) {
+	if len(versions) == 0 {
+		panic(fmt.Sprintf("no versions of snapshot type: %s", ft))
 	}
+	versions = slices.Clone(versions)
+	slices.Sort(versions)
+	types[ft].versions = slices.Compact(versions)
 }
 
-type IdxType string
+// Versions - versions of files of type which this binary can read, ascending
+func (ft Type) Versions() []uint8 { return types[ft].versions }
 
-const (
-	Transactions2Block IdxType = "transactions-to-block"
-)
+// Version - version of new files of type
+func (ft Type) Version() uint8 { return ft.Versions()[len(ft.Versions())-1] }
 
-func (it IdxType) String() string { return string(it) }
+func (ft Type) CanRead(version uint8) bool { return slices.Contains(ft.Versions(), version) }
 
-var AllSnapshotTypes = []Type{Headers, Bodies, Transactions}
+func ParseFileType(s string) (Type, bool) {
+	for t, info := range types {
+		if info.name != "" && info.name == s {
+			return Type(t), true
+		}
+	}
+	return NumberOfTypes, false
+}
 
 var (
 	ErrInvalidFileName = fmt.Errorf("invalid compressed file name")
 )
 
+// FileName - name of new file of type (or of index of type): with latest version of type, see FileNameV
 func FileName(from, to uint64, fileType string) string {
-	return fmt.Sprintf("v1-%06d-%06d-%s", from/1_000, to/1_000, fileType)
+	return FileNameV(fileVersion(fileType), from, to, fileType)
+}
+func FileNameV(version uint8, from, to uint64, fileType string) string {
+	return fmt.Sprintf("v%d-%06d-%06d-%s", version, from/1_000, to/1_000, fileType)
+}
+
+// fileVersion - latest version of type, fileType is name of type or of its index (same way as ParseFileName parses it)
+func fileVersion(fileType string) uint8 {
+	if t, ok := ParseFileType(strings.Split(fileType, "-")[0]); ok {
+		return t.Version()
+	}
+	return 1
+}
+
+// SegmentFileName - name of new segment of type: with latest version of type
+func SegmentFileName(from, to uint64, t Type) string {
+	return SegmentFileNameV(t.Version(), from, to, t)
+}
+func SegmentFileNameV(version uint8, from, to uint64, t Type) string {
+	return FileNameV(version, from, to, t.String()) + ".seg"
 }
-func SegmentFileName(from, to uint64, t Type) string   { return FileName(from, to, t.String()) + ".seg" }
 func DatFileName(from, to uint64, fType string) string { return FileName(from, to, fType) + ".dat" }
 func IdxFileName(from, to uint64, fType string) string { return FileName(from, to, fType) + ".idx" }
+func DatFileNameV(version uint8, from, to uint64, fType string) string {
+	return FileNameV(version, from, to, fType) + ".dat"
+}
+func IdxFileNameV(version uint8, from, to uint64, fType string) string {
+	return FileNameV(version, from, to, fType) + ".idx"
+}
 
 func FilterExt(in []FileInfo, expectExt string) (out []FileInfo) {
 	for _, f := range in {
@@ -118,7 +198,8 @@ func ParseFileName(dir, fileName string) (res FileInfo, err error) {
 	if len(parts) < 4 {
 		return res, fmt.Errorf("expected format: v1-001500-002000-bodies.seg got: %s. %w", fileName, ErrInvalidFileName)
 	}
-	if parts[0] != "v1" {
+	version, err := strconv.ParseUint(strings.TrimPrefix(parts[0], "v"), 10, 8)
+	if err != nil || !strings.HasPrefix(parts[0], "v") {
 		return res, fmt.Errorf("version: %s. %w", parts[0], ErrInvalidFileName)
 	}
 	from, err := strconv.ParseUint(parts[1], 10, 64)
@@ -129,22 +210,14 @@ func ParseFileName(dir, fileName string) (res FileInfo, err error) {
 	if err != nil {
 		return
 	}
-	var snapshotType Type
-	ft, ok := ParseFileType(parts[3])
+	snapshotType, ok := ParseFileType(parts[3])
 	if !ok {
-		return res, fmt.Errorf("unexpected snapshot suffix: %s,%w", parts[2], ErrInvalidFileName)
+		return res, fmt.Errorf("unexpected snapshot suffix: %s,%w", parts[3], ErrInvalidFileName)
 	}
-	switch ft {
-	case Headers:
-		snapshotType = Headers
-	case Bodies:
-		snapshotType = Bodies
-	case Transactions:
-		snapshotType = Transactions
-	default:
-		return res, fmt.Errorf("unexpected snapshot suffix: %s,%w", parts[2], ErrInvalidFileName)
+	if !snapshotType.CanRead(uint8(version)) {
+		return res, fmt.Errorf("unsupported version of %s: %s. %w", snapshotType, parts[0], ErrInvalidFileName)
 	}
-	return FileInfo{From: from * 1_000, To: to * 1_000, Path: filepath.Join(dir, fileName), T: snapshotType, Ext: ext}, nil
+	return FileInfo{Version: uint8(version), From: from * 1_000, To: to * 1_000, Path: filepath.Join(dir, fileName), T: snapshotType, Ext: ext}, nil
 }
 
 const Erigon3SeedableSteps = 32
@@ -159,6 +232,12 @@ type FileInfo struct {
 	T         Type
 }
 
+// unversionedName - name of file without version: same for all versions of file
+func (f FileInfo) unversionedName() string {
+	name := filepath.Base(f.Path)
+	return name[strings.IndexByte(name, '-'):]
+}
+
 func (f FileInfo) TorrentFileExists() bool { return dir.FileExist(f.Path + ".torrent") }
 func (f FileInfo) Seedable() bool          { return f.To-f.From == Erigon2SegmentSize }
 func (f FileInfo) NeedTorrentFile() bool   { return f.Seedable() && !f.TorrentFileExists() }
@@ -186,6 +265,8 @@ func TmpFiles(dir string) (res []string, err error) {
 }
 
 // ParseDir - reading dir (
+// if there are files of few versions for same range and type - only files of latest version of .seg are returned
+// (.idx of other versions don't match it), and of latest version if there is no .seg
 func ParseDir(dir string) (res []FileInfo, err error) {
 	files, err := os.ReadDir(dir)
 	if err != nil {
@@ -213,9 +294,6 @@ func ParseDir(dir string) (res []FileInfo, err error) {
 		res = append(res, meta)
 	}
 	slices.SortFunc(res, func(i, j FileInfo) bool {
-		if i.Version != j.Version {
-			return i.Version < j.Version
-		}
 		if i.From != j.From {
 			return i.From < j.From
 		}
@@ -225,7 +303,34 @@ func ParseDir(dir string) (res []FileInfo, err error) {
 		if i.T != j.T {
 			return i.T < j.T
 		}
-		return i.Ext < j.Ext
+		if i.Ext != j.Ext {
+			return i.Ext < j.Ext
+		}
+		if i.unversionedName() != j.unversionedName() {
+			return i.unversionedName() < j.unversionedName()
+		}
+		return i.Version > j.Version
+	})
+	type segKey struct {
+		from, to uint64
+		t        Type
+	}
+	segVersions := map[segKey]uint8{}
+	for _, f := range res {
+		if k := (segKey{f.From, f.To, f.T}); f.Ext == ".seg" && f.Version > segVersions[k] {
+			segVersions[k] = f.Version
+		}
+	}
+	filtered := res[:0]
+	for _, f := range res {
+		if v, ok := segVersions[segKey{f.From, f.To, f.T}]; ok && f.Version != v {
+			continue
+		}
+		filtered = append(filtered, f)
+	}
+	res = filtered
+	res = slices.CompactFunc(res, func(i, j FileInfo) bool {
+		return i.unversionedName() == j.unversionedName()
 	})
 
 	return res, nil
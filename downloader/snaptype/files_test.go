@@ -0,0 +1,111 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package snaptype
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileName(t *testing.T) {
+	for _, tt := range []Type{Headers, Bodies, Transactions, Receipts, ParliaSnapshots, BlobSidecars} {
+		f, err := ParseFileName("dir", SegmentFileName(500_000, 1_000_000, tt))
+		require.NoError(t, err)
+		require.Equal(t, tt, f.T)
+		require.Equal(t, uint64(500_000), f.From)
+		require.Equal(t, uint64(1_000_000), f.To)
+		require.Equal(t, ".seg", f.Ext)
+		require.True(t, f.Seedable())
+
+		for _, idx := range tt.Indices() {
+			f, err = ParseFileName("dir", IdxFileName(500_000, 1_000_000, idx.String()))
+			require.NoError(t, err)
+			require.Equal(t, tt, f.T)
+			require.Equal(t, ".idx", f.Ext)
+		}
+	}
+	require.Equal(t, []IdxType{"transactions", Transactions2Block}, Transactions.Indices())
+	require.Equal(t, []IdxType{"parlia"}, ParliaSnapshots.Indices())
+	require.Equal(t, []Type{Headers, Bodies, Transactions}, AllSnapshotTypes)
+	require.Contains(t, BscSnapshotTypes, BlobSidecars)
+	require.Greater(t, Receipts, NumberOfTypes)
+
+	_, err := ParseFileName("dir", "v1-000000-000500-unknown.seg")
+	require.ErrorIs(t, err, ErrInvalidFileName)
+	tt, ok := ParseFileType("unknown")
+	require.False(t, ok)
+	require.Equal(t, NumberOfTypes, tt)
+	_, ok = ParseFileType("")
+	require.False(t, ok)
+	require.Panics(t, func() { _ = NumberOfTypes.String() })
+
+	require.Panics(t, func() { RegisterType("receipts") })
+	require.Panics(t, func() { RegisterType("blob-sidecars") })
+}
+
+func TestVersions(t *testing.T) {
+	require.Equal(t, "v1-000000-000500-headers.seg", SegmentFileName(0, 500_000, Headers))
+	_, err := ParseFileName("dir", "v2-000000-000500-headers.seg")
+	require.ErrorIs(t, err, ErrInvalidFileName)
+	_, err = ParseFileName("dir", "x1-000000-000500-headers.seg")
+	require.ErrorIs(t, err, ErrInvalidFileName)
+
+	tt := RegisterType("versioned", "versioned-extra")
+	t.Cleanup(func() { types = types[:tt] })
+	SetVersions(tt, 3, 1, 2, 2)
+	require.Equal(t, []uint8{1, 2, 3}, tt.Versions())
+	require.Equal(t, uint8(3), tt.Version())
+	require.Equal(t, "v3-000000-000500-versioned.seg", SegmentFileName(0, 500_000, tt))
+	require.Equal(t, "v3-000000-000500-versioned-extra.idx", IdxFileName(0, 500_000, "versioned-extra"))
+	require.Equal(t, "v3-000000-000500-versioned.dat", DatFileName(0, 500_000, tt.String()))
+	require.Equal(t, "v1-000000-000500-headers.idx", IdxFileName(0, 500_000, Headers.String()))
+	SetVersions(tt, 1, 2)
+	f, err := ParseFileName("dir", SegmentFileNameV(2, 0, 500_000, tt))
+	require.NoError(t, err)
+	require.Equal(t, uint8(2), f.Version)
+	_, err = ParseFileName("dir", SegmentFileNameV(3, 0, 500_000, tt))
+	require.ErrorIs(t, err, ErrInvalidFileName)
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		SegmentFileNameV(1, 0, 500_000, tt), SegmentFileNameV(2, 0, 500_000, tt), SegmentFileNameV(3, 0, 500_000, tt), // v2 is preferred, v3 can't be read
+		IdxFileNameV(1, 0, 500_000, tt.String()), IdxFileNameV(2, 0, 500_000, tt.String()), // v1 doesn't match preferred .seg
+		IdxFileNameV(1, 0, 500_000, "versioned-extra"), IdxFileNameV(2, 0, 500_000, "versioned-extra"),
+		SegmentFileNameV(1, 500_000, 1_000_000, tt), IdxFileNameV(1, 500_000, 1_000_000, tt.String()),
+		IdxFileNameV(1, 1_000_000, 1_500_000, tt.String()), IdxFileNameV(2, 1_000_000, 1_500_000, tt.String()), // no .seg - latest
+		SegmentFileNameV(1, 0, 500_000, Headers),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{1}, 0o644))
+	}
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range segments {
+		names = append(names, filepath.Base(f.Path))
+	}
+	require.Equal(t, []string{"v1-000000-000500-headers.seg", "v2-000000-000500-versioned.seg", "v1-000500-001000-versioned.seg"}, names)
+	idx, err := IdxFiles(dir)
+	require.NoError(t, err)
+	names = nil
+	for _, f := range idx {
+		names = append(names, filepath.Base(f.Path))
+	}
+	require.Equal(t, []string{"v2-000000-000500-versioned-extra.idx", "v2-000000-000500-versioned.idx", "v1-000500-001000-versioned.idx", "v2-001000-001500-versioned.idx"}, names)
+}
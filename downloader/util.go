@@ -140,6 +140,19 @@ func seedableSegmentFiles(dir string) ([]string, error) {
 	return res, nil
 }
 
+// seedableFiles - seedable segments and history files
+func seedableFiles(dir string) ([]string, error) {
+	files, err := seedableSegmentFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("seedableSegmentFiles: %w", err)
+	}
+	files2, err := seedableHistorySnapshots(dir)
+	if err != nil {
+		return nil, fmt.Errorf("seedableHistorySnapshots: %w", err)
+	}
+	return append(files, files2...), nil
+}
+
 var historyFileRegex = regexp.MustCompile("^([[:lower:]]+).([0-9]+)-([0-9]+).(v|ef)$")
 
 func seedableHistorySnapshots(dir string) ([]string, error) {
@@ -396,7 +409,9 @@ func AddTorrentFile(torrentFilePath string, torrentClient *torrent.Client) (*tor
 
 var ErrSkip = fmt.Errorf("skip")
 
-func VerifyDtaFiles(ctx context.Context, snapDir string) error {
+// VerifyDtaFiles - verifies data files of all .torrent files of snapDir. If db (of downloader) is not nil: files which
+// were not changed since previous verification are not re-hashed, and results are stored (see VerifyResult)
+func VerifyDtaFiles(ctx context.Context, snapDir string, db kv.RwDB) error {
 	logEvery := time.NewTicker(5 * time.Second)
 	defer logEvery.Stop()
 
@@ -429,10 +444,37 @@ func VerifyDtaFiles(ctx context.Context, snapDir string) error {
 			return err
 		}
 
+		r := &VerifyResult{Name: info.Name, InfoHash: metaInfo.HashInfoBytes(), Pieces: info.NumPieces()}
+		if db != nil {
+			fi, err := os.Stat(filepath.Join(snapDir, info.Name))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			var prev *VerifyResult
+			if err = db.View(ctx, func(tx kv.Tx) (err error) {
+				prev, err = readVerifyResult(tx, info.Name)
+				return err
+			}); err != nil {
+				return err
+			}
+			if prev != nil && prev.unchanged(r.InfoHash, fi) {
+				j += info.NumPieces()
+				if !prev.Ok() {
+					failsAmount++
+					log.Error("[snapshots] Verify hash mismatch", "file", info.Name, "bad pieces", prev.BadPieces, "verified at", prev.VerifiedAt)
+				}
+				continue
+			}
+			if fi != nil {
+				r.Size, r.ModTime = fi.Size(), fi.ModTime()
+			}
+		}
+
 		if err = verifyTorrent(&info, snapDir, func(i int, good bool) error {
 			j++
 			if !good {
 				failsAmount++
+				r.BadPieces++ // verification of file stops at first bad piece
 				log.Error("[snapshots] Verify hash mismatch", "at piece", i, "file", info.Name)
 				return ErrSkip
 			}
@@ -444,12 +486,15 @@ func VerifyDtaFiles(ctx context.Context, snapDir string) error {
 			default:
 			}
 			return nil
-		}); err != nil {
-			if errors.Is(ErrSkip, err) {
-				continue
-			}
+		}); err != nil && !errors.Is(err, ErrSkip) {
 			return err
 		}
+		if db != nil {
+			r.VerifiedAt = time.Now()
+			if err = db.Update(ctx, func(tx kv.RwTx) error { return writeVerifyResult(tx, r) }); err != nil {
+				return err
+			}
+		}
 	}
 	if failsAmount > 0 {
 		return fmt.Errorf("not all files are valid")
@@ -0,0 +1,47 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTorrentFilesIfNeed(t *testing.T) {
+	snapDir := t.TempDir()
+	var expect []string
+	for _, tt := range snaptype.BscSnapshotTypes {
+		name := snaptype.SegmentFileName(0, 500_000, tt)
+		require.NoError(t, os.WriteFile(filepath.Join(snapDir, name), []byte(name), 0o644))
+		expect = append(expect, name)
+	}
+	// not seedable
+	require.NoError(t, os.WriteFile(filepath.Join(snapDir, snaptype.SegmentFileName(500_000, 501_000, snaptype.Receipts)), []byte{1}, 0o644))
+
+	files, err := BuildTorrentFilesIfNeed(context.Background(), snapDir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, expect, files)
+	for _, name := range expect {
+		require.True(t, dir.FileExist(filepath.Join(snapDir, name+".torrent")))
+	}
+}
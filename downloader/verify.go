@@ -0,0 +1,106 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/anacrolix/torrent/types/infohash"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloader"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// VerifyResult - result of verification of data file by piece hashes of its .torrent. Results are stored in
+// kv.BittorrentVerified: file which wasn't changed since verification (same size, mtime and info-hash) is not re-hashed.
+type VerifyResult struct {
+	Name       string
+	InfoHash   metainfo.Hash
+	Size       int64
+	ModTime    time.Time
+	VerifiedAt time.Time
+	Pieces     int
+	BadPieces  int  // wrong hash or not downloaded yet
+	Cached     bool // result of previous verification
+}
+
+func (r *VerifyResult) Ok() bool { return r.BadPieces == 0 }
+
+// unchanged - file has same stats as at moment of verification
+func (r *VerifyResult) unchanged(infoHash metainfo.Hash, fi os.FileInfo) bool {
+	return fi != nil && r.InfoHash == infoHash && r.Size == fi.Size() && r.ModTime.Equal(fi.ModTime())
+}
+
+const verifyResultLen = 8 + 8 + 8 + infohash.Size + 4 + 4
+
+func readVerifyResult(tx kv.Getter, name string) (*VerifyResult, error) {
+	v, err := tx.GetOne(kv.BittorrentVerified, []byte(name))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	if len(v) != verifyResultLen {
+		return nil, fmt.Errorf("verify result of %s: expected %d bytes, got %d", name, verifyResultLen, len(v))
+	}
+	r := &VerifyResult{
+		Name:       name,
+		Size:       int64(binary.BigEndian.Uint64(v)),
+		ModTime:    time.Unix(0, int64(binary.BigEndian.Uint64(v[8:]))),
+		VerifiedAt: time.Unix(int64(binary.BigEndian.Uint64(v[16:])), 0),
+		Pieces:     int(binary.BigEndian.Uint32(v[24+infohash.Size:])),
+		BadPieces:  int(binary.BigEndian.Uint32(v[28+infohash.Size:])),
+	}
+	copy(r.InfoHash[:], v[24:])
+	return r, nil
+}
+
+func writeVerifyResult(tx kv.Putter, r *VerifyResult) error {
+	v := make([]byte, verifyResultLen)
+	binary.BigEndian.PutUint64(v, uint64(r.Size))
+	binary.BigEndian.PutUint64(v[8:], uint64(r.ModTime.UnixNano()))
+	binary.BigEndian.PutUint64(v[16:], uint64(r.VerifiedAt.Unix()))
+	copy(v[24:], r.InfoHash[:])
+	binary.BigEndian.PutUint32(v[24+infohash.Size:], uint32(r.Pieces))
+	binary.BigEndian.PutUint32(v[28+infohash.Size:], uint32(r.BadPieces))
+	return tx.Put(kv.BittorrentVerified, []byte(r.Name), v)
+}
+
+func verifyReply(violations []ManifestViolation, results []VerifyResult) *proto_downloader.VerifyReply {
+	reply := &proto_downloader.VerifyReply{}
+	for _, r := range results {
+		reply.Results = append(reply.Results, &proto_downloader.VerifyResult{
+			Name:       r.Name,
+			InfoHash:   gointerfaces.ConvertAddressToH160(r.InfoHash),
+			Pieces:     uint32(r.Pieces),
+			BadPieces:  uint32(r.BadPieces),
+			Cached:     r.Cached,
+			Size:       uint64(r.Size),
+			ModTime:    uint64(r.ModTime.Unix()),
+			VerifiedAt: uint64(r.VerifiedAt.Unix()),
+		})
+	}
+	for _, v := range violations {
+		reply.ManifestViolations = append(reply.ManifestViolations, &proto_downloader.ManifestViolation{Name: v.Name, Error: v.Err.Error()})
+	}
+	return reply
+}
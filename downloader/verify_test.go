@@ -0,0 +1,136 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	lg "github.com/anacrolix/log"
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloader"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	snapDir := t.TempDir()
+	headers, bodies := snaptype.SegmentFileName(0, 500_000, snaptype.Headers), snaptype.SegmentFileName(0, 500_000, snaptype.Bodies)
+	data := make([]byte, 2*downloadercfg.DefaultPieceSize+12345)
+	rand.New(rand.NewSource(1)).Read(data)
+	for _, name := range []string{headers, bodies} {
+		require.NoError(t, os.WriteFile(filepath.Join(snapDir, name), data, 0o644))
+	}
+	open := func() *Downloader {
+		cfg, err := downloadercfg.New(snapDir, "test", lg.Warning, 1*datasize.GB, 1*datasize.GB, 0, 10, 1, nil)
+		require.NoError(t, err)
+		cfg.DisableTrackers = true
+		d, err := New(ctx, cfg)
+		require.NoError(t, err)
+		d.MainLoopInBackground(ctx, true)
+		return d
+	}
+	d := open()
+
+	results, err := d.verify(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, bodies, results[0].Name)
+	require.Equal(t, headers, results[1].Name)
+	for _, r := range results {
+		require.True(t, r.Ok())
+		require.False(t, r.Cached)
+		require.Equal(t, 3, r.Pieces)
+		require.Equal(t, int64(len(data)), r.Size)
+	}
+
+	// unchanged files are not re-hashed after restart
+	d.Close()
+	d = open()
+	defer d.Close()
+	results, err = d.verify(ctx, nil)
+	require.NoError(t, err)
+	require.True(t, results[0].Cached && results[1].Cached)
+
+	// changed file is re-hashed
+	f, err := os.OpenFile(filepath.Join(snapDir, headers), os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{data[0] + 1}, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, os.Chtimes(filepath.Join(snapDir, headers), time.Now(), time.Now().Add(time.Hour)))
+	results, err = d.verify(ctx, []string{headers})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.False(t, results[0].Cached)
+	require.Equal(t, 1, results[0].BadPieces)
+
+	_, err = d.verify(ctx, []string{"unknown.seg"})
+	require.Error(t, err)
+
+	// report of Verify RPC
+	s, err := NewGrpcServer(d)
+	require.NoError(t, err)
+	reply, err := s.Verify(ctx, &proto_downloader.VerifyRequest{Files: []string{bodies}})
+	require.NoError(t, err)
+	require.Len(t, reply.Results, 1)
+	require.Equal(t, bodies, reply.Results[0].Name)
+	require.Zero(t, reply.Results[0].BadPieces)
+	require.Equal(t, uint64(len(data)), reply.Results[0].Size)
+	fi, err := os.Stat(filepath.Join(snapDir, bodies))
+	require.NoError(t, err)
+	require.Equal(t, uint64(fi.ModTime().Unix()), reply.Results[0].ModTime)
+	require.NotZero(t, reply.Results[0].VerifiedAt)
+	reply, err = s.Verify(ctx, &proto_downloader.VerifyRequest{})
+	require.NoError(t, err)
+	require.Len(t, reply.Results, 2)
+	require.Equal(t, headers, reply.Results[1].Name)
+	require.Equal(t, uint32(1), reply.Results[1].BadPieces)
+	require.True(t, reply.Results[1].Cached)
+	require.Empty(t, reply.ManifestViolations)
+
+	// offline verification uses stored results: bad file isn't re-hashed
+	require.NoError(t, d.db.Update(ctx, func(tx kv.RwTx) error {
+		r, err := readVerifyResult(tx, bodies)
+		require.NoError(t, err)
+		r.BadPieces = 1
+		return writeVerifyResult(tx, r)
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(snapDir, headers), data, 0o644))
+	require.Error(t, VerifyDtaFiles(ctx, snapDir, d.db))
+	require.NoError(t, VerifyDtaFiles(ctx, snapDir, nil))
+	require.NoError(t, d.db.Update(ctx, func(tx kv.RwTx) error { return tx.Delete(kv.BittorrentVerified, []byte(bodies)) }))
+	require.NoError(t, VerifyDtaFiles(ctx, snapDir, d.db))
+	require.NoError(t, VerifyDtaFiles(ctx, snapDir, d.db))
+	require.NoError(t, d.db.View(ctx, func(tx kv.Tx) error {
+		for _, name := range []string{headers, bodies} {
+			r, err := readVerifyResult(tx, name)
+			require.NoError(t, err)
+			require.True(t, r.Ok(), name)
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// Webseeds (BEP 19) - HTTP mirrors of snapshots dir. Torrent client downloads pieces by HTTP range requests
+// and verifies them by piece hashes of .torrent - same way as pieces of peers.
+// Mirror serves file of torrent `history/x.v` by url `<base>history/x.v` (and its .torrent by `<base>history/x.v.torrent`).
+
+// addWebSeeds - idempotent
+func addWebSeeds(t *torrent.Torrent, baseURLs []string) {
+	if len(baseURLs) == 0 {
+		return
+	}
+	t.AddWebSeeds(baseURLs, torrent.WebSeedPathEscaper(webSeedPathEscaper))
+}
+
+// webSeedPathEscaper - info.Name of history files has sub-dir, keep "/" not escaped
+func webSeedPathEscaper(pathComps []string) string {
+	var res []string
+	for _, comp := range pathComps {
+		for _, s := range strings.Split(comp, "/") {
+			res = append(res, url.PathEscape(s))
+		}
+	}
+	return strings.Join(res, "/")
+}
+
+// fetchTorrentFile - downloads .torrent of file `name` from webseeds, it must have given info-hash.
+// Metadata of magnet-links can't be received without peers.
+func fetchTorrentFile(ctx context.Context, baseURLs []string, name string, infoHash metainfo.Hash) (mi *metainfo.MetaInfo, err error) {
+	for _, base := range baseURLs {
+		if mi, err = fetchTorrentFileFrom(ctx, base+webSeedPathEscaper([]string{name})+".torrent"); err != nil {
+			continue
+		}
+		if mi.HashInfoBytes() != infoHash {
+			err = fmt.Errorf("webseed %s: .torrent of %s has info-hash %x, expected %x", base, name, mi.HashInfoBytes(), infoHash)
+			continue
+		}
+		return mi, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no webseeds")
+	}
+	return nil, err
+}
+
+func fetchTorrentFileFrom(ctx context.Context, u string) (*metainfo.MetaInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return metainfo.Load(resp.Body)
+}
@@ -0,0 +1,102 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lg "github.com/anacrolix/log"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadergrpc"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSeed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// mirror serves generated segment and its .torrent
+	mirrorDir, name := t.TempDir(), snaptype.SegmentFileName(0, 500_000, snaptype.Headers)
+	data := make([]byte, 2*downloadercfg.DefaultPieceSize+12345)
+	rand.New(rand.NewSource(1)).Read(data)
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, name), data, 0o644))
+	require.NoError(t, buildTorrentIfNeed(name, mirrorDir))
+	mi, err := metainfo.LoadFromFile(filepath.Join(mirrorDir, name+".torrent"))
+	require.NoError(t, err)
+	var rangeRequests atomic.Int32
+	fileServer := http.FileServer(http.Dir(mirrorDir))
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests.Add(1)
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer mirror.Close()
+
+	for testName, withManifest := range map[string]bool{"manifest": true, "no manifest": false} {
+		withManifest := withManifest
+		t.Run(testName, func(t *testing.T) {
+			rangeRequests.Store(0)
+			snapDir := t.TempDir()
+			cfg, err := downloadercfg.New(snapDir, "test", lg.Warning, 1*datasize.GB, 1*datasize.GB, 0, 10, 1, nil)
+			require.NoError(t, err)
+			cfg.DisableTrackers = true
+			if withManifest {
+				cfg.Manifest = downloadercfg.Manifest{name: {InfoHash: mi.HashInfoBytes(), Size: int64(len(data))}}
+			}
+			cfg.WebSeeds = []string{mirror.URL} // trailing slash is added
+			d, err := New(ctx, cfg)
+			require.NoError(t, err)
+			defer d.Close()
+			require.Equal(t, []string{mirror.URL}, cfg.WebSeeds)
+
+			s, err := NewGrpcServer(d)
+			require.NoError(t, err)
+			_, err = s.Download(ctx, &proto_downloader.DownloadRequest{Items: []*proto_downloader.DownloadItem{
+				{Path: name, TorrentHash: downloadergrpc.String2Proto(mi.HashInfoBytes().HexString())},
+			}})
+			require.NoError(t, err)
+			require.FileExists(t, filepath.Join(snapDir, name+".torrent")) // .torrent is fetched from webseed
+			d.MainLoopInBackground(ctx, true)
+
+			tr, ok := d.Torrent().Torrent(mi.HashInfoBytes())
+			require.True(t, ok)
+			select {
+			case <-tr.Complete.On():
+			case <-ctx.Done():
+				t.Fatal(ctx.Err())
+			}
+			got, err := os.ReadFile(filepath.Join(snapDir, name))
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, got))
+			require.Positive(t, rangeRequests.Load())
+		})
+	}
+}
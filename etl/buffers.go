@@ -254,7 +254,7 @@ func (b *appendSortableBuffer) Write(w io.Writer) error {
 		if _, err := w.Write(entry.key); err != nil {
 			return err
 		}
-		lv := int64(len(entry.key))
+		lv := int64(len(entry.value))
 		if entry.value == nil {
 			lv = -1
 		}
@@ -379,6 +379,19 @@ func getBufferByType(tp int, size datasize.ByteSize) Buffer {
 	}
 }
 
+func getBufferSize(b Buffer) datasize.ByteSize {
+	switch b := b.(type) {
+	case *sortableBuffer:
+		return datasize.ByteSize(b.optimalSize)
+	case *appendSortableBuffer:
+		return datasize.ByteSize(b.optimalSize)
+	case *oldestEntrySortableBuffer:
+		return datasize.ByteSize(b.optimalSize)
+	default:
+		panic(fmt.Sprintf("unknown buffer type: %T ", b))
+	}
+}
+
 func getTypeByBuffer(b Buffer) int {
 	switch b.(type) {
 	case *sortableBuffer:
@@ -19,15 +19,19 @@ package etl
 import (
 	"bytes"
 	"container/heap"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -41,13 +45,24 @@ type simpleLoadFunc func(k, v []byte) error
 // as a Collect Transform Load
 type Collector struct {
 	buf           Buffer
+	spareBuf      Buffer           // buffer to continue collection while buf is flushed in background
+	flushing      chan flushResult // not nil while buffer is flushed in background
 	logPrefix     string
 	tmpdir        string
 	dataProviders []dataProvider
 	logLvl        log.Lvl
 	bufType       int
+	maxSpillFiles int
 	allFlushed    bool
 	autoClean     bool
+	bgFlush       bool
+	compress      bool
+}
+
+type flushResult struct {
+	provider dataProvider
+	buf      Buffer
+	err      error
 }
 
 // NewCollectorFromFiles creates collector from existing files (left over from previous unsuccessful loading)
@@ -73,6 +88,7 @@ func NewCollectorFromFiles(logPrefix, tmpdir string) (*Collector, error) {
 		if err != nil {
 			return nil, fmt.Errorf("collector from files - opening file %s: %w", fileInfo.Name(), err)
 		}
+		dataProvider.compressed = strings.HasPrefix(fileInfo.Name(), compressedSpillFilePrefix)
 		dataProviders[i] = &dataProvider
 	}
 	return &Collector{dataProviders: dataProviders, allFlushed: true, autoClean: false, logPrefix: logPrefix}, nil
@@ -103,19 +119,61 @@ func (c *Collector) Collect(k, v []byte) error {
 
 func (c *Collector) LogLvl(v log.Lvl) { c.logLvl = v }
 
+// SortAndFlushInBackground - full buffer is sorted and written to disk by background goroutine,
+// while next buffer is being collected. Collector will use 2 buffers - so it doubles RAM usage.
+func (c *Collector) SortAndFlushInBackground(v bool) { c.bgFlush = v }
+
+// MaxSpillFiles - if Load has more than n files to merge, files are merged (in parallel) by groups of n
+// into bigger files, until there are not more than n of them. It limits amount of open files and size of merge heap.
+// 0 - no limit.
+func (c *Collector) MaxSpillFiles(n int) { c.maxSpillFiles = n }
+
+// CompressSpillFiles - compress files written to tmpdir. Trades CPU for disk space and IO.
+func (c *Collector) CompressSpillFiles(v bool) { c.compress = v }
+
+// Flush - writes all collected data to tmpdir, even if it fits in RAM. Together with NewCriticalCollector
+// (which doesn't remove files if loading failed) it allows to resume loading after restart by NewCollectorFromFiles.
+func (c *Collector) Flush() error {
+	if err := c.flushBuffer(false); err != nil {
+		return err
+	}
+	if err := c.waitFlush(); err != nil {
+		return err
+	}
+	c.allFlushed = true
+	return nil
+}
+
 func (c *Collector) flushBuffer(canStoreInRam bool) error {
+	if err := c.waitFlush(); err != nil {
+		return err
+	}
 	if c.buf.Len() == 0 {
 		return nil
 	}
+	doFsync := !c.autoClean /* is critical collector */
+	if c.bgFlush && !canStoreInRam {
+		fullBuf := c.buf
+		if c.buf = c.spareBuf; c.buf == nil {
+			c.buf = getBufferByType(c.bufType, getBufferSize(fullBuf))
+		}
+		c.spareBuf = nil
+		c.flushing = make(chan flushResult, 1)
+		go func(flushing chan<- flushResult) {
+			fullBuf.Sort()
+			provider, err := flushToDisk(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.compress, c.logLvl)
+			flushing <- flushResult{provider: provider, buf: fullBuf, err: err}
+		}(c.flushing)
+		return nil
+	}
 	var provider dataProvider
 	c.buf.Sort()
 	if canStoreInRam && len(c.dataProviders) == 0 {
 		provider = KeepInRAM(c.buf)
 		c.allFlushed = true
 	} else {
-		doFsync := !c.autoClean /* is critical collector */
 		var err error
-		provider, err = FlushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.logLvl)
+		provider, err = flushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.compress, c.logLvl)
 		if err != nil {
 			return err
 		}
@@ -126,7 +184,96 @@ func (c *Collector) flushBuffer(canStoreInRam bool) error {
 	return nil
 }
 
+// waitFlush - waits for background flush (if any) and takes its file. Files are added in order of flushes:
+// it matters for buffers which keep the oldest value of key.
+func (c *Collector) waitFlush() error {
+	if c.flushing == nil {
+		return nil
+	}
+	res := <-c.flushing
+	c.flushing = nil
+	c.spareBuf = res.buf
+	if res.err != nil {
+		return res.err
+	}
+	if res.provider != nil {
+		c.dataProviders = append(c.dataProviders, res.provider)
+	}
+	return nil
+}
+
+// mergeSpillFiles - see MaxSpillFiles. Groups are made of neighbour files, and merged file takes place of its group:
+// order of files is preserved.
+func (c *Collector) mergeSpillFiles(args TransformArgs) error {
+	for c.maxSpillFiles > 1 && len(c.dataProviders) > c.maxSpillFiles {
+		log.Log(c.logLvl, fmt.Sprintf("[%s] ETL merging spill files", c.logPrefix), "files", len(c.dataProviders), "limit", c.maxSpillFiles)
+		groups := (len(c.dataProviders) + c.maxSpillFiles - 1) / c.maxSpillFiles
+		merged := make([]dataProvider, groups)
+		g := errgroup.Group{}
+		g.SetLimit(runtime.GOMAXPROCS(0))
+		for i := 0; i < groups; i++ {
+			i := i
+			group := c.dataProviders[i*c.maxSpillFiles:]
+			if len(group) > c.maxSpillFiles {
+				group = group[:c.maxSpillFiles]
+			}
+			if len(group) == 1 {
+				merged[i] = group[0]
+				continue
+			}
+			g.Go(func() error {
+				var numBuf [binary.MaxVarintLen64]byte
+				provider, err := writeToDisk(c.tmpdir, !c.autoClean, c.compress, func(w io.Writer) error {
+					return mergeSortFiles(c.logPrefix, group, func(k, v []byte) error {
+						return writeElementToDisk(w, numBuf[:], k, v)
+					}, args)
+				})
+				if err != nil {
+					return err
+				}
+				merged[i] = provider
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			for i, p := range merged {
+				if p != nil && p != c.dataProviders[i*c.maxSpillFiles] {
+					p.Dispose()
+				}
+			}
+			return err
+		}
+		for i, p := range c.dataProviders {
+			if merged[i/c.maxSpillFiles] != p {
+				p.Dispose()
+			}
+		}
+		c.dataProviders = merged
+	}
+	return nil
+}
+
 func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args TransformArgs) error {
+	if toBucket == "" { // passing empty bucket name is valid case for etl when DB modification is not expected
+		return c.load(noTableSink{}, &currentTableReader{db, toBucket}, toBucket, loadFunc, args)
+	}
+	sink, err := NewTableSink(db, toBucket)
+	if err != nil {
+		return err
+	}
+	sink.logPrefix = c.logPrefix
+	return c.load(sink, sink, toBucket, loadFunc, args)
+}
+
+// LoadInto - loads collected data into any ordered consumer (see Sink and its adapters).
+// LoadFunc and TransformArgs have same semantic as in Load, nil loadFunc means IdentityLoadFunc.
+// If sink implements CurrentTableReader - it's passed to loadFunc, otherwise loadFunc gets nil table.
+func (c *Collector) LoadInto(sink Sink, loadFunc LoadFunc, args TransformArgs) error {
+	table, _ := sink.(CurrentTableReader)
+	return c.load(sink, table, fmt.Sprintf("%T", sink), loadFunc, args)
+}
+
+func (c *Collector) load(sink Sink, table CurrentTableReader, logName string, loadFunc LoadFunc, args TransformArgs) error {
 	if c.autoClean {
 		defer c.Close()
 	}
@@ -136,40 +283,22 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 			return e
 		}
 	}
-
-	bucket := toBucket
-
-	var cursor kv.RwCursor
-	haveSortingGuaranties := isIdentityLoadFunc(loadFunc) // user-defined loadFunc may change ordering
-	var lastKey []byte
-	if bucket != "" { // passing empty bucket name is valid case for etl when DB modification is not expected
-		var err error
-		cursor, err = db.RwCursor(bucket)
-		if err != nil {
-			return err
-		}
-		var errLast error
-		lastKey, _, errLast = cursor.Last()
-		if errLast != nil {
-			return errLast
-		}
+	if err := c.mergeSpillFiles(args); err != nil {
+		return fmt.Errorf("loadIntoTable %s: %w", logName, err)
 	}
 
-	var canUseAppend bool
-	isDupSort := kv.ChaindataTablesCfg[bucket].Flags&kv.DupSort != 0 && !kv.ChaindataTablesCfg[bucket].AutoDupSortKeysConversion
+	if loadFunc == nil {
+		loadFunc = IdentityLoadFunc
+	}
+	if s, ok := sink.(sortedSink); ok {
+		s.setSortingGuaranties(isIdentityLoadFunc(loadFunc)) // user-defined loadFunc may change ordering
+	}
 
 	logEvery := time.NewTicker(30 * time.Second)
 	defer logEvery.Stop()
 
-	i := 0
 	var prevK []byte
 	loadNextFunc := func(_, k, v []byte) error {
-		if i == 0 {
-			isEndOfBucket := lastKey == nil || bytes.Compare(lastKey, k) == -1
-			canUseAppend = haveSortingGuaranties && isEndOfBucket
-		}
-		i++
-
 		// SortableOldestAppearedBuffer must guarantee that only 1 oldest value of key will appear
 		// but because size of buffer is limited - each flushed file does guarantee "oldest appeared"
 		// property, but files may overlap. files are sorted, just skip repeated keys here
@@ -185,7 +314,7 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 		select {
 		default:
 		case <-logEvery.C:
-			logArs := []interface{}{"into", bucket}
+			logArs := []interface{}{"into", logName}
 			if args.LogDetailsLoad != nil {
 				logArs = append(logArs, args.LogDetailsLoad(k, v)...)
 			} else {
@@ -199,50 +328,30 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 			(c.bufType == SortableAppendBuffer && len(v) == 0) || //backward compatibility
 			(c.bufType == SortableOldestAppearedBuffer && len(v) == 0)
 		if isNil {
-			if canUseAppend {
-				return nil // nothing to delete after end of bucket
-			}
-			if err := cursor.Delete(k); err != nil {
-				return err
-			}
-			return nil
-		}
-		if canUseAppend {
-			if isDupSort {
-				if err := cursor.(kv.RwCursorDupSort).AppendDup(k, v); err != nil {
-					return fmt.Errorf("%s: bucket: %s, appendDup: k=%x, %w", c.logPrefix, bucket, k, err)
-				}
-			} else {
-				if err := cursor.Append(k, v); err != nil {
-					return fmt.Errorf("%s: bucket: %s, append: k=%x, v=%x, %w", c.logPrefix, bucket, k, v, err)
-				}
-			}
-
-			return nil
-		}
-		if err := cursor.Put(k, v); err != nil {
-			return fmt.Errorf("%s: put: k=%x, %w", c.logPrefix, k, err)
+			v = nil
 		}
-		return nil
+		return sink.Put(k, v)
 	}
 
-	currentTable := &currentTableReader{db, bucket}
 	simpleLoad := func(k, v []byte) error {
-		return loadFunc(k, v, currentTable, loadNextFunc)
+		return loadFunc(k, v, table, loadNextFunc)
 	}
 	if err := mergeSortFiles(c.logPrefix, c.dataProviders, simpleLoad, args); err != nil {
-		return fmt.Errorf("loadIntoTable %s: %w", toBucket, err)
+		return fmt.Errorf("loadIntoTable %s: %w", logName, err)
 	}
 	//log.Trace(fmt.Sprintf("[%s] ETL Load done", c.logPrefix), "bucket", bucket, "records", i)
 	return nil
 }
 
 func (c *Collector) reset() {
+	_ = c.waitFlush()
 	for _, p := range c.dataProviders {
 		p.Dispose()
 	}
 	c.dataProviders = nil
-	c.buf.Reset()
+	if c.buf != nil { // nil for collector made by NewCollectorFromFiles
+		c.buf.Reset()
+	}
 	c.allFlushed = false
 }
 
@@ -18,6 +18,7 @@ package etl
 
 import (
 	"bufio"
+	"compress/flate"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -35,14 +36,34 @@ type fileDataProvider struct {
 	file       *os.File
 	reader     io.Reader
 	byteReader io.ByteReader // Different interface to the same object as reader
+	compressed bool          // file is compressed by flate, see Collector.CompressSpillFiles
 }
 
+const (
+	spillFilePrefix           = "erigon-sortable-buf-"
+	compressedSpillFilePrefix = "erigon-sortable-buf-flate-" // compression is recognised by prefix in NewCollectorFromFiles
+)
+
 // FlushToDisk - `doFsync` is true only for 'critical' collectors (which should not loose).
 func FlushToDisk(logPrefix string, b Buffer, tmpdir string, doFsync bool, lvl log.Lvl) (dataProvider, error) {
+	return flushToDisk(logPrefix, b, tmpdir, doFsync, false, lvl)
+}
+
+func flushToDisk(logPrefix string, b Buffer, tmpdir string, doFsync, compress bool, lvl log.Lvl) (dataProvider, error) {
 	if b.Len() == 0 {
 		return nil, nil
 	}
+	defer b.Reset() // run it after buf.flush and file.sync
+	provider, err := writeToDisk(tmpdir, doFsync, compress, b.Write)
+	if err != nil {
+		return nil, err
+	}
+	log.Log(lvl, fmt.Sprintf("[%s] Flushed buffer file", logPrefix), "name", provider.file.Name())
+	return provider, nil
+}
 
+// writeToDisk - creates spill file in tmpdir and fills it by given write func
+func writeToDisk(tmpdir string, doFsync, compress bool, write func(w io.Writer) error) (*fileDataProvider, error) {
 	// if we are going to create files in the system temp dir, we don't need any
 	// subfolders.
 	if tmpdir != "" {
@@ -50,28 +71,49 @@ func FlushToDisk(logPrefix string, b Buffer, tmpdir string, doFsync bool, lvl lo
 			return nil, err
 		}
 	}
-
-	bufferFile, err := os.CreateTemp(tmpdir, "erigon-sortable-buf-")
+	prefix := spillFilePrefix
+	if compress {
+		prefix = compressedSpillFilePrefix
+	}
+	bufferFile, err := os.CreateTemp(tmpdir, prefix)
 	if err != nil {
 		return nil, err
 	}
-	if doFsync {
-		defer bufferFile.Sync() //nolint:errcheck
+	provider := &fileDataProvider{file: bufferFile, compressed: compress}
+	if err = writeSpillFile(bufferFile, doFsync, compress, write); err != nil {
+		provider.Dispose()
+		return nil, err
 	}
+	return provider, nil
+}
 
-	w := bufio.NewWriterSize(bufferFile, BufIOSize)
-	defer w.Flush() //nolint:errcheck
-
-	defer func() {
-		b.Reset() // run it after buf.flush and file.sync
-		log.Log(lvl, fmt.Sprintf("[%s] Flushed buffer file", logPrefix), "name", bufferFile.Name())
-	}()
-
-	if err = b.Write(w); err != nil {
-		return nil, fmt.Errorf("error writing entries to disk: %w", err)
+func writeSpillFile(f *os.File, doFsync, compress bool, write func(w io.Writer) error) error {
+	var fw *flate.Writer
+	var w *bufio.Writer
+	if compress {
+		var err error
+		if fw, err = flate.NewWriter(f, flate.BestSpeed); err != nil {
+			return err
+		}
+		w = bufio.NewWriterSize(fw, BufIOSize)
+	} else {
+		w = bufio.NewWriterSize(f, BufIOSize)
 	}
-
-	return &fileDataProvider{file: bufferFile, reader: nil}, nil
+	if err := write(w); err != nil {
+		return fmt.Errorf("error writing entries to disk: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if fw != nil {
+		if err := fw.Close(); err != nil {
+			return err
+		}
+	}
+	if doFsync {
+		return f.Sync()
+	}
+	return nil
 }
 
 func (p *fileDataProvider) Next(keyBuf, valBuf []byte) ([]byte, []byte, error) {
@@ -80,7 +122,12 @@ func (p *fileDataProvider) Next(keyBuf, valBuf []byte) ([]byte, []byte, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		r := bufio.NewReaderSize(p.file, BufIOSize)
+		var r *bufio.Reader
+		if p.compressed {
+			r = bufio.NewReaderSize(flate.NewReader(bufio.NewReaderSize(p.file, BufIOSize)), BufIOSize)
+		} else {
+			r = bufio.NewReaderSize(p.file, BufIOSize)
+		}
 		p.reader = r
 		p.byteReader = r
 
@@ -143,6 +190,24 @@ func readElementFromDisk(r io.Reader, br io.ByteReader, keyBuf, valBuf []byte) (
 	return keyBuf, valBuf, err
 }
 
+// writeElementToDisk - writes element in the format of Buffer.Write, nil key or value is encoded as length -1
+func writeElementToDisk(w io.Writer, numBuf []byte, k, v []byte) error {
+	for _, b := range [2][]byte{k, v} {
+		l := len(b)
+		if b == nil {
+			l = -1
+		}
+		n := binary.PutVarint(numBuf, int64(l))
+		if _, err := w.Write(numBuf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type memoryDataProvider struct {
 	buffer       Buffer
 	currentIndex int
@@ -17,6 +17,7 @@ package etl
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -141,6 +142,29 @@ func TestWriteAndReadBufferEntry(t *testing.T) {
 	assert.Equal(t, io.EOF, err)
 }
 
+func TestWriteAndReadAppendBufferEntry(t *testing.T) {
+	b := NewAppendBuffer(128)
+	buffer := bytes.NewBuffer(make([]byte, 0))
+
+	// values are longer than keys and appended to each other
+	b.Put([]byte("k1"), []byte("value-1"))
+	b.Put([]byte("k2"), []byte("value-2"))
+	b.Put([]byte("k1"), []byte("value-3"))
+	b.Put([]byte("k3"), []byte{})
+	b.Sort()
+	require.NoError(t, b.Write(buffer))
+
+	readBuffer := bytes.NewReader(buffer.Bytes())
+	for _, expect := range [][2]string{{"k1", "value-1value-3"}, {"k2", "value-2"}, {"k3", ""}} {
+		k, v, err := readElementFromDisk(readBuffer, readBuffer, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expect[0], string(k))
+		assert.Equal(t, expect[1], string(v))
+	}
+	_, _, err := readElementFromDisk(readBuffer, readBuffer, nil, nil)
+	assert.Equal(t, io.EOF, err)
+}
+
 func TestNextKey(t *testing.T) {
 	for _, tc := range []string{
 		"00000001->00000002",
@@ -493,3 +517,180 @@ func TestReuseCollectorAfterLoad(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, see)
 }
+
+// collectAndLoad - collects keys with repeats (value is order of appearance), returns loaded pairs
+func collectAndLoad(t *testing.T, c *Collector) [][2]string {
+	t.Helper()
+	for i := 0; i < 2_000; i++ {
+		require.NoError(t, c.Collect([]byte(fmt.Sprintf("key %04d", (i*7)%500)), []byte(fmt.Sprintf("%d", i))))
+	}
+	var res [][2]string
+	require.NoError(t, c.Load(nil, "", func(k, v []byte, table CurrentTableReader, next LoadNextFunc) error {
+		res = append(res, [2]string{string(k), string(v)})
+		return nil
+	}, TransformArgs{}))
+	return res
+}
+
+func TestCollectorSpillOptions(t *testing.T) {
+	for _, bufType := range []int{SortableSliceBuffer, SortableAppendBuffer, SortableOldestAppearedBuffer} {
+		// result of append and oldest buffers depends on buffer size, so compare with result of collector with same buffer size
+		expected := collectAndLoad(t, NewCollector(t.Name(), t.TempDir(), getBufferByType(bufType, 256)))
+		require.NotEmpty(t, expected)
+		for _, bg := range []bool{false, true} {
+			for _, compress := range []bool{false, true} {
+				for _, maxFiles := range []int{0, 2, 3, 1000} {
+					t.Run(fmt.Sprintf("type=%d,bg=%t,compress=%t,maxFiles=%d", bufType, bg, compress, maxFiles), func(t *testing.T) {
+						tmpDir := t.TempDir()
+						c := NewCollector(t.Name(), tmpDir, getBufferByType(bufType, 256))
+						c.SortAndFlushInBackground(bg)
+						c.CompressSpillFiles(compress)
+						c.MaxSpillFiles(maxFiles)
+						require.Equal(t, expected, collectAndLoad(t, c))
+
+						files, err := os.ReadDir(tmpDir)
+						require.NoError(t, err)
+						require.Empty(t, files)
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestCollectorMergeSpillFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := NewCollector(t.Name(), tmpDir, NewOldestEntryBuffer(256))
+	defer c.Close()
+	c.MaxSpillFiles(4)
+	c.CompressSpillFiles(true)
+	for i := 0; i < 2_000; i++ {
+		require.NoError(t, c.Collect([]byte(fmt.Sprintf("key %04d", (i*7)%500)), []byte(fmt.Sprintf("%d", i))))
+	}
+	require.Greater(t, len(c.dataProviders), 16)
+	require.NoError(t, c.mergeSpillFiles(TransformArgs{}))
+	require.LessOrEqual(t, len(c.dataProviders), 4)
+	files, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Equal(t, len(c.dataProviders), len(files))
+	for _, f := range files {
+		require.True(t, strings.HasPrefix(f.Name(), compressedSpillFilePrefix))
+	}
+}
+
+func TestCollectorFromCompressedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := NewCriticalCollector(t.Name(), tmpDir, NewSortableBuffer(256))
+	c.CompressSpillFiles(true)
+	c.SortAndFlushInBackground(true)
+	for i := 0; i < 1_000; i++ {
+		require.NoError(t, c.Collect([]byte(fmt.Sprintf("key %04d", i)), []byte(fmt.Sprintf("%d", i))))
+	}
+	require.NoError(t, c.flushBuffer(false))
+	require.NoError(t, c.waitFlush())
+
+	// files left over from previous unsuccessful loading
+	c, err := NewCollectorFromFiles(t.Name(), tmpDir)
+	require.NoError(t, err)
+	defer c.Close()
+	i := 0
+	require.NoError(t, c.Load(nil, "", func(k, v []byte, table CurrentTableReader, next LoadNextFunc) error {
+		require.Equal(t, fmt.Sprintf("key %04d", i), string(k))
+		require.Equal(t, fmt.Sprintf("%d", i), string(v))
+		i++
+		return nil
+	}, TransformArgs{}))
+	require.Equal(t, 1_000, i)
+}
+
+func TestCollectorFlush(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := NewCriticalCollector(t.Name(), tmpDir, NewSortableBuffer(BufferOptimalSize))
+	for i := 9; i >= 0; i-- {
+		require.NoError(t, c.Collect([]byte(fmt.Sprintf("key %d", i)), []byte(fmt.Sprintf("%d", i))))
+	}
+	require.NoError(t, c.Flush()) // data fits in RAM, but must be on disk
+	files, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+
+	c, err = NewCollectorFromFiles(t.Name(), tmpDir)
+	require.NoError(t, err)
+	defer c.Close()
+	var keys []string
+	require.NoError(t, c.LoadInto(SinkFunc(func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}), nil, TransformArgs{}))
+	require.Equal(t, []string{"key 0", "key 1", "key 2", "key 3", "key 4", "key 5", "key 6", "key 7", "key 8", "key 9"}, keys)
+}
+
+type wordsRecorder struct{ words []string }
+
+func (w *wordsRecorder) AddWord(word []byte) error {
+	w.words = append(w.words, string(word))
+	return nil
+}
+
+type keysRecorder struct{ offsets map[string]uint64 }
+
+func (w *keysRecorder) AddKey(key []byte, offset uint64) error {
+	w.offsets[string(key)] = offset
+	return nil
+}
+
+func TestLoadIntoSinks(t *testing.T) {
+	collect := func(t *testing.T) *Collector {
+		c := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(64))
+		for i := 9; i >= 0; i-- {
+			v := make([]byte, 8)
+			binary.BigEndian.PutUint64(v, uint64(i*100))
+			require.NoError(t, c.Collect([]byte(fmt.Sprintf("key %d", i)), v))
+		}
+		return c
+	}
+
+	t.Run("func", func(t *testing.T) {
+		var keys []string
+		require.NoError(t, collect(t).LoadInto(SinkFunc(func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		}), nil, TransformArgs{}))
+		require.Equal(t, 10, len(keys))
+		require.Equal(t, "key 0", keys[0])
+		require.Equal(t, "key 9", keys[9])
+	})
+	t.Run("table", func(t *testing.T) {
+		_, tx := memdb.NewTestTx(t)
+		sink, err := NewTableSink(tx, kv.ChaindataTables[0])
+		require.NoError(t, err)
+		require.NoError(t, collect(t).LoadInto(sink, func(k, v []byte, table CurrentTableReader, next LoadNextFunc) error {
+			if k[len(k)-1] == '5' { // also check that loadFunc can read the table
+				prev, err := table.Get([]byte("key 4"))
+				require.NoError(t, err)
+				require.Equal(t, uint64(400), binary.BigEndian.Uint64(prev))
+			}
+			return next(k, k, v)
+		}, TransformArgs{}))
+		v, err := tx.GetOne(kv.ChaindataTables[0], []byte("key 7"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(700), binary.BigEndian.Uint64(v))
+	})
+	t.Run("compressor", func(t *testing.T) {
+		w := &wordsRecorder{}
+		require.NoError(t, collect(t).LoadInto(CompressorSink(w, true), IdentityLoadFunc, TransformArgs{}))
+		require.Equal(t, 20, len(w.words))
+		require.Equal(t, "key 1", w.words[2])
+		require.Equal(t, uint64(100), binary.BigEndian.Uint64([]byte(w.words[3])))
+	})
+	t.Run("index", func(t *testing.T) {
+		w := &keysRecorder{offsets: map[string]uint64{}}
+		require.NoError(t, collect(t).LoadInto(IndexSink(w), nil, TransformArgs{}))
+		require.Equal(t, 10, len(w.offsets))
+		require.Equal(t, uint64(300), w.offsets["key 3"])
+
+		c := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(64))
+		require.NoError(t, c.Collect([]byte("key"), []byte{1}))
+		require.Error(t, c.LoadInto(IndexSink(w), nil, TransformArgs{}))
+	})
+}
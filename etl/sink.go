@@ -0,0 +1,149 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package etl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// Sink - ordered consumer of loaded data, see Collector.LoadInto.
+// Put is called in ascending order of keys, unless LoadFunc changes ordering.
+// v == nil means "no value" (deletion of the key for TableSink), see Buffer types for when it happens.
+// Sink must not keep references to k and v after Put returns.
+type Sink interface {
+	Put(k, v []byte) error
+}
+
+// SinkFunc adapts ordinary function to Sink
+type SinkFunc func(k, v []byte) error
+
+func (f SinkFunc) Put(k, v []byte) error { return f(k, v) }
+
+// sortedSink - sink which can use faster writes if it knows that keys will come sorted
+type sortedSink interface {
+	setSortingGuaranties(sorted bool)
+}
+
+// TableSink - puts data into MDBX table, it's what Collector.Load uses.
+// If keys come sorted (identity LoadFunc) and they are after the last key of the table - uses Append.
+// Also implements CurrentTableReader for LoadFunc.
+type TableSink struct {
+	tx           kv.RwTx
+	cursor       kv.RwCursor
+	table        string
+	logPrefix    string
+	lastKey      []byte
+	isDupSort    bool
+	sorted       bool
+	canUseAppend bool
+	started      bool
+}
+
+func NewTableSink(tx kv.RwTx, table string) (*TableSink, error) {
+	cursor, err := tx.RwCursor(table)
+	if err != nil {
+		return nil, err
+	}
+	lastKey, _, err := cursor.Last()
+	if err != nil {
+		return nil, err
+	}
+	return &TableSink{
+		tx:        tx,
+		cursor:    cursor,
+		table:     table,
+		lastKey:   lastKey,
+		isDupSort: kv.ChaindataTablesCfg[table].Flags&kv.DupSort != 0 && !kv.ChaindataTablesCfg[table].AutoDupSortKeysConversion,
+	}, nil
+}
+
+func (s *TableSink) setSortingGuaranties(sorted bool) { s.sorted = sorted }
+
+func (s *TableSink) Get(key []byte) ([]byte, error) { return s.tx.GetOne(s.table, key) }
+
+func (s *TableSink) Put(k, v []byte) error {
+	if !s.started {
+		s.started = true
+		isEndOfBucket := s.lastKey == nil || bytes.Compare(s.lastKey, k) == -1
+		s.canUseAppend = s.sorted && isEndOfBucket
+	}
+	if v == nil {
+		if s.canUseAppend {
+			return nil // nothing to delete after end of bucket
+		}
+		return s.cursor.Delete(k)
+	}
+	if s.canUseAppend {
+		if s.isDupSort {
+			if err := s.cursor.(kv.RwCursorDupSort).AppendDup(k, v); err != nil {
+				return fmt.Errorf("%s: bucket: %s, appendDup: k=%x, %w", s.logPrefix, s.table, k, err)
+			}
+		} else {
+			if err := s.cursor.Append(k, v); err != nil {
+				return fmt.Errorf("%s: bucket: %s, append: k=%x, v=%x, %w", s.logPrefix, s.table, k, v, err)
+			}
+		}
+		return nil
+	}
+	if err := s.cursor.Put(k, v); err != nil {
+		return fmt.Errorf("%s: put: k=%x, %w", s.logPrefix, k, err)
+	}
+	return nil
+}
+
+// noTableSink - for Collector.Load with empty table name: it's valid when loadFunc doesn't call next
+type noTableSink struct{}
+
+func (noTableSink) Put(k, _ []byte) error {
+	return fmt.Errorf("etl: can't load key %x, table name is not provided", k)
+}
+
+// WordAdder - consumer of words, for example compress.Compressor
+type WordAdder interface {
+	AddWord(word []byte) error
+}
+
+// CompressorSink - adds values (with keys before them - if withKeys) as words
+func CompressorSink(c WordAdder, withKeys bool) Sink {
+	return SinkFunc(func(k, v []byte) error {
+		if withKeys {
+			if err := c.AddWord(k); err != nil {
+				return err
+			}
+		}
+		return c.AddWord(v)
+	})
+}
+
+// KeyAdder - consumer of keys with offsets, for example recsplit.RecSplit or state.BtIndexWriter
+type KeyAdder interface {
+	AddKey(key []byte, offset uint64) error
+}
+
+// IndexSink - adds keys to index, value must be BigEndian uint64 offset of the key
+func IndexSink(w KeyAdder) Sink {
+	return SinkFunc(func(k, v []byte) error {
+		if len(v) != 8 {
+			return fmt.Errorf("etl: index sink expects 8 bytes offset, got %x for key %x", v, k)
+		}
+		return w.AddKey(k, binary.BigEndian.Uint64(v))
+	})
+}
@@ -30,7 +30,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DownloaderClient interface {
 	Download(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyReply, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error)
 }
 
@@ -51,8 +51,8 @@ func (c *downloaderClient) Download(ctx context.Context, in *DownloadRequest, op
 	return out, nil
 }
 
-func (c *downloaderClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *downloaderClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyReply, error) {
+	out := new(VerifyReply)
 	err := c.cc.Invoke(ctx, Downloader_Verify_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -74,7 +74,7 @@ func (c *downloaderClient) Stats(ctx context.Context, in *StatsRequest, opts ...
 // for forward compatibility
 type DownloaderServer interface {
 	Download(context.Context, *DownloadRequest) (*emptypb.Empty, error)
-	Verify(context.Context, *VerifyRequest) (*emptypb.Empty, error)
+	Verify(context.Context, *VerifyRequest) (*VerifyReply, error)
 	Stats(context.Context, *StatsRequest) (*StatsReply, error)
 	mustEmbedUnimplementedDownloaderServer()
 }
@@ -86,7 +86,7 @@ type UnimplementedDownloaderServer struct {
 func (UnimplementedDownloaderServer) Download(context.Context, *DownloadRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Download not implemented")
 }
-func (UnimplementedDownloaderServer) Verify(context.Context, *VerifyRequest) (*emptypb.Empty, error) {
+func (UnimplementedDownloaderServer) Verify(context.Context, *VerifyRequest) (*VerifyReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
 }
 func (UnimplementedDownloaderServer) Stats(context.Context, *StatsRequest) (*StatsReply, error) {
@@ -31,6 +31,7 @@ const (
 	KV_IndexRange_FullMethodName   = "/remote.KV/IndexRange"
 	KV_HistoryRange_FullMethodName = "/remote.KV/HistoryRange"
 	KV_DomainRange_FullMethodName  = "/remote.KV/DomainRange"
+	KV_TableDiffs_FullMethodName   = "/remote.KV/TableDiffs"
 )
 
 // KVClient is the client API for KV service.
@@ -59,6 +60,8 @@ type KVClient interface {
 	IndexRange(ctx context.Context, in *IndexRangeReq, opts ...grpc.CallOption) (*IndexRangeReply, error)
 	HistoryRange(ctx context.Context, in *HistoryRangeReq, opts ...grpc.CallOption) (*Pairs, error)
 	DomainRange(ctx context.Context, in *DomainRangeReq, opts ...grpc.CallOption) (*Pairs, error)
+	// TableDiffs - changes of tables made by write transactions committed after subscription, for read-only replicas of db
+	TableDiffs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (KV_TableDiffsClient, error)
 }
 
 type kVClient struct {
@@ -204,6 +207,38 @@ func (c *kVClient) DomainRange(ctx context.Context, in *DomainRangeReq, opts ...
 	return out, nil
 }
 
+func (c *kVClient) TableDiffs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (KV_TableDiffsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &KV_ServiceDesc.Streams[2], KV_TableDiffs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kVTableDiffsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KV_TableDiffsClient interface {
+	Recv() (*TableDiffBatch, error)
+	grpc.ClientStream
+}
+
+type kVTableDiffsClient struct {
+	grpc.ClientStream
+}
+
+func (x *kVTableDiffsClient) Recv() (*TableDiffBatch, error) {
+	m := new(TableDiffBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // KVServer is the server API for KV service.
 // All implementations must embed UnimplementedKVServer
 // for forward compatibility
@@ -230,6 +265,8 @@ type KVServer interface {
 	IndexRange(context.Context, *IndexRangeReq) (*IndexRangeReply, error)
 	HistoryRange(context.Context, *HistoryRangeReq) (*Pairs, error)
 	DomainRange(context.Context, *DomainRangeReq) (*Pairs, error)
+	// TableDiffs - changes of tables made by write transactions committed after subscription, for read-only replicas of db
+	TableDiffs(*emptypb.Empty, KV_TableDiffsServer) error
 	mustEmbedUnimplementedKVServer()
 }
 
@@ -267,6 +304,9 @@ func (UnimplementedKVServer) HistoryRange(context.Context, *HistoryRangeReq) (*P
 func (UnimplementedKVServer) DomainRange(context.Context, *DomainRangeReq) (*Pairs, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DomainRange not implemented")
 }
+func (UnimplementedKVServer) TableDiffs(*emptypb.Empty, KV_TableDiffsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TableDiffs not implemented")
+}
 func (UnimplementedKVServer) mustEmbedUnimplementedKVServer() {}
 
 // UnsafeKVServer may be embedded to opt out of forward compatibility for this service.
@@ -471,6 +511,27 @@ func _KV_DomainRange_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KV_TableDiffs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KVServer).TableDiffs(m, &kVTableDiffsServer{stream})
+}
+
+type KV_TableDiffsServer interface {
+	Send(*TableDiffBatch) error
+	grpc.ServerStream
+}
+
+type kVTableDiffsServer struct {
+	grpc.ServerStream
+}
+
+func (x *kVTableDiffsServer) Send(m *TableDiffBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // KV_ServiceDesc is the grpc.ServiceDesc for KV service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -523,6 +584,11 @@ var KV_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _KV_StateChanges_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "TableDiffs",
+			Handler:       _KV_TableDiffs_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "remote/kv.proto",
 }
@@ -46,6 +46,9 @@ var _ KVClient = &KVClientMock{}
 //			StateChangesFunc: func(ctx context.Context, in *StateChangeRequest, opts ...grpc.CallOption) (KV_StateChangesClient, error) {
 //				panic("mock out the StateChanges method")
 //			},
+//			TableDiffsFunc: func(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (KV_TableDiffsClient, error) {
+//				panic("mock out the TableDiffs method")
+//			},
 //			TxFunc: func(ctx context.Context, opts ...grpc.CallOption) (KV_TxClient, error) {
 //				panic("mock out the Tx method")
 //			},
@@ -83,6 +86,9 @@ type KVClientMock struct {
 	// StateChangesFunc mocks the StateChanges method.
 	StateChangesFunc func(ctx context.Context, in *StateChangeRequest, opts ...grpc.CallOption) (KV_StateChangesClient, error)
 
+	// TableDiffsFunc mocks the TableDiffs method.
+	TableDiffsFunc func(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (KV_TableDiffsClient, error)
+
 	// TxFunc mocks the Tx method.
 	TxFunc func(ctx context.Context, opts ...grpc.CallOption) (KV_TxClient, error)
 
@@ -163,6 +169,15 @@ type KVClientMock struct {
 			// Opts is the opts argument value.
 			Opts []grpc.CallOption
 		}
+		// TableDiffs holds details about calls to the TableDiffs method.
+		TableDiffs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// In is the in argument value.
+			In *emptypb.Empty
+			// Opts is the opts argument value.
+			Opts []grpc.CallOption
+		}
 		// Tx holds details about calls to the Tx method.
 		Tx []struct {
 			// Ctx is the ctx argument value.
@@ -188,6 +203,7 @@ type KVClientMock struct {
 	lockRange        sync.RWMutex
 	lockSnapshots    sync.RWMutex
 	lockStateChanges sync.RWMutex
+	lockTableDiffs   sync.RWMutex
 	lockTx           sync.RWMutex
 	lockVersion      sync.RWMutex
 }
@@ -544,6 +560,50 @@ func (mock *KVClientMock) StateChangesCalls() []struct {
 	return calls
 }
 
+// TableDiffs calls TableDiffsFunc.
+func (mock *KVClientMock) TableDiffs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (KV_TableDiffsClient, error) {
+	callInfo := struct {
+		Ctx  context.Context
+		In   *emptypb.Empty
+		Opts []grpc.CallOption
+	}{
+		Ctx:  ctx,
+		In:   in,
+		Opts: opts,
+	}
+	mock.lockTableDiffs.Lock()
+	mock.calls.TableDiffs = append(mock.calls.TableDiffs, callInfo)
+	mock.lockTableDiffs.Unlock()
+	if mock.TableDiffsFunc == nil {
+		var (
+			kV_TableDiffsClientOut KV_TableDiffsClient
+			errOut                 error
+		)
+		return kV_TableDiffsClientOut, errOut
+	}
+	return mock.TableDiffsFunc(ctx, in, opts...)
+}
+
+// TableDiffsCalls gets all the calls that were made to TableDiffs.
+// Check the length with:
+//
+//	len(mockedKVClient.TableDiffsCalls())
+func (mock *KVClientMock) TableDiffsCalls() []struct {
+	Ctx  context.Context
+	In   *emptypb.Empty
+	Opts []grpc.CallOption
+} {
+	var calls []struct {
+		Ctx  context.Context
+		In   *emptypb.Empty
+		Opts []grpc.CallOption
+	}
+	mock.lockTableDiffs.RLock()
+	calls = mock.calls.TableDiffs
+	mock.lockTableDiffs.RUnlock()
+	return calls
+}
+
 // Tx calls TxFunc.
 func (mock *KVClientMock) Tx(ctx context.Context, opts ...grpc.CallOption) (KV_TxClient, error) {
 	callInfo := struct {
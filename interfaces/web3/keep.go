@@ -0,0 +1 @@
+package web3
@@ -371,6 +371,29 @@ func (m *TransformDualIter[K, v]) Close() {
 	}
 }
 
+// TransformKV2DualIter - decodes stream of raw key-values to stream of typed pairs. See kv.Table
+type TransformKV2DualIter[K, V any] struct {
+	it        KV
+	transform func(k, v []byte) (K, V, error)
+}
+
+func TransformKV2Dual[K, V any](it KV, transform func(k, v []byte) (K, V, error)) *TransformKV2DualIter[K, V] {
+	return &TransformKV2DualIter[K, V]{it: it, transform: transform}
+}
+func (m *TransformKV2DualIter[K, V]) HasNext() bool { return m.it.HasNext() }
+func (m *TransformKV2DualIter[K, V]) Next() (k K, v V, err error) {
+	rawK, rawV, err := m.it.Next()
+	if err != nil {
+		return k, v, err
+	}
+	return m.transform(rawK, rawV)
+}
+func (m *TransformKV2DualIter[K, V]) Close() {
+	if x, ok := m.it.(Closer); ok {
+		x.Close()
+	}
+}
+
 type TransformKV2U64Iter[K, V []byte] struct {
 	it        KV
 	transform func(K, V) (uint64, error)
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/iter"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
@@ -414,3 +415,58 @@ func TestFiler(t *testing.T) {
 		require.Nil(t, res)
 	})
 }
+
+func TestTypedTable(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	ctx := context.Background()
+	tx, _ := db.BeginRw(ctx)
+	defer tx.Rollback()
+
+	for i := uint64(1); i <= 5; i++ {
+		require.NoError(t, kv.MaxTxNumTbl.Put(tx, i, i*10))
+		require.NoError(t, kv.HeadersTbl.Put(tx, kv.BlockNumHash{Num: i, Hash: common.Hash{byte(i)}}, []byte{byte(i)}))
+	}
+	require.NoError(t, kv.HeadersTbl.Put(tx, kv.BlockNumHash{Num: 3, Hash: common.Hash{0xff}}, []byte{0xff}))
+
+	v, ok, err := kv.MaxTxNumTbl.Get(tx, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(30), v)
+	_, ok, err = kv.MaxTxNumTbl.Get(tx, 6)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	it, err := kv.MaxTxNumTbl.Range(tx, 2, 4)
+	require.NoError(t, err)
+	keys, values, err := iter.ToDualArray[uint64, uint64](it)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, keys)
+	require.Equal(t, []uint64{20, 30}, values)
+
+	it, err = kv.MaxTxNumTbl.RangeFrom(tx, 4)
+	require.NoError(t, err)
+	keys, _, err = iter.ToDualArray[uint64, uint64](it)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 5}, keys)
+
+	headers, err := kv.HeadersTbl.Prefix(tx, kv.U64Codec.Encode(nil, 3))
+	require.NoError(t, err)
+	hashes, bodies, err := iter.ToDualArray[kv.BlockNumHash, []byte](headers)
+	require.NoError(t, err)
+	require.Equal(t, []kv.BlockNumHash{{Num: 3, Hash: common.Hash{3}}, {Num: 3, Hash: common.Hash{0xff}}}, hashes)
+	require.Equal(t, [][]byte{{3}, {0xff}}, bodies)
+
+	require.NoError(t, kv.MaxTxNumTbl.Delete(tx, 5))
+	has, err := kv.MaxTxNumTbl.Has(tx, 5)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// mis-encoded value written by raw api is reported, not silently decoded
+	require.NoError(t, tx.Put(kv.MaxTxNum, kv.U64Codec.Encode(nil, 7), []byte{1, 2, 3}))
+	_, _, err = kv.MaxTxNumTbl.Get(tx, 7)
+	require.ErrorContains(t, err, "expected 8 bytes, got 3")
+	it, err = kv.MaxTxNumTbl.RangeFrom(tx, 7)
+	require.NoError(t, err)
+	_, _, err = iter.ToDualArray[uint64, uint64](it)
+	require.ErrorContains(t, err, "table MaxTxNum")
+}
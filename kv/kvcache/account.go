@@ -0,0 +1,67 @@
+/*
+Copyright 2023 Erigon contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvcache
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon-lib/commitment"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+// Account - value of kv.PlainState for account key
+type Account struct {
+	Nonce       uint64
+	Balance     uint256.Int
+	Incarnation uint64 // storage of account is keyed by incarnation: changes when contract is re-created
+	CodeHash    common.Hash
+}
+
+// StorageKey - key of storage slot in kv.PlainState: addr, incarnation, slot
+func StorageKey(addr common.Address, incarnation uint64, slot common.Hash) []byte {
+	k := make([]byte, length.Addr+length.Incarnation+length.Hash)
+	copy(k, addr[:])
+	binary.BigEndian.PutUint64(k[length.Addr:], incarnation)
+	copy(k[length.Addr+length.Incarnation:], slot[:])
+	return k
+}
+
+// getAccount - nil if account doesn't exist
+func getAccount(view CacheView, addr common.Address) (*Account, error) {
+	enc, err := view.Get(addr[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	acc := &Account{}
+	var codeHash []byte
+	acc.Nonce, acc.Balance, acc.Incarnation, codeHash, err = types.DecodeAccount(enc)
+	if err != nil {
+		return nil, fmt.Errorf("account %x: %w", addr, err)
+	}
+	if codeHash == nil {
+		codeHash = commitment.EmptyCodeHash
+	}
+	copy(acc.CodeHash[:], codeHash)
+	return acc, nil
+}
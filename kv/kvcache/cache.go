@@ -32,6 +32,7 @@ import (
 	"golang.org/x/crypto/sha3"
 
 	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -57,6 +58,10 @@ type Cache interface {
 type CacheView interface {
 	Get(k []byte) ([]byte, error)
 	GetCode(k []byte) ([]byte, error)
+	// GetAccount - returns nil if account doesn't exist
+	GetAccount(addr common.Address) (*Account, error)
+	// GetStorage - returns nil if slot is empty
+	GetStorage(addr common.Address, incarnation uint64, slot common.Hash) ([]byte, error)
 }
 
 // Coherent works on top of Database Transaction and pair Coherent+ReadTransaction must
@@ -110,9 +115,15 @@ type Coherent struct {
 	hits                 *metrics.Counter
 	codeHits             *metrics.Counter
 	roots                map[uint64]*CoherentRoot
-	stateEvict           *ThreadSafeEvictionList
-	codeEvict            *ThreadSafeEvictionList
+	stateEvict           EvictionPolicy
+	codeEvict            EvictionPolicy
 	miss                 *metrics.Counter
+	evicted              *metrics.Counter
+	codeEvicted          *metrics.Counter
+	policyHits           *metrics.Counter // hits and misses under label of eviction policy, to compare policies
+	policyMiss           *metrics.Counter
+	codePolicyHits       *metrics.Counter
+	codePolicyMiss       *metrics.Counter
 	cfg                  CoherentConfig
 	latestStateVersionID uint64
 	lock                 sync.Mutex
@@ -144,6 +155,10 @@ func (c *CoherentView) Get(k []byte) ([]byte, error) { return c.cache.Get(k, c.t
 func (c *CoherentView) GetCode(k []byte) ([]byte, error) {
 	return c.cache.GetCode(k, c.tx, c.stateVersionID)
 }
+func (c *CoherentView) GetAccount(addr common.Address) (*Account, error) { return getAccount(c, addr) }
+func (c *CoherentView) GetStorage(addr common.Address, incarnation uint64, slot common.Hash) ([]byte, error) {
+	return c.cache.Get(StorageKey(addr, incarnation, slot), c.tx, c.stateVersionID)
+}
 
 var _ Cache = (*Coherent)(nil)         // compile-time interface check
 var _ CacheView = (*CoherentView)(nil) // compile-time interface check
@@ -159,8 +174,9 @@ type CoherentConfig struct {
 	WaitForNewBlock bool // should we wait 10ms for a new block message to arrive when calling View?
 	WithStorage     bool
 	MetricsLabel    string
-	NewBlockWait    time.Duration // how long wait
-	KeepViews       uint64        // keep in memory up to this amount of views, evict older
+	NewBlockWait    time.Duration      // how long wait
+	KeepViews       uint64             // keep in memory up to this amount of views, evict older
+	EvictionPolicy  EvictionPolicyName // for state and code caches, LRUPolicy if empty
 }
 
 var DefaultCoherentConfig = CoherentConfig{
@@ -169,6 +185,7 @@ var DefaultCoherentConfig = CoherentConfig{
 	CacheSize:       2 * datasize.GB,
 	CodeCacheSize:   2 * datasize.GB,
 	MetricsLabel:    "default",
+	EvictionPolicy:  LRUPolicy,
 	WithStorage:     true,
 	WaitForNewBlock: true,
 }
@@ -177,22 +194,39 @@ func New(cfg CoherentConfig) *Coherent {
 	if cfg.KeepViews == 0 {
 		panic("empty config passed")
 	}
+	if cfg.EvictionPolicy == "" {
+		cfg.EvictionPolicy = LRUPolicy
+	}
+	stateEvict, err := NewEvictionPolicy(cfg.EvictionPolicy, int(cfg.CacheSize.Bytes()))
+	if err != nil {
+		panic(err)
+	}
+	codeEvict, err := NewEvictionPolicy(cfg.EvictionPolicy, int(cfg.CodeCacheSize.Bytes()))
+	if err != nil {
+		panic(err)
+	}
 
 	return &Coherent{
-		roots:        map[uint64]*CoherentRoot{},
-		stateEvict:   &ThreadSafeEvictionList{l: NewList()},
-		codeEvict:    &ThreadSafeEvictionList{l: NewList()},
-		hasher:       sha3.NewLegacyKeccak256(),
-		cfg:          cfg,
-		miss:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
-		hits:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
-		timeout:      metrics.GetOrCreateCounter(fmt.Sprintf(`cache_timeout_total{name="%s"}`, cfg.MetricsLabel)),
-		keys:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_keys_total{name="%s"}`, cfg.MetricsLabel)),
-		evict:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_list_total{name="%s"}`, cfg.MetricsLabel)),
-		codeMiss:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
-		codeHits:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
-		codeKeys:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_keys_total{name="%s"}`, cfg.MetricsLabel)),
-		codeEvictLen: metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_list_total{name="%s"}`, cfg.MetricsLabel)),
+		roots:          map[uint64]*CoherentRoot{},
+		stateEvict:     stateEvict,
+		codeEvict:      codeEvict,
+		hasher:         sha3.NewLegacyKeccak256(),
+		cfg:            cfg,
+		miss:           metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
+		hits:           metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
+		evicted:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_evict_total{name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		timeout:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_timeout_total{name="%s"}`, cfg.MetricsLabel)),
+		keys:           metrics.GetOrCreateCounter(fmt.Sprintf(`cache_keys_total{name="%s"}`, cfg.MetricsLabel)),
+		evict:          metrics.GetOrCreateCounter(fmt.Sprintf(`cache_list_total{name="%s"}`, cfg.MetricsLabel)),
+		codeMiss:       metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
+		codeHits:       metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
+		codeEvicted:    metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_evict_total{name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		policyHits:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_policy_total{result="hit",name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		policyMiss:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_policy_total{result="miss",name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		codePolicyHits: metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_policy_total{result="hit",name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		codePolicyMiss: metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_policy_total{result="miss",name="%s",policy="%s"}`, cfg.MetricsLabel, cfg.EvictionPolicy)),
+		codeKeys:       metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_keys_total{name="%s"}`, cfg.MetricsLabel)),
+		codeEvictLen:   metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_list_total{name="%s"}`, cfg.MetricsLabel)),
 	}
 }
 
@@ -240,18 +274,8 @@ func (c *Coherent) advanceRoot(stateVersionID uint64) (r *CoherentRoot) {
 			r.cache = btree2.NewBTreeG[*Element](Less)
 			r.codeCache = btree2.NewBTreeG[*Element](Less)
 		} else {
-			r.cache.Walk(func(items []*Element) bool {
-				for _, i := range items {
-					c.stateEvict.PushFront(i)
-				}
-				return true
-			})
-			r.codeCache.Walk(func(items []*Element) bool {
-				for _, i := range items {
-					c.codeEvict.PushFront(i)
-				}
-				return true
-			})
+			c.evicted.Add(trackAll(r.cache, c.stateEvict))
+			c.codeEvicted.Add(trackAll(r.codeCache, c.codeEvict))
 		}
 	}
 	r.isCanonical = true
@@ -281,26 +305,25 @@ func (c *Coherent) OnNewBlock(stateChanges *remote.StateChangeBatch) {
 				v := sc.Changes[i].Data
 				//fmt.Printf("set: %x,%x\n", addr, v)
 				c.add(addr[:], v, r, id)
+				c.removeStorage(addr[:], sc.Changes[i].Incarnation, true, r, id)
 			case remote.Action_UPSERT_CODE:
 				addr := gointerfaces.ConvertH160toAddress(sc.Changes[i].Address)
 				v := sc.Changes[i].Data
 				c.add(addr[:], v, r, id)
+				c.removeStorage(addr[:], sc.Changes[i].Incarnation, true, r, id)
 				c.hasher.Reset()
 				c.hasher.Write(sc.Changes[i].Code)
-				k := make([]byte, 32)
-				c.hasher.Sum(k)
-				c.addCode(k, sc.Changes[i].Code, r, id)
+				c.addCode(c.hasher.Sum(nil), sc.Changes[i].Code, r, id)
 			case remote.Action_REMOVE:
 				addr := gointerfaces.ConvertH160toAddress(sc.Changes[i].Address)
 				c.add(addr[:], nil, r, id)
+				c.removeStorage(addr[:], 0, false, r, id)
 			case remote.Action_STORAGE:
 				//skip, will check later
 			case remote.Action_CODE:
 				c.hasher.Reset()
 				c.hasher.Write(sc.Changes[i].Code)
-				k := make([]byte, 32)
-				c.hasher.Sum(k)
-				c.addCode(k, sc.Changes[i].Code, r, id)
+				c.addCode(c.hasher.Sum(nil), sc.Changes[i].Code, r, id)
 			default:
 				panic("not implemented yet")
 			}
@@ -380,7 +403,11 @@ func (c *Coherent) getFromCache(k []byte, id uint64, code bool) (*Element, *Cohe
 		it, _ = r.cache.Get(&Element{K: k})
 	}
 	if it != nil && isLatest {
-		c.stateEvict.MoveToFront(it)
+		if code {
+			c.codeEvict.Touch(it)
+		} else {
+			c.stateEvict.Touch(it)
+		}
 	}
 
 	return it, r, nil
@@ -394,9 +421,11 @@ func (c *Coherent) Get(k []byte, tx kv.Tx, id uint64) ([]byte, error) {
 	if it != nil {
 		//fmt.Printf("from cache:  %#x,%x\n", k, it.(*Element).V)
 		c.hits.Inc()
+		c.policyHits.Inc()
 		return it.V, nil
 	}
 	c.miss.Inc()
+	c.policyMiss.Inc()
 
 	v, err := tx.GetOne(kv.PlainState, k)
 	if err != nil {
@@ -419,9 +448,11 @@ func (c *Coherent) GetCode(k []byte, tx kv.Tx, id uint64) ([]byte, error) {
 	if it != nil {
 		//fmt.Printf("from cache:  %#x,%x\n", k, it.(*Element).V)
 		c.codeHits.Inc()
+		c.codePolicyHits.Inc()
 		return it.V, nil
 	}
 	c.codeMiss.Inc()
+	c.codePolicyMiss.Inc()
 
 	v, err := tx.GetOne(kv.Code, k)
 	if err != nil {
@@ -434,19 +465,45 @@ func (c *Coherent) GetCode(k []byte, tx kv.Tx, id uint64) ([]byte, error) {
 	v = c.addCode(common.Copy(k), common.Copy(v), r, id).V
 	return v, nil
 }
-func (c *Coherent) removeOldest(r *CoherentRoot) {
-	e := c.stateEvict.Oldest()
-	if e != nil {
-		c.stateEvict.Remove(e)
-		r.cache.Delete(e)
+
+// removeStorage - deletes cached storage of account. Storage of account is keyed by incarnation: when account is
+// deleted or re-created with new incarnation, all its slots of other incarnations are gone from db - but they are not listed in StateChanges.
+// If keepCurrent=true - slots of given incarnation are kept.
+func (c *Coherent) removeStorage(addr []byte, incarnation uint64, keepCurrent bool, r *CoherentRoot, id uint64) {
+	var toDel []*Element
+	r.cache.Ascend(&Element{K: addr}, func(it *Element) bool {
+		if !bytes.HasPrefix(it.K, addr) {
+			return false
+		}
+		if len(it.K) == length.Addr+length.Incarnation+length.Hash {
+			if !keepCurrent || binary.BigEndian.Uint64(it.K[length.Addr:]) != incarnation {
+				toDel = append(toDel, it)
+			}
+		}
+		return true
+	})
+	for _, it := range toDel {
+		r.cache.Delete(it)
+		if c.latestStateVersionID == id {
+			c.stateEvict.Remove(it)
+		}
 	}
 }
-func (c *Coherent) removeOldestCode(r *CoherentRoot) {
-	e := c.codeEvict.Oldest()
-	if e != nil {
-		c.codeEvict.Remove(e)
-		r.codeCache.Delete(e)
+
+// trackAll - passes all elements of cache to policy, deletes from cache elements which policy evicted
+func trackAll(cache *btree2.BTreeG[*Element], policy EvictionPolicy) (evicted int) {
+	items := make([]*Element, 0, cache.Len())
+	cache.Walk(func(batch []*Element) bool {
+		items = append(items, batch...)
+		return true
+	})
+	for _, it := range items {
+		for _, e := range policy.Add(it, nil) {
+			cache.Delete(e)
+			evicted++
+		}
 	}
+	return evicted
 }
 func (c *Coherent) add(k, v []byte, r *CoherentRoot, id uint64) *Element {
 	it := &Element{K: k, V: v}
@@ -455,15 +512,12 @@ func (c *Coherent) add(k, v []byte, r *CoherentRoot, id uint64) *Element {
 		//fmt.Printf("add to non-last viewID: %d<%d\n", c.latestViewID, id)
 		return it
 	}
-	if replaced != nil {
-		c.stateEvict.Remove(replaced)
-	}
-	c.stateEvict.PushFront(it)
-
 	// clear down cache until size below the configured limit
-	for c.stateEvict.Size() > int(c.cfg.CacheSize.Bytes()) {
-		c.removeOldest(r)
+	evicted := c.stateEvict.Add(it, replaced)
+	for _, e := range evicted {
+		r.cache.Delete(e)
 	}
+	c.evicted.Add(len(evicted))
 
 	return it
 }
@@ -474,14 +528,11 @@ func (c *Coherent) addCode(k, v []byte, r *CoherentRoot, id uint64) *Element {
 		//fmt.Printf("add to non-last viewID: %d<%d\n", c.latestViewID, id)
 		return it
 	}
-	if replaced != nil {
-		c.codeEvict.Remove(replaced)
-	}
-	c.codeEvict.PushFront(it)
-
-	for c.codeEvict.Size() > int(c.cfg.CodeCacheSize.Bytes()) {
-		c.removeOldestCode(r)
+	evicted := c.codeEvict.Add(it, replaced)
+	for _, e := range evicted {
+		r.codeCache.Delete(e)
 	}
+	c.codeEvicted.Add(len(evicted))
 
 	return it
 }
@@ -605,6 +656,10 @@ func (c *Coherent) clearCaches(r *CoherentRoot) {
 	defer c.lock.Unlock()
 	r.cache.Clear()
 	r.codeCache.Clear()
+	if r == c.latestStateView {
+		c.stateEvict.Init()
+		c.codeEvict.Init()
+	}
 }
 
 type Stat struct {
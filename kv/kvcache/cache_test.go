@@ -19,6 +19,9 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -28,6 +31,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
 )
 
 func TestEvictionInUnexpectedOrder(t *testing.T) {
@@ -372,3 +376,331 @@ func TestCode(t *testing.T) {
 		return nil
 	})
 }
+
+func TestSnapshot(t *testing.T) {
+	require, ctx := require.New(t), context.Background()
+	cfg := DefaultCoherentConfig
+	cfg.NewBlockWait = 0
+	db := memdb.NewTestDB(t)
+	k1, k2, k3 := [20]byte{1}, [20]byte{2}, [20]byte{3}
+	fileName := filepath.Join(t.TempDir(), "kvcache.snapshot")
+
+	var id uint64
+	setVersion := func(tx kv.RwTx) {
+		id = tx.ViewID()
+		var versionID [8]byte
+		binary.BigEndian.PutUint64(versionID[:], id)
+		require.NoError(tx.Put(kv.Sequence, kv.PlainStateVersion, versionID[:]))
+	}
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		require.NoError(tx.Put(kv.PlainState, k1[:], []byte{1}))
+		require.NoError(tx.Put(kv.PlainState, k2[:], []byte{2}))
+		require.NoError(tx.Put(kv.Code, k1[:], []byte{3}))
+		setVersion(tx)
+		return nil
+	}))
+
+	c := New(cfg)
+	saved, err := c.SaveSnapshot(fileName)
+	require.NoError(err)
+	require.False(saved) // no latest root yet
+
+	c.OnNewBlock(&remote.StateChangeBatch{StateVersionId: id})
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		for _, k := range [][20]byte{k1, k2, k3} {
+			_, err := c.Get(k[:], tx, id)
+			require.NoError(err)
+		}
+		_, err := c.GetCode(k1[:], tx, id)
+		require.NoError(err)
+		return nil
+	}))
+	saved, err = c.SaveSnapshot(fileName)
+	require.NoError(err)
+	require.True(saved)
+
+	// restart
+	c = New(cfg)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		loaded, err := c.LoadSnapshot(ctx, fileName, tx)
+		require.NoError(err)
+		require.True(loaded)
+		require.Equal(3, c.Len())
+
+		hits, codeHits := c.hits.Get(), c.codeHits.Get()
+		v, err := c.Get(k1[:], tx, id)
+		require.NoError(err)
+		require.Equal([]byte{1}, v)
+		v, err = c.Get(k3[:], tx, id)
+		require.NoError(err)
+		require.Nil(v) // absence of key is cached too
+		v, err = c.GetCode(k1[:], tx, id)
+		require.NoError(err)
+		require.Equal([]byte{3}, v)
+		require.Equal(hits+2, c.hits.Get())
+		require.Equal(codeHits+1, c.codeHits.Get())
+
+		// cache already has latest root
+		loaded, err = c.LoadSnapshot(ctx, fileName, tx)
+		require.NoError(err)
+		require.False(loaded)
+		return nil
+	}))
+
+	// db has changed without change of state version - snapshot is discarded by validation
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(kv.PlainState, k2[:], []byte{4})
+	}))
+	c = New(cfg)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		loaded, err := c.LoadSnapshot(ctx, fileName, tx)
+		require.NoError(err)
+		require.False(loaded)
+		require.Equal(0, c.Len())
+		require.Equal(0, c.stateEvict.Len())
+		return nil
+	}))
+
+	// state version of db has changed - snapshot is discarded
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		setVersion(tx)
+		return nil
+	}))
+	c = New(cfg)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		loaded, err := c.LoadSnapshot(ctx, fileName, tx)
+		require.NoError(err)
+		require.False(loaded)
+		require.Equal(0, c.Len())
+		return nil
+	}))
+
+	// corrupted file
+	data, err := os.ReadFile(fileName)
+	require.NoError(err)
+	binary.BigEndian.PutUint64(data[1:], id)
+	require.NoError(os.WriteFile(fileName, data[:len(data)-1], 0644))
+	c = New(cfg)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		_, err := c.LoadSnapshot(ctx, fileName, tx)
+		require.ErrorIs(err, io.ErrUnexpectedEOF)
+		return nil
+	}))
+}
+
+// encodeAccount - same format as kv.PlainState: nonce, balance, incarnation, code hash
+func encodeAccount(nonce uint64, balance uint64, incarnation uint64, codeHash []byte) []byte {
+	enc := []byte{0}
+	field := func(bit byte, v []byte) {
+		enc[0] |= bit
+		enc = append(enc, byte(len(v)))
+		enc = append(enc, v...)
+	}
+	num := func(x uint64) []byte {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], x)
+		i := 0
+		for i < 8 && b[i] == 0 {
+			i++
+		}
+		return b[i:]
+	}
+	if nonce > 0 {
+		field(1, num(nonce))
+	}
+	if balance > 0 {
+		field(2, num(balance))
+	}
+	if incarnation > 0 {
+		field(4, num(incarnation))
+	}
+	if codeHash != nil {
+		field(8, codeHash)
+	}
+	return enc
+}
+
+func TestStorageIncarnation(t *testing.T) {
+	require, ctx := require.New(t), context.Background()
+	cfg := DefaultCoherentConfig
+	cfg.NewBlockWait = 0
+	c := New(cfg)
+	db := memdb.NewTestDB(t)
+	addr := common.Address{1}
+	slot1, slot2 := common.Hash{1}, common.Hash{2}
+	code := []byte{0x60, 0x00}
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(code)
+	codeHash := common.BytesToHash(hasher.Sum(nil))
+
+	change := func(action remote.Action, incarnation uint64, data []byte, slots ...common.Hash) *remote.AccountChange {
+		ac := &remote.AccountChange{
+			Action:      action,
+			Address:     gointerfaces.ConvertAddressToH160(addr),
+			Incarnation: incarnation,
+			Data:        data,
+		}
+		if action == remote.Action_UPSERT_CODE {
+			ac.Code = code
+		}
+		for _, slot := range slots {
+			ac.StorageChanges = append(ac.StorageChanges, &remote.StorageChange{
+				Location: gointerfaces.ConvertHashToH256(slot),
+				Data:     []byte{byte(incarnation)},
+			})
+		}
+		return ac
+	}
+	// writes same changes to db and sends them to cache
+	var id uint64
+	apply := func(changes ...*remote.AccountChange) {
+		require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+			for _, ac := range changes {
+				switch ac.Action {
+				case remote.Action_REMOVE:
+					require.NoError(tx.Delete(kv.PlainState, addr[:]))
+					for _, slot := range []common.Hash{slot1, slot2} {
+						for inc := uint64(1); inc < 3; inc++ {
+							require.NoError(tx.Delete(kv.PlainState, StorageKey(addr, inc, slot)))
+						}
+					}
+				default:
+					require.NoError(tx.Put(kv.PlainState, addr[:], ac.Data))
+					if ac.Code != nil {
+						require.NoError(tx.Put(kv.Code, codeHash[:], ac.Code))
+					}
+				}
+				for _, sc := range ac.StorageChanges {
+					require.NoError(tx.Put(kv.PlainState, StorageKey(addr, ac.Incarnation, gointerfaces.ConvertH256ToHash(sc.Location)), sc.Data))
+				}
+			}
+			id = tx.ViewID()
+			var versionID [8]byte
+			binary.BigEndian.PutUint64(versionID[:], id)
+			return tx.Put(kv.Sequence, kv.PlainStateVersion, versionID[:])
+		}))
+		c.OnNewBlock(&remote.StateChangeBatch{StateVersionId: id, ChangeBatch: []*remote.StateChange{{Direction: remote.Direction_FORWARD, Changes: changes}}})
+	}
+	check := func(f func(view CacheView)) {
+		require.NoError(db.View(ctx, func(tx kv.Tx) error {
+			view, err := c.View(ctx, tx)
+			require.NoError(err)
+			f(view)
+			_, err = AssertCheckValues(ctx, tx, c)
+			require.NoError(err)
+			return nil
+		}))
+	}
+	hasStorage := func(incarnation uint64, slot common.Hash) bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		_, ok := c.latestStateView.cache.Get(&Element{K: StorageKey(addr, incarnation, slot)})
+		return ok
+	}
+
+	apply(change(remote.Action_UPSERT_CODE, 1, encodeAccount(1, 0, 1, codeHash[:]), slot1, slot2))
+	check(func(view CacheView) {
+		acc, err := view.GetAccount(addr)
+		require.NoError(err)
+		require.Equal(uint64(1), acc.Incarnation)
+		require.Equal(codeHash, acc.CodeHash)
+		codeHits := c.codeHits.Get()
+		v, err := view.GetCode(acc.CodeHash[:])
+		require.NoError(err)
+		require.Equal(code, v)
+		require.Equal(codeHits+1, c.codeHits.Get()) // code of UPSERT_CODE is cached by its hash
+		v, err = view.GetStorage(addr, 1, slot1)
+		require.NoError(err)
+		require.Equal([]byte{1}, v)
+	})
+	require.True(hasStorage(1, slot1))
+	require.True(hasStorage(1, slot2))
+
+	// self-destruct: storage of all incarnations is dropped
+	apply(change(remote.Action_REMOVE, 1, nil))
+	require.False(hasStorage(1, slot1))
+	require.False(hasStorage(1, slot2))
+	check(func(view CacheView) {
+		acc, err := view.GetAccount(addr)
+		require.NoError(err)
+		require.Nil(acc)
+		v, err := view.GetStorage(addr, 1, slot1)
+		require.NoError(err)
+		require.Nil(v)
+	})
+
+	// re-created with new incarnation
+	apply(change(remote.Action_UPSERT, 1, encodeAccount(1, 0, 1, codeHash[:]), slot1))
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		_, err := c.Get(StorageKey(addr, 1, slot2), tx, id) // cache absence of slot
+		return err
+	}))
+	require.True(hasStorage(1, slot2))
+	apply(change(remote.Action_UPSERT, 2, encodeAccount(1, 0, 2, codeHash[:]), slot2))
+	require.False(hasStorage(1, slot1))
+	require.False(hasStorage(1, slot2))
+	require.True(hasStorage(2, slot2))
+	check(func(view CacheView) {
+		acc, err := view.GetAccount(addr)
+		require.NoError(err)
+		require.Equal(uint64(2), acc.Incarnation)
+		v, err := view.GetStorage(addr, 2, slot2)
+		require.NoError(err)
+		require.Equal([]byte{2}, v)
+	})
+	require.Equal(c.latestStateView.cache.Len(), c.stateEvict.Len())
+}
+
+func TestStorageEviction(t *testing.T) {
+	for _, policy := range EvictionPolicies {
+		policy := policy
+		t.Run(string(policy), func(t *testing.T) {
+			require, ctx := require.New(t), context.Background()
+			const slotSize = 20 + 8 + 32 + 1
+			cfg := DefaultCoherentConfig
+			cfg.NewBlockWait = 0
+			cfg.CacheSize = 10 * slotSize
+			cfg.EvictionPolicy = policy
+			c := New(cfg)
+			db := memdb.NewTestDB(t)
+			addr := common.Address{1}
+
+			var id uint64
+			for block := 0; block < 5; block++ {
+				ac := &remote.AccountChange{Action: remote.Action_STORAGE, Address: gointerfaces.ConvertAddressToH160(addr), Incarnation: 1}
+				require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+					for i := 0; i < 5; i++ {
+						slot := common.Hash{byte(block), byte(i)}
+						ac.StorageChanges = append(ac.StorageChanges, &remote.StorageChange{Location: gointerfaces.ConvertHashToH256(slot), Data: []byte{byte(block)}})
+						require.NoError(tx.Put(kv.PlainState, StorageKey(addr, 1, slot), []byte{byte(block)}))
+					}
+					id = tx.ViewID()
+					var versionID [8]byte
+					binary.BigEndian.PutUint64(versionID[:], id)
+					return tx.Put(kv.Sequence, kv.PlainStateVersion, versionID[:])
+				}))
+				c.OnNewBlock(&remote.StateChangeBatch{StateVersionId: id, ChangeBatch: []*remote.StateChange{{Direction: remote.Direction_FORWARD, Changes: []*remote.AccountChange{ac}}}})
+				require.LessOrEqual(c.stateEvict.Size(), int(cfg.CacheSize.Bytes()))
+				require.Equal(c.latestStateView.cache.Len(), c.stateEvict.Len())
+			}
+			require.Less(c.Len(), 25)
+
+			// evicted slots are read from db
+			require.NoError(db.View(ctx, func(tx kv.Tx) error {
+				view, err := c.View(ctx, tx)
+				require.NoError(err)
+				for block := 0; block < 5; block++ {
+					for i := 0; i < 5; i++ {
+						v, err := view.GetStorage(addr, 1, common.Hash{byte(block), byte(i)})
+						require.NoError(err)
+						require.Equal([]byte{byte(block)}, v)
+					}
+				}
+				_, err = AssertCheckValues(ctx, tx, c)
+				require.NoError(err)
+				return nil
+			}))
+			require.LessOrEqual(c.stateEvict.Size(), int(cfg.CacheSize.Bytes()))
+		})
+	}
+}
@@ -18,6 +18,7 @@ package kvcache
 import (
 	"context"
 
+	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/kv"
 )
@@ -52,3 +53,9 @@ type DummyView struct {
 
 func (c *DummyView) Get(k []byte) ([]byte, error)     { return c.cache.Get(k, c.tx, 0) }
 func (c *DummyView) GetCode(k []byte) ([]byte, error) { return c.cache.GetCode(k, c.tx, 0) }
+func (c *DummyView) GetAccount(addr common.Address) (*Account, error) {
+	return getAccount(c, addr)
+}
+func (c *DummyView) GetStorage(addr common.Address, incarnation uint64, slot common.Hash) ([]byte, error) {
+	return c.Get(StorageKey(addr, incarnation, slot))
+}
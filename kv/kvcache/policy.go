@@ -0,0 +1,417 @@
+/*
+Copyright 2023 Erigon contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvcache
+
+import (
+	"fmt"
+)
+
+// EvictionPolicy - decides which elements of latest view stay in cache when size of cache exceeds the limit
+// (CoherentConfig.CacheSize or CoherentConfig.CodeCacheSize). Only elements of latest (Canonical) view are
+// passed to policy - see "Rules of filling cache.stateEvict".
+// Coherent calls all methods under Coherent.lock.
+type EvictionPolicy interface {
+	// Add - element `e` was set to latest view, `replaced` - previous element of same key (nil if there was no such key).
+	// Returns elements which must be deleted from latest view to fit into limit, it may include `e` itself -
+	// if policy doesn't admit it. Returned slice is valid until next call of Add.
+	Add(e, replaced *Element) (evicted []*Element)
+	// Touch - element of latest view was read
+	Touch(e *Element)
+	// Remove - element was deleted from latest view not by policy (for example: storage of deleted account)
+	Remove(e *Element)
+	// Init - forgets all elements. Statistic of accesses (if policy has it) may be kept.
+	Init()
+	Len() int
+	Size() int // size of elements in bytes
+}
+
+type EvictionPolicyName string
+
+const (
+	LRUPolicy EvictionPolicyName = "lru"
+	// TinyLFUPolicy - W-TinyLFU: new elements pass through small LRU window, then are admitted to main space
+	// only if they are accessed more frequently than element they would evict. Protects hot set from bursts of one-off keys.
+	// See: Gil Einziger, Roy Friedman, Ben Manes. TinyLFU: A Highly Efficient Cache Admission Policy. https://arxiv.org/abs/1512.00727
+	TinyLFUPolicy EvictionPolicyName = "tinylfu"
+	// ARCPolicy - Adaptive Replacement Cache: balances between recency and frequency by remembering keys of recently evicted elements.
+	// See: Nimrod Megiddo, Dharmendra S. Modha. ARC: A Self-Tuning, Low Overhead Replacement Cache. FAST 2003.
+	ARCPolicy EvictionPolicyName = "arc"
+)
+
+var EvictionPolicies = []EvictionPolicyName{LRUPolicy, TinyLFUPolicy, ARCPolicy}
+
+// NewEvictionPolicy - limit is in bytes. Empty name means LRUPolicy.
+func NewEvictionPolicy(name EvictionPolicyName, limit int) (EvictionPolicy, error) {
+	switch name {
+	case LRUPolicy, "":
+		return &lruPolicy{l: &ThreadSafeEvictionList{l: NewList()}, limit: limit}, nil
+	case TinyLFUPolicy:
+		return newTinyLFUPolicy(limit), nil
+	case ARCPolicy:
+		return newARCPolicy(limit), nil
+	default:
+		return nil, fmt.Errorf("unknown kvcache eviction policy: %q, known: %v", name, EvictionPolicies)
+	}
+}
+
+// clearList - unlike List.Init, also detaches elements from list. Because elements are shared between views,
+// element left attached to re-initialized list may corrupt it later.
+func clearList(l *List) {
+	for e := l.Back(); e != nil; e = l.Back() {
+		l.Remove(e)
+	}
+}
+
+// lruPolicy - evicts least recently used elements
+type lruPolicy struct {
+	l       *ThreadSafeEvictionList
+	evicted []*Element
+	limit   int
+}
+
+func (p *lruPolicy) Add(e, replaced *Element) []*Element {
+	p.evicted = p.evicted[:0]
+	if replaced != nil {
+		p.l.Remove(replaced)
+	}
+	p.l.PushFront(e)
+	for p.l.Size() > p.limit {
+		oldest := p.l.Oldest()
+		p.l.Remove(oldest)
+		p.evicted = append(p.evicted, oldest)
+	}
+	return p.evicted
+}
+func (p *lruPolicy) Touch(e *Element)  { p.l.MoveToFront(e) }
+func (p *lruPolicy) Remove(e *Element) { p.l.Remove(e) }
+func (p *lruPolicy) Init()             { p.l.Init() }
+func (p *lruPolicy) Len() int          { return p.l.Len() }
+func (p *lruPolicy) Size() int         { return p.l.Size() }
+
+const (
+	tinyLFUWindowPercent    = 1  // size of window LRU - in percents of limit
+	tinyLFUProtectedPercent = 80 // size of protected segment - in percents of main space
+	tinyLFUAvgElementSize   = 64 // used to choose width of frequency sketch
+)
+
+// tinyLFUPolicy - window LRU + main space as segmented LRU (probation and protected segments).
+// Element evicted from window competes with victim of main space: one with bigger estimated frequency stays.
+type tinyLFUPolicy struct {
+	sketch    *countMinSketch
+	window    *List
+	probation *List // seen once in main space
+	protected *List // accessed at least once while in probation
+	evicted   []*Element
+
+	windowLimit, mainLimit, protectedLimit int
+}
+
+func newTinyLFUPolicy(limit int) *tinyLFUPolicy {
+	windowLimit := limit * tinyLFUWindowPercent / 100
+	mainLimit := limit - windowLimit
+	return &tinyLFUPolicy{
+		sketch:         newCountMinSketch(limit / tinyLFUAvgElementSize),
+		window:         NewList(),
+		probation:      NewList(),
+		protected:      NewList(),
+		windowLimit:    windowLimit,
+		mainLimit:      mainLimit,
+		protectedLimit: mainLimit * tinyLFUProtectedPercent / 100,
+	}
+}
+
+func (p *tinyLFUPolicy) Add(e, replaced *Element) []*Element {
+	p.evicted = p.evicted[:0]
+	p.sketch.increment(e.K)
+	if replaced != nil && p.owns(replaced) { // update of existing key - keeps its segment
+		l := replaced.list
+		l.Remove(replaced)
+		l.PushFront(e)
+	} else {
+		p.window.PushFront(e)
+	}
+	for p.window.Size() > p.windowLimit {
+		candidate := p.window.Back()
+		p.window.Remove(candidate)
+		p.admit(candidate)
+	}
+	for p.mainSize() > p.mainLimit { // update could make element bigger
+		p.evict(p.victim())
+	}
+	p.demote()
+	return p.evicted
+}
+
+// admit - moves candidate from window to probation segment, if it's used more frequently than elements it would evict
+func (p *tinyLFUPolicy) admit(candidate *Element) {
+	for p.mainSize()+candidate.Size() > p.mainLimit {
+		victim := p.victim()
+		if victim == nil { // candidate is bigger than main space
+			p.evicted = append(p.evicted, candidate)
+			return
+		}
+		if p.sketch.estimate(candidate.K) <= p.sketch.estimate(victim.K) {
+			p.evicted = append(p.evicted, candidate)
+			return
+		}
+		p.evict(victim)
+	}
+	p.probation.PushFront(candidate)
+}
+
+func (p *tinyLFUPolicy) victim() *Element {
+	if e := p.probation.Back(); e != nil {
+		return e
+	}
+	return p.protected.Back()
+}
+
+func (p *tinyLFUPolicy) evict(e *Element) {
+	e.list.Remove(e)
+	p.evicted = append(p.evicted, e)
+}
+
+// demote - moves least recently used elements of protected segment to probation segment
+func (p *tinyLFUPolicy) demote() {
+	for p.protected.Size() > p.protectedLimit {
+		e := p.protected.Back()
+		p.protected.Remove(e)
+		p.probation.PushFront(e)
+	}
+}
+
+func (p *tinyLFUPolicy) Touch(e *Element) {
+	p.sketch.increment(e.K)
+	switch e.list {
+	case p.window:
+		p.window.MoveToFront(e)
+	case p.probation:
+		p.probation.Remove(e)
+		p.protected.PushFront(e)
+		p.demote()
+	case p.protected:
+		p.protected.MoveToFront(e)
+	}
+}
+
+func (p *tinyLFUPolicy) Remove(e *Element) {
+	if p.owns(e) {
+		e.list.Remove(e)
+	}
+}
+
+func (p *tinyLFUPolicy) owns(e *Element) bool {
+	return e.list != nil && (e.list == p.window || e.list == p.probation || e.list == p.protected)
+}
+func (p *tinyLFUPolicy) mainSize() int { return p.probation.Size() + p.protected.Size() }
+func (p *tinyLFUPolicy) Init() {
+	clearList(p.window)
+	clearList(p.probation)
+	clearList(p.protected)
+}
+func (p *tinyLFUPolicy) Len() int { return p.window.Len() + p.probation.Len() + p.protected.Len() }
+func (p *tinyLFUPolicy) Size() int {
+	return p.window.Size() + p.probation.Size() + p.protected.Size()
+}
+
+const (
+	sketchDepth    = 4
+	sketchMinWidth = 64
+	sketchMaxWidth = 1 << 22
+	sketchMaxCount = 15 // 4-bit counters
+)
+
+// countMinSketch - approximate frequency of keys: `sketchDepth` rows of 4-bit counters, estimation is minimum of counters of key.
+// When amount of increments reaches 10*width, all counters are halved - so old popularity fades away.
+type countMinSketch struct {
+	rows       [sketchDepth][]uint64 // 16 counters per word
+	mask       uint64
+	additions  int
+	resetLimit int
+}
+
+func newCountMinSketch(width int) *countMinSketch {
+	w := sketchMinWidth
+	for w < width && w < sketchMaxWidth {
+		w <<= 1
+	}
+	s := &countMinSketch{mask: uint64(w - 1), resetLimit: 10 * w}
+	for i := range s.rows {
+		s.rows[i] = make([]uint64, w/16)
+	}
+	return s
+}
+
+func (s *countMinSketch) indices(k []byte) (h1, h2 uint64) {
+	h := keyHash(k)
+	return h, h>>32 | 1
+}
+
+func (s *countMinSketch) increment(k []byte) {
+	h1, h2 := s.indices(k)
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		word, shift := idx/16, (idx%16)*4
+		if (s.rows[i][word]>>shift)&0xf < sketchMaxCount {
+			s.rows[i][word] += 1 << shift
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetLimit {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(k []byte) uint64 {
+	h1, h2 := s.indices(k)
+	min := uint64(sketchMaxCount)
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		if c := (s.rows[i][idx/16] >> ((idx % 16) * 4)) & 0xf; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// reset - halves all counters
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = (s.rows[i][j] >> 1) & 0x7777777777777777
+		}
+	}
+	s.additions /= 2
+}
+
+// keyHash - FNV-1a with final mixing (keys are addresses and hashes - low bits are already random, but not guaranteed)
+func keyHash(k []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, b := range k {
+		h ^= uint64(b)
+		h *= 1099511628211
+	}
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
+
+// arcPolicy - resident elements are in t1 (seen once recently) and t2 (seen at least twice), keys of elements evicted
+// from them are remembered in ghost lists b1 and b2. Hit in ghost list adapts target size of t1.
+// Sizes are in bytes, amount of ghost keys is limited by amount of resident elements.
+type arcPolicy struct {
+	t1, t2  *List
+	b1, b2  *List // ghost elements: only K is set
+	ghosts  map[string]*Element
+	evicted []*Element
+	limit   int
+	target  int // target size of t1 in bytes
+}
+
+func newARCPolicy(limit int) *arcPolicy {
+	return &arcPolicy{t1: NewList(), t2: NewList(), b1: NewList(), b2: NewList(), ghosts: map[string]*Element{}, limit: limit}
+}
+
+func (p *arcPolicy) Add(e, replaced *Element) []*Element {
+	p.evicted = p.evicted[:0]
+	if replaced != nil && replaced.list != nil && (replaced.list == p.t1 || replaced.list == p.t2) {
+		replaced.list.Remove(replaced)
+		p.t2.PushFront(e)
+	} else if g, ok := p.ghosts[string(e.K)]; ok {
+		if g.list == p.b1 {
+			p.target = minInt(p.limit, p.target+e.Size()*maxInt(1, p.b2.Len()/p.b1.Len()))
+		} else {
+			p.target = maxInt(0, p.target-e.Size()*maxInt(1, p.b1.Len()/p.b2.Len()))
+		}
+		g.list.Remove(g)
+		delete(p.ghosts, string(e.K))
+		p.t2.PushFront(e)
+	} else {
+		p.t1.PushFront(e)
+	}
+	for p.Size() > p.limit {
+		p.replace()
+	}
+	for p.b1.Len()+p.b2.Len() > p.Len() {
+		if p.b1.Len() > p.b2.Len() {
+			p.forget(p.b1.Back())
+		} else {
+			p.forget(p.b2.Back())
+		}
+	}
+	return p.evicted
+}
+
+// replace - evicts least recently used element of t1 or t2 and remembers its key in corresponding ghost list
+func (p *arcPolicy) replace() {
+	var e *Element
+	var ghosts *List
+	if p.t1.Len() > 0 && (p.t1.Size() > p.target || p.t2.Len() == 0) {
+		e, ghosts = p.t1.Back(), p.b1
+	} else {
+		e, ghosts = p.t2.Back(), p.b2
+	}
+	e.list.Remove(e)
+	p.evicted = append(p.evicted, e)
+	g := ghosts.PushFront(&Element{K: e.K})
+	p.ghosts[string(e.K)] = g
+}
+
+func (p *arcPolicy) forget(g *Element) {
+	g.list.Remove(g)
+	delete(p.ghosts, string(g.K))
+}
+
+func (p *arcPolicy) Touch(e *Element) {
+	switch e.list {
+	case p.t1:
+		p.t1.Remove(e)
+		p.t2.PushFront(e)
+	case p.t2:
+		p.t2.MoveToFront(e)
+	}
+}
+
+func (p *arcPolicy) Remove(e *Element) {
+	if e.list != nil && (e.list == p.t1 || e.list == p.t2) {
+		e.list.Remove(e)
+	}
+}
+
+func (p *arcPolicy) Init() {
+	clearList(p.t1)
+	clearList(p.t2)
+	p.b1.Init()
+	p.b2.Init()
+	p.ghosts = map[string]*Element{}
+	p.target = 0
+}
+func (p *arcPolicy) Len() int  { return p.t1.Len() + p.t2.Len() }
+func (p *arcPolicy) Size() int { return p.t1.Size() + p.t2.Size() }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
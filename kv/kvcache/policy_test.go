@@ -0,0 +1,291 @@
+/*
+Copyright 2023 Erigon contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+)
+
+// policyCache - minimal cache on top of policy: same as latest view of Coherent
+type policyCache struct {
+	p        EvictionPolicy
+	resident map[string]*Element
+}
+
+func newPolicyCache(t testing.TB, name EvictionPolicyName, limit int) *policyCache {
+	p, err := NewEvictionPolicy(name, limit)
+	require.NoError(t, err)
+	return &policyCache{p: p, resident: map[string]*Element{}}
+}
+
+// get - returns true on hit, adds key on miss
+func (c *policyCache) get(k []byte) bool {
+	if e, ok := c.resident[string(k)]; ok {
+		c.p.Touch(e)
+		return true
+	}
+	c.set(k, []byte{1})
+	return false
+}
+
+func (c *policyCache) set(k, v []byte) {
+	e := &Element{K: k, V: v}
+	replaced := c.resident[string(k)]
+	c.resident[string(k)] = e
+	for _, evicted := range c.p.Add(e, replaced) {
+		delete(c.resident, string(evicted.K))
+	}
+}
+
+func testKey(i int) []byte {
+	var k [20]byte
+	binary.BigEndian.PutUint64(k[12:], uint64(i))
+	return k[:]
+}
+
+func TestEvictionPolicies(t *testing.T) {
+	for _, name := range EvictionPolicies {
+		name := name
+		t.Run(string(name), func(t *testing.T) {
+			require := require.New(t)
+			limit := 100 * 21
+			c := newPolicyCache(t, name, limit)
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 10_000; i++ {
+				k := testKey(rnd.Intn(300))
+				if rnd.Intn(4) == 0 {
+					c.set(k, make([]byte, 1+rnd.Intn(3)))
+				} else {
+					c.get(k)
+				}
+				require.LessOrEqual(c.p.Size(), limit)
+				require.Equal(len(c.resident), c.p.Len())
+			}
+			size := 0
+			for _, e := range c.resident {
+				size += e.Size()
+			}
+			require.Equal(size, c.p.Size())
+			require.Greater(c.p.Len(), 50)
+
+			// element bigger than limit is not kept
+			c.set(testKey(1_000), make([]byte, limit))
+			_, ok := c.resident[string(testKey(1_000))]
+			require.False(ok)
+			require.Equal(len(c.resident), c.p.Len())
+
+			c.p.Init()
+			require.Equal(0, c.p.Len())
+			require.Equal(0, c.p.Size())
+			for _, e := range c.resident {
+				require.Nil(e.list)
+			}
+		})
+	}
+
+	_, err := NewEvictionPolicy("fifo", 1)
+	require.Error(t, err)
+}
+
+// TestEvictionPoliciesHotSet - hot set fits into cache and is accessed all the time, but bursts of one-off keys
+// are bigger than cache. LRU loses hot set on each burst, other policies must keep it.
+func TestEvictionPoliciesHotSet(t *testing.T) {
+	const hotKeys, burst = 50, 200
+	hitRatio := map[EvictionPolicyName]float64{}
+	for _, name := range EvictionPolicies {
+		c := newPolicyCache(t, name, 100*21)
+		rnd := rand.New(rand.NewSource(1))
+		oneOff := hotKeys
+		var hits, total int
+		for round := 0; round < 50; round++ {
+			for i := 0; i < 500; i++ {
+				if c.get(testKey(rnd.Intn(hotKeys))) {
+					hits++
+				}
+				total++
+			}
+			for i := 0; i < burst; i++ {
+				c.get(testKey(oneOff))
+				oneOff++
+			}
+		}
+		hitRatio[name] = float64(hits) / float64(total)
+	}
+	require.Greater(t, hitRatio[TinyLFUPolicy], hitRatio[LRUPolicy]+0.05, hitRatio)
+	require.Greater(t, hitRatio[ARCPolicy], hitRatio[LRUPolicy]+0.05, hitRatio)
+}
+
+func TestCoherentEvictionPolicies(t *testing.T) {
+	for _, name := range EvictionPolicies {
+		name := name
+		t.Run(string(name), func(t *testing.T) {
+			require, ctx := require.New(t), context.Background()
+			cfg := DefaultCoherentConfig
+			cfg.CacheSize = 10 * 21
+			cfg.NewBlockWait = 0
+			cfg.EvictionPolicy = name
+			cfg.MetricsLabel = "test_" + string(name)
+			c := New(cfg)
+			db := memdb.NewTestDB(t)
+
+			for id := uint64(1); id <= 20; id++ {
+				batch := &remote.StateChangeBatch{StateVersionId: id, ChangeBatch: []*remote.StateChange{{Direction: remote.Direction_FORWARD}}}
+				for i := 0; i < 5; i++ {
+					var addr [20]byte
+					addr[0] = byte(id*5) + byte(i)
+					batch.ChangeBatch[0].Changes = append(batch.ChangeBatch[0].Changes, &remote.AccountChange{
+						Action:  remote.Action_UPSERT,
+						Address: gointerfaces.ConvertAddressToH160(addr),
+						Data:    []byte{byte(id)},
+					})
+				}
+				c.OnNewBlock(batch)
+				require.NoError(db.View(ctx, func(tx kv.Tx) error {
+					for i := 0; i < 5; i++ {
+						if _, err := c.Get([]byte{byte(i)}, tx, id); err != nil {
+							return err
+						}
+					}
+					return nil
+				}))
+				require.Equal(c.roots[c.latestStateVersionID].cache.Len(), c.stateEvict.Len())
+				require.LessOrEqual(c.stateEvict.Size(), int(cfg.CacheSize.Bytes()))
+			}
+
+			// non-consecutive version: policy is re-filled from the root
+			c.OnNewBlock(&remote.StateChangeBatch{StateVersionId: 100})
+			require.Equal(c.roots[c.latestStateVersionID].cache.Len(), c.stateEvict.Len())
+		})
+	}
+}
+
+func TestStateChangesReplay(t *testing.T) {
+	require := require.New(t)
+	batches := syntheticStateChanges(10, 1)
+	var buf bytes.Buffer
+	for _, sc := range batches {
+		require.NoError(WriteStateChanges(&buf, sc))
+	}
+	data := buf.Bytes()
+
+	i := 0
+	require.NoError(ReadStateChanges(bytes.NewReader(data), func(sc *remote.StateChangeBatch) error {
+		require.Equal(batches[i].StateVersionId, sc.StateVersionId)
+		require.Equal(len(batches[i].ChangeBatch[0].Changes), len(sc.ChangeBatch[0].Changes))
+		i++
+		return nil
+	}))
+	require.Equal(len(batches), i)
+	require.Error(ReadStateChanges(bytes.NewReader(data[:len(data)-1]), func(sc *remote.StateChangeBatch) error { return nil }))
+}
+
+// syntheticStateChanges - txpool-like workload: each block touches senders from hot set,
+// every 10th block also has burst of one-off accounts
+func syntheticStateChanges(blocks int, seed int64) []*remote.StateChangeBatch {
+	const hotSenders, perBlock, burst = 2_000, 200, 5_000
+	rnd := rand.New(rand.NewSource(seed))
+	oneOff := hotSenders
+	res := make([]*remote.StateChangeBatch, 0, blocks)
+	for b := 1; b <= blocks; b++ {
+		sc := &remote.StateChange{Direction: remote.Direction_FORWARD, BlockHeight: uint64(b)}
+		account := func(i int) {
+			var addr [20]byte
+			copy(addr[:], testKey(i))
+			sc.Changes = append(sc.Changes, &remote.AccountChange{
+				Action:  remote.Action_UPSERT,
+				Address: gointerfaces.ConvertAddressToH160(addr),
+				Data:    make([]byte, 40),
+			})
+		}
+		for i := 0; i < perBlock; i++ {
+			account(int(rnd.ExpFloat64()*hotSenders/4) % hotSenders)
+		}
+		if b%10 == 0 {
+			for i := 0; i < burst; i++ {
+				account(oneOff)
+				oneOff++
+			}
+		}
+		res = append(res, &remote.StateChangeBatch{StateVersionId: uint64(b), ChangeBatch: []*remote.StateChange{sc}})
+	}
+	return res
+}
+
+// BenchmarkEvictionPolicies - replays stream of StateChangeBatch: accounts changed by block N are read from
+// view of block N-1 (as txpool validates pending txs of senders), hit ratio is reported.
+// Recorded stream (see WriteStateChanges) can be passed by env variable: KVCACHE_REPLAY=/path/to/stream
+func BenchmarkEvictionPolicies(b *testing.B) {
+	var batches []*remote.StateChangeBatch
+	if fileName := os.Getenv("KVCACHE_REPLAY"); fileName != "" {
+		f, err := os.Open(fileName)
+		require.NoError(b, err)
+		defer f.Close()
+		require.NoError(b, ReadStateChanges(f, func(sc *remote.StateChangeBatch) error {
+			batches = append(batches, sc)
+			return nil
+		}))
+	} else {
+		batches = syntheticStateChanges(200, 1)
+	}
+	db := memdb.NewTestDB(b)
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(b, err)
+	defer tx.Rollback()
+
+	for _, name := range EvictionPolicies {
+		name := name
+		b.Run(string(name), func(b *testing.B) {
+			var hits, total uint64
+			for n := 0; n < b.N; n++ {
+				cfg := DefaultCoherentConfig
+				cfg.CacheSize = 1_000 * (20 + 40)
+				cfg.EvictionPolicy = name
+				cfg.MetricsLabel = "bench_" + string(name)
+				c := New(cfg)
+				hits0, miss0 := c.hits.Get(), c.miss.Get()
+				for i, sc := range batches {
+					if i > 0 {
+						prevID := batches[i-1].StateVersionId
+						for _, change := range sc.ChangeBatch {
+							for _, ac := range change.Changes {
+								addr := gointerfaces.ConvertH160toAddress(ac.Address)
+								if _, err := c.Get(addr[:], tx, prevID); err != nil {
+									b.Fatal(err)
+								}
+							}
+						}
+					}
+					c.OnNewBlock(sc)
+				}
+				hits += c.hits.Get() - hits0
+				total += c.hits.Get() - hits0 + c.miss.Get() - miss0
+			}
+			b.ReportMetric(100*float64(hits)/float64(total), "hit%")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 Erigon contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvcache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+)
+
+// Recorded stream of StateChangeBatch messages - for comparison of eviction policies on real workload
+// (see BenchmarkEvictionPolicies). Each message is stored as: uvarint(len(msg)), protobuf-encoded msg.
+
+// WriteStateChanges - appends one batch to recorded stream
+func WriteStateChanges(w io.Writer, sc *remote.StateChangeBatch) error {
+	data, err := proto.Marshal(sc)
+	if err != nil {
+		return err
+	}
+	var numBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(numBuf[:], uint64(len(data)))
+	if _, err = w.Write(numBuf[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadStateChanges - calls f for each batch of recorded stream, in order they were written
+func ReadStateChanges(r io.Reader, f func(sc *remote.StateChangeBatch) error) error {
+	br := bufio.NewReader(r)
+	var buf []byte
+	for i := 0; ; i++ {
+		l, err := binary.ReadUvarint(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading length of state changes batch %d: %w", i, err)
+		}
+		if uint64(cap(buf)) < l {
+			buf = make([]byte, l)
+		}
+		buf = buf[:l]
+		if _, err = io.ReadFull(br, buf); err != nil {
+			return fmt.Errorf("reading state changes batch %d: %w", i, err)
+		}
+		sc := &remote.StateChangeBatch{}
+		if err = proto.Unmarshal(buf, sc); err != nil {
+			return fmt.Errorf("decoding state changes batch %d: %w", i, err)
+		}
+		if err = f(sc); err != nil {
+			return err
+		}
+	}
+}
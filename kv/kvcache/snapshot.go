@@ -0,0 +1,247 @@
+/*
+Copyright 2023 Erigon contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvcache
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	btree2 "github.com/tidwall/btree"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// Warm-start snapshot: latest root of cache persisted on shutdown (SaveSnapshot) and loaded on startup (LoadSnapshot),
+// so first blocks after restart don't hit db for every key.
+//
+// File format: formatVersion(1), stateVersionID(8), state entries, code entries.
+// Entries: uvarint(count), then for each entry: uvarint(len(k)), k, uvarint(len(v)+1) (0 - means v==nil: key is absent in db), v.
+
+const (
+	snapshotFormatVersion = 1
+	snapshotMaxKeyLen     = 1024
+)
+
+// SaveSnapshot - persists latest root of cache. File is written to fileName+".tmp" and renamed.
+// Returns false if there is no latest root yet (nothing is written then).
+func (c *Coherent) SaveSnapshot(fileName string) (saved bool, err error) {
+	c.lock.Lock()
+	r, id := c.latestStateView, c.latestStateVersionID
+	var cache, codeCache *btree2.BTreeG[*Element]
+	if r != nil {
+		cache, codeCache = r.cache.Copy(), r.codeCache.Copy()
+	}
+	c.lock.Unlock()
+	if r == nil {
+		return false, nil
+	}
+
+	tmpFileName := fileName + ".tmp"
+	defer os.Remove(tmpFileName)
+	f, err := os.Create(tmpFileName)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	var header [1 + 8]byte
+	header[0] = snapshotFormatVersion
+	binary.BigEndian.PutUint64(header[1:], id)
+	if _, err = w.Write(header[:]); err != nil {
+		return false, err
+	}
+	if err = writeSnapshotEntries(w, cache); err != nil {
+		return false, fmt.Errorf("kvcache snapshot %s: %w", fileName, err)
+	}
+	if err = writeSnapshotEntries(w, codeCache); err != nil {
+		return false, fmt.Errorf("kvcache snapshot %s: %w", fileName, err)
+	}
+	if err = w.Flush(); err != nil {
+		return false, err
+	}
+	if err = f.Sync(); err != nil {
+		return false, err
+	}
+	if err = f.Close(); err != nil {
+		return false, err
+	}
+	if err = os.Rename(tmpFileName, fileName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func writeSnapshotEntries(w io.Writer, cache *btree2.BTreeG[*Element]) (err error) {
+	var numBuf [binary.MaxVarintLen64]byte
+	writeNum := func(n uint64) error {
+		_, err := w.Write(numBuf[:binary.PutUvarint(numBuf[:], n)])
+		return err
+	}
+	if err = writeNum(uint64(cache.Len())); err != nil {
+		return err
+	}
+	cache.Walk(func(items []*Element) bool {
+		for _, it := range items {
+			if err = writeNum(uint64(len(it.K))); err != nil {
+				return false
+			}
+			if _, err = w.Write(it.K); err != nil {
+				return false
+			}
+			vLen := uint64(0)
+			if it.V != nil {
+				vLen = uint64(len(it.V)) + 1
+			}
+			if err = writeNum(vLen); err != nil {
+				return false
+			}
+			if _, err = w.Write(it.V); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// LoadSnapshot - fills empty cache by snapshot persisted by SaveSnapshot. Snapshot is discarded (loaded=false, err=nil) if:
+//   - stateVersionID of snapshot doesn't match kv.PlainStateVersion of given tx
+//   - cache already received new block
+//   - ValidateCurrentRoot found values which are different from db
+//
+// File is not removed - it's up to caller.
+func (c *Coherent) LoadSnapshot(ctx context.Context, fileName string, tx kv.Tx) (loaded bool, err error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var header [1 + 8]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return false, fmt.Errorf("kvcache snapshot %s: reading header: %w", fileName, unexpectedEOF(err))
+	}
+	if header[0] != snapshotFormatVersion {
+		return false, fmt.Errorf("kvcache snapshot %s: unsupported format version %d", fileName, header[0])
+	}
+	id := binary.BigEndian.Uint64(header[1:])
+
+	idBytes, err := tx.GetOne(kv.Sequence, kv.PlainStateVersion)
+	if err != nil {
+		return false, err
+	}
+	if len(idBytes) == 0 || binary.BigEndian.Uint64(idBytes) != id {
+		return false, nil
+	}
+
+	state, err := readSnapshotEntries(r)
+	if err != nil {
+		return false, fmt.Errorf("kvcache snapshot %s: reading state: %w", fileName, err)
+	}
+	code, err := readSnapshotEntries(r)
+	if err != nil {
+		return false, fmt.Errorf("kvcache snapshot %s: reading code: %w", fileName, err)
+	}
+	if _, err = r.ReadByte(); !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("kvcache snapshot %s: unexpected data after code entries", fileName)
+	}
+
+	if !c.loadRoot(id, state, code) {
+		return false, nil
+	}
+	result, err := c.ValidateCurrentRoot(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if result.RequestCancelled {
+		c.lock.Lock()
+		root, isLatest := c.latestStateView, c.latestStateVersionID == id
+		c.lock.Unlock()
+		if isLatest {
+			c.clearCaches(root)
+		}
+		return false, ctx.Err()
+	}
+	return !result.CacheCleared, nil
+}
+
+func readSnapshotEntries(r *bufio.Reader) ([]*Element, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	entries := make([]*Element, 0, minInt(int(count), 1<<16)) // count is not trusted
+	for i := uint64(0); i < count; i++ {
+		kLen, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if kLen > snapshotMaxKeyLen {
+			return nil, fmt.Errorf("key length %d is too big", kLen)
+		}
+		e := &Element{K: make([]byte, kLen)}
+		if _, err = io.ReadFull(r, e.K); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		vLen, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if vLen > 0 {
+			e.V = make([]byte, vLen-1)
+			if _, err = io.ReadFull(r, e.V); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// loadRoot - makes root of snapshot latest one, as if OnNewBlock with all snapshot entries was received.
+// Returns false if cache already has latest root.
+func (c *Coherent) loadRoot(id uint64, state, code []*Element) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.latestStateView != nil {
+		return false
+	}
+	r := c.advanceRoot(id)
+	for _, e := range state {
+		c.add(e.K, e.V, r, id)
+	}
+	for _, e := range code {
+		c.addCode(e.K, e.V, r, id)
+	}
+	c.keys.Set(uint64(r.cache.Len()))
+	c.codeKeys.Set(uint64(r.codeCache.Len()))
+	if r.readyChanClosed.CompareAndSwap(false, true) {
+		close(r.ready)
+	}
+	return true
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mdbx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/torquem-ch/mdbx-go/mdbx"
+
+	"github.com/ledgerwatch/erigon-lib/common/background"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// Backup - hot backup: copies all tables of db (also tables unknown to TableCfg) to new db at dstPath,
+// while db is used by readers and writer.
+//
+// Copy is consistent: it's made in one read transaction, so it has state of db at moment when Backup started
+// and doesn't have writes committed after it. Data is copied by cursors in order of keys (mdbx-go doesn't expose
+// mdbx_env_copy), so pages of copy are always densely filled and free pages of source are not copied:
+//   - compact=true: file of copy has minimal size
+//   - compact=false: file of copy is pre-allocated to current size of source file, so copy can replace source without re-growth
+//
+// progress is optional: Total - amount of entries in all tables, Processed - amount of copied entries.
+//
+// Copy is built in temporary dir next to dstPath and renamed to dstPath when all tables are copied: on error or
+// cancellation of ctx nothing is left at dstPath.
+func (db *MdbxKV) Backup(ctx context.Context, dstPath string, compact bool, progress *background.Progress) error {
+	if _, err := os.Stat(filepath.Join(dstPath, "mdbx.dat")); err == nil {
+		return fmt.Errorf("backup: db already exists at %s", dstPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	tmpPath, err := os.MkdirTemp(filepath.Dir(dstPath), filepath.Base(dstPath)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	if err = db.backupTo(ctx, tmpPath, compact, progress); err != nil {
+		_ = os.RemoveAll(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, dstPath); err != nil {
+		_ = os.RemoveAll(tmpPath)
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
+}
+
+// backupTo - copies all tables to new db at dstPath, db is closed on return
+func (db *MdbxKV) backupTo(ctx context.Context, dstPath string, compact bool, progress *background.Progress) error {
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	srcTx := tx.(*MdbxTx).tx
+
+	tables, err := srcTx.ListDBI()
+	if err != nil {
+		return err
+	}
+	sort.Strings(tables)
+	dbis := make([]mdbx.DBI, len(tables))
+	flags := make([]uint, len(tables))
+	var total uint64
+	for i, name := range tables {
+		if dbis[i], err = srcTx.OpenDBI(name, mdbx.DBAccede, nil, nil); err != nil {
+			return fmt.Errorf("backup: table %s: %w", name, err)
+		}
+		if flags[i], err = srcTx.Flags(dbis[i]); err != nil {
+			return fmt.Errorf("backup: table %s: %w", name, err)
+		}
+		st, err := srcTx.StatDBI(dbis[i])
+		if err != nil {
+			return fmt.Errorf("backup: table %s: %w", name, err)
+		}
+		total += st.Entries
+	}
+	if progress != nil {
+		progress.Total.Store(total)
+		progress.Processed.Store(0)
+	}
+
+	dst, err := NewMDBX(db.log).Path(dstPath).Label(db.opts.label).
+		PageSize(db.opts.pageSize).MapSize(db.opts.mapSize).GrowthStep(db.opts.growthStep).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return kv.TableCfg{} }).
+		Open()
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	defer dst.Close()
+	dstDB := dst.(*MdbxKV)
+	if !compact {
+		info, err := db.env.Info(srcTx)
+		if err != nil {
+			return err
+		}
+		if err = dstDB.env.SetGeometry(-1, int(info.Geo.Current), -1, -1, -1, -1); err != nil {
+			return fmt.Errorf("backup: pre-allocate %s: %w", dstPath, err)
+		}
+	}
+
+	for i, name := range tables {
+		if err := dstDB.copyTable(ctx, srcTx, dbis[i], name, flags[i], progress); err != nil {
+			return fmt.Errorf("backup: table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// copyTable - appends all entries of source table to new table of db. Commits every db.txSize/2 bytes.
+func (db *MdbxKV) copyTable(ctx context.Context, srcTx *mdbx.Txn, srcDBI mdbx.DBI, name string, flags uint, progress *background.Progress) error {
+	src, err := srcTx.OpenCursor(srcDBI)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	putFlags := uint(mdbx.Append)
+	if flags&mdbx.DupSort != 0 {
+		putFlags = mdbx.AppendDup
+	}
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { tx.Rollback() }()
+	dbi, err := tx.(*MdbxTx).tx.OpenDBI(name, flags|mdbx.Create, nil, nil)
+	if err != nil {
+		return err
+	}
+	dst, err := tx.(*MdbxTx).tx.OpenCursor(dbi)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if dst != nil {
+			dst.Close()
+		}
+	}()
+
+	var written, processed uint64
+	for k, v, err := src.Get(nil, nil, mdbx.First); ; k, v, err = src.Get(nil, nil, mdbx.Next) {
+		if err != nil {
+			if mdbx.IsNotFound(err) {
+				break
+			}
+			return err
+		}
+		if err = dst.Put(k, v, putFlags); err != nil {
+			return err
+		}
+		written += uint64(len(k) + len(v))
+		processed++
+		if processed%4096 == 0 {
+			if progress != nil {
+				progress.Processed.Add(4096)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		if written > db.txSize/2 {
+			dst.Close()
+			dst = nil
+			if err = tx.Commit(); err != nil {
+				return err
+			}
+			next, err := db.BeginRw(ctx)
+			if err != nil {
+				return err
+			}
+			tx = next
+			if dst, err = tx.(*MdbxTx).tx.OpenCursor(dbi); err != nil {
+				return err
+			}
+			written = 0
+		}
+	}
+	if progress != nil {
+		progress.Processed.Add(processed % 4096)
+	}
+	dst.Close()
+	return tx.Commit()
+}
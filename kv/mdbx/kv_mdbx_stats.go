@@ -0,0 +1,188 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mdbx
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/torquem-ch/mdbx-go/mdbx"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// TableStats - space usage of one table. Fields marked "scan" are filled only if Stats was called with scan=true:
+// they require reading of whole table.
+type TableStats struct {
+	Name          string `json:"name"`
+	DupSort       bool   `json:"dupsort"`
+	Entries       uint64 `json:"entries"` // for DupSort tables - amount of key-value pairs
+	Depth         uint   `json:"depth"`
+	BranchPages   uint64 `json:"branch_pages"`
+	LeafPages     uint64 `json:"leaf_pages"`
+	OverflowPages uint64 `json:"overflow_pages"`
+	Size          uint64 `json:"size"` // bytes of all pages of table
+
+	Keys        uint64  `json:"keys,omitempty"`         // scan: amount of distinct keys
+	MaxDups     uint64  `json:"max_dups,omitempty"`     // scan: max amount of values of one key (DupSort tables)
+	PayloadSize uint64  `json:"payload_size,omitempty"` // scan: bytes of keys and values
+	Utilization float64 `json:"utilization,omitempty"`  // scan: PayloadSize/Size - how much of pages is used by data
+}
+
+// DBStats - space usage report of db: tables of kv.TableCfg (which exist in db) sorted by name, and free-list
+type DBStats struct {
+	Label     string       `json:"label"`
+	PageSize  uint64       `json:"page_size"`
+	FileSize  uint64       `json:"file_size"`
+	UsedSize  uint64       `json:"used_size"`  // bytes of allocated pages (including free ones)
+	FreePages uint64       `json:"free_pages"` // pages in free-list (GC), can be re-used by next writes
+	FreeSize  uint64       `json:"free_size"`
+	GC        TableStats   `json:"gc"` // table of free-list itself
+	Tables    []TableStats `json:"tables"`
+}
+
+// Stats - see DBStats. scan=true - reads all tables to fill "scan" fields of TableStats, it's slow on big db.
+func (db *MdbxKV) Stats(ctx context.Context, scan bool) (stats *DBStats, err error) {
+	err = db.View(ctx, func(tx kv.Tx) error {
+		stats, err = tx.(*MdbxTx).Stats(scan)
+		return err
+	})
+	return stats, err
+}
+
+// Stats - see DBStats
+func (tx *MdbxTx) Stats(scan bool) (*DBStats, error) {
+	info, err := tx.db.env.Info(tx.tx)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := tx.db.opts.pageSize
+	stats := &DBStats{
+		Label:    tx.db.opts.label.String(),
+		PageSize: pageSize,
+		FileSize: info.Geo.Current,
+		UsedSize: uint64(info.LastPNO+1) * pageSize,
+	}
+
+	gc, err := tx.tableStats("gc", mdbx.DBI(0), false, false)
+	if err != nil {
+		return nil, err
+	}
+	stats.GC = *gc
+	if stats.FreePages, err = tx.freePages(); err != nil {
+		return nil, err
+	}
+	stats.FreeSize = stats.FreePages * pageSize
+
+	names := make([]string, 0, len(tx.db.buckets))
+	for name, cfg := range tx.db.buckets {
+		if cfg.DBI == NonExistingDBI {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg := tx.db.buckets[name]
+		st, err := tx.tableStats(name, mdbx.DBI(cfg.DBI), cfg.Flags&kv.DupSort != 0, scan)
+		if err != nil {
+			return nil, err
+		}
+		stats.Tables = append(stats.Tables, *st)
+	}
+	return stats, nil
+}
+
+func (tx *MdbxTx) tableStats(name string, dbi mdbx.DBI, dupSort, scan bool) (*TableStats, error) {
+	st, err := tx.tx.StatDBI(dbi)
+	if err != nil {
+		return nil, fmt.Errorf("table: %s, %w", name, err)
+	}
+	res := &TableStats{
+		Name:          name,
+		DupSort:       dupSort,
+		Entries:       st.Entries,
+		Depth:         st.Depth,
+		BranchPages:   st.BranchPages,
+		LeafPages:     st.LeafPages,
+		OverflowPages: st.OverflowPages,
+		Size:          (st.BranchPages + st.LeafPages + st.OverflowPages) * tx.db.opts.pageSize,
+	}
+	if !scan {
+		return res, nil
+	}
+
+	// raw cursor: keys of AutoDupSortKeysConversion tables are counted as they are stored
+	c, err := tx.tx.OpenCursor(dbi)
+	if err != nil {
+		return nil, fmt.Errorf("table: %s, %w", name, err)
+	}
+	defer c.Close()
+	var prevK []byte
+	var dups uint64
+	for k, v, err := c.Get(nil, nil, mdbx.First); ; k, v, err = c.Get(nil, nil, mdbx.Next) {
+		if err != nil {
+			if mdbx.IsNotFound(err) {
+				break
+			}
+			return nil, fmt.Errorf("table: %s, %w", name, err)
+		}
+		if prevK == nil || !bytes.Equal(prevK, k) { // value of DupSort table shares key with previous values
+			res.Keys++
+			res.PayloadSize += uint64(len(k))
+			dups = 0
+		}
+		dups++
+		if dups > res.MaxDups {
+			res.MaxDups = dups
+		}
+		res.PayloadSize += uint64(len(v))
+		prevK = k
+	}
+	if !dupSort {
+		res.MaxDups = 0
+	}
+	if res.Size > 0 {
+		res.Utilization = float64(res.PayloadSize) / float64(res.Size)
+	}
+	return res, nil
+}
+
+// freePages - sum of sizes of page lists stored in GC: each value is array of 32-bit page numbers
+// (native byte order) and first element is amount of page numbers
+func (tx *MdbxTx) freePages() (uint64, error) {
+	c, err := tx.tx.OpenCursor(mdbx.DBI(0))
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	var res uint64
+	for _, v, err := c.Get(nil, nil, mdbx.First); ; _, v, err = c.Get(nil, nil, mdbx.Next) {
+		if err != nil {
+			if mdbx.IsNotFound(err) {
+				return res, nil
+			}
+			return 0, err
+		}
+		if len(v) >= 4 {
+			res += uint64(binary.LittleEndian.Uint32(v))
+		}
+	}
+}
@@ -18,9 +18,15 @@ package mdbx
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon-lib/common/background"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/order"
 	"github.com/ledgerwatch/log/v3"
@@ -773,3 +779,254 @@ func TestAutoConversionSeekBothRange(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, v)
 }
+
+func TestBackup(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	const plain, dupSort = "Plain", "DupSort"
+	tables := func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.TableCfg{
+			plain:       kv.TableCfgItem{},
+			dupSort:     kv.TableCfgItem{Flags: kv.DupSort},
+			kv.Sequence: kv.TableCfgItem{},
+		}
+	}
+	db := NewMDBX(logger).Path(t.TempDir()).WithTableCfg(tables).MapSize(128 * datasize.MB).MustOpen()
+	t.Cleanup(db.Close)
+
+	key := func(i uint64) []byte { return binary.BigEndian.AppendUint64(nil, i) }
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		for i := uint64(0); i < 10_000; i++ {
+			if err := tx.Put(dupSort, key(i%100), key(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	// writer: every tx puts same key to both tables and increments sequence - consistent copy has equal amount of them
+	stop, written := make(chan struct{}), make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := uint64(0); ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+				if err := tx.Put(plain, key(i), key(i)); err != nil {
+					return err
+				}
+				if err := tx.Put(dupSort, key(1_000_000), key(i)); err != nil {
+					return err
+				}
+				_, err := tx.IncrementSequence(plain, 1)
+				return err
+			}))
+			select {
+			case written <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	go func() { // reader
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+				_, err := tx.ReadSequence(plain)
+				return err
+			}))
+		}
+	}()
+	<-written
+
+	for _, compact := range []bool{true, false} {
+		dstPath := filepath.Join(t.TempDir(), "backup")
+		progress := &background.Progress{}
+		require.NoError(t, db.(*MdbxKV).Backup(ctx, dstPath, compact, progress))
+		require.Equal(t, progress.Total.Load(), progress.Processed.Load())
+		require.Error(t, db.(*MdbxKV).Backup(ctx, dstPath, compact, nil)) // doesn't overwrite
+
+		backup := NewMDBX(logger).Path(dstPath).WithTableCfg(tables).MapSize(128 * datasize.MB).MustOpen()
+		require.NoError(t, backup.View(ctx, func(tx kv.Tx) error {
+			seq, err := tx.ReadSequence(plain)
+			require.NoError(t, err)
+			require.Greater(t, seq, uint64(0))
+			count := func(table string) uint64 {
+				c, err := tx.Cursor(table)
+				require.NoError(t, err)
+				defer c.Close()
+				n, err := c.Count()
+				require.NoError(t, err)
+				return n
+			}
+			require.Equal(t, seq, count(plain))
+			dupCount := count(dupSort)
+			require.Equal(t, 10_000+seq, dupCount)
+
+			var i uint64
+			require.NoError(t, tx.ForEach(plain, nil, func(k, v []byte) error {
+				require.Equal(t, key(i), k)
+				require.Equal(t, key(i), v)
+				i++
+				return nil
+			}))
+			return nil
+		}))
+		backup.Close()
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// cancelOnProgress - ctx which is cancelled when progress reaches n: copy checks ctx right after progress update
+type cancelOnProgress struct {
+	context.Context
+	cancel   context.CancelFunc
+	progress *background.Progress
+	n        uint64
+}
+
+func (c *cancelOnProgress) Done() <-chan struct{} {
+	if c.progress.Processed.Load() >= c.n {
+		c.cancel()
+	}
+	return c.Context.Done()
+}
+
+func TestBackupCancel(t *testing.T) {
+	logger := log.New()
+	const plain, dupSort = "Plain", "DupSort"
+	tables := func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.TableCfg{
+			plain:   kv.TableCfgItem{},
+			dupSort: kv.TableCfgItem{Flags: kv.DupSort},
+		}
+	}
+	db := NewMDBX(logger).Path(t.TempDir()).WithTableCfg(tables).MapSize(128 * datasize.MB).MustOpen()
+	t.Cleanup(db.Close)
+
+	key := func(i uint64) []byte { return binary.BigEndian.AppendUint64(nil, i) }
+	require.NoError(t, db.Update(context.Background(), func(tx kv.RwTx) error {
+		for i := uint64(0); i < 20_000; i++ {
+			if err := tx.Put(dupSort, key(i%100), key(i)); err != nil {
+				return err
+			}
+			if err := tx.Put(plain, key(i), key(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	// cancelled while second table is copied - first one is already committed to copy
+	dir := t.TempDir()
+	dstPath := filepath.Join(dir, "backup")
+	progress := &background.Progress{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := db.(*MdbxKV).Backup(&cancelOnProgress{Context: ctx, cancel: cancel, progress: progress, n: 28_000}, dstPath, true, progress)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, progress.Processed.Load(), progress.Total.Load())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries) // no partial copy at dstPath and no temporary dir
+
+	require.NoError(t, db.(*MdbxKV).Backup(context.Background(), dstPath, true, progress))
+	require.Equal(t, progress.Total.Load(), progress.Processed.Load())
+	backup := NewMDBX(logger).Path(dstPath).WithTableCfg(tables).MapSize(128 * datasize.MB).MustOpen()
+	defer backup.Close()
+	require.NoError(t, backup.View(context.Background(), func(tx kv.Tx) error {
+		c, err := tx.Cursor(plain)
+		require.NoError(t, err)
+		defer c.Close()
+		n, err := c.Count()
+		require.NoError(t, err)
+		require.Equal(t, uint64(20_000), n)
+		return nil
+	}))
+}
+
+func TestStats(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	const plain, dupSort = "Plain", "DupSort"
+	db := NewMDBX(logger).InMem(t.TempDir()).WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.TableCfg{
+			plain:       kv.TableCfgItem{},
+			dupSort:     kv.TableCfgItem{Flags: kv.DupSort},
+			kv.Sequence: kv.TableCfgItem{},
+		}
+	}).MapSize(128 * datasize.MB).MustOpen()
+	t.Cleanup(db.Close)
+
+	key := func(i uint64) []byte { return binary.BigEndian.AppendUint64(nil, i) }
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		for i := uint64(0); i < 10_000; i++ {
+			if err := tx.Put(plain, key(i), make([]byte, 100)); err != nil {
+				return err
+			}
+			if err := tx.Put(dupSort, key(i%10), key(i)); err != nil {
+				return err
+			}
+		}
+		return tx.Put(plain, key(1_000_000), make([]byte, 10*db.(*MdbxKV).PageSize())) // overflow pages
+	}))
+	for i := 0; i < 3; i++ { // produce some free pages
+		require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+			return tx.ClearBucket(plain)
+		}))
+		require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+			for i := uint64(0); i < 5_000; i++ {
+				if err := tx.Put(plain, key(i), make([]byte, 100)); err != nil {
+					return err
+				}
+			}
+			return tx.Put(plain, key(1_000_000), make([]byte, 10*db.(*MdbxKV).PageSize()))
+		}))
+	}
+
+	stats, err := db.(*MdbxKV).Stats(ctx, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(stats.Tables))
+	require.Equal(t, dupSort, stats.Tables[0].Name)
+	require.True(t, stats.Tables[0].DupSort)
+	require.Equal(t, uint64(10_000), stats.Tables[0].Entries)
+	require.Zero(t, stats.Tables[0].Keys)
+	p := stats.Tables[1]
+	require.Equal(t, plain, p.Name)
+	require.Equal(t, uint64(5_001), p.Entries)
+	require.Greater(t, p.LeafPages, uint64(0))
+	require.Greater(t, p.BranchPages, uint64(0))
+	require.Greater(t, p.OverflowPages, uint64(0))
+	require.Equal(t, (p.LeafPages+p.BranchPages+p.OverflowPages)*stats.PageSize, p.Size)
+	require.Greater(t, stats.FreePages, uint64(0))
+	require.LessOrEqual(t, stats.UsedSize, stats.FileSize)
+	require.LessOrEqual(t, stats.FreeSize, stats.UsedSize)
+
+	stats, err = db.(*MdbxKV).Stats(ctx, true)
+	require.NoError(t, err)
+	d := stats.Tables[0]
+	require.Equal(t, uint64(10), d.Keys)
+	require.Equal(t, uint64(1_000), d.MaxDups)
+	require.Equal(t, uint64(10*8+10_000*8), d.PayloadSize)
+	p = stats.Tables[1]
+	require.Equal(t, uint64(5_001), p.Keys)
+	require.Zero(t, p.MaxDups)
+	require.Equal(t, 5_001*8+5_000*100+10*stats.PageSize, p.PayloadSize)
+	require.Greater(t, p.Utilization, 0.3)
+	require.LessOrEqual(t, p.Utilization, 1.0)
+
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	var decoded DBStats
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, *stats, decoded)
+	require.Contains(t, string(data), `"name":"Plain"`)
+}
@@ -32,6 +32,9 @@ type MemoryMutation struct {
 	clearedTables    map[string]struct{}
 	db               kv.Tx
 	statelessCursors map[string]kv.RwCursor
+
+	undoLog    []undoEntry // see PushSavepoint
+	savepoints []int       // index in undoLog of first change after savepoint
 }
 
 // NewMemoryBatch - starts in-mem batch
@@ -101,6 +104,9 @@ func initSequences(db kv.Tx, memTx kv.RwTx) error {
 }
 
 func (m *MemoryMutation) IncrementSequence(bucket string, amount uint64) (uint64, error) {
+	if err := m.saveKeyUndo(kv.Sequence, []byte(bucket)); err != nil {
+		return 0, err
+	}
 	return m.memTx.IncrementSequence(bucket, amount)
 }
 
@@ -174,10 +180,16 @@ func (m *MemoryMutation) Has(table string, key []byte) (bool, error) {
 }
 
 func (m *MemoryMutation) Put(table string, k, v []byte) error {
+	if err := m.saveKeyUndo(table, k); err != nil {
+		return err
+	}
 	return m.memTx.Put(table, k, v)
 }
 
 func (m *MemoryMutation) Append(table string, key []byte, value []byte) error {
+	if err := m.saveKeyUndo(table, key); err != nil {
+		return err
+	}
 	return m.memTx.Append(table, key, value)
 }
 
@@ -258,6 +270,9 @@ func (m *MemoryMutation) ForPrefix(bucket string, prefix []byte, walker func(k,
 }
 
 func (m *MemoryMutation) Delete(table string, k []byte) error {
+	if err := m.saveKeyUndo(table, k); err != nil {
+		return err
+	}
 	if _, ok := m.deletedEntries[table]; !ok {
 		m.deletedEntries[table] = make(map[string]struct{})
 	}
@@ -297,6 +312,9 @@ func (m *MemoryMutation) ListBuckets() ([]string, error) {
 }
 
 func (m *MemoryMutation) ClearBucket(bucket string) error {
+	if err := m.saveTableUndo(bucket); err != nil {
+		return err
+	}
 	m.clearedTables[bucket] = struct{}{}
 	return m.memTx.ClearBucket(bucket)
 }
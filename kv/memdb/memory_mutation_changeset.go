@@ -0,0 +1,179 @@
+/*
+   Copyright 2023 Erigon contributors
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+       http://www.apache.org/licenses/LICENSE-2.0
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package memdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+type ChangeKind byte
+
+const (
+	ChangeClearTable ChangeKind = iota + 1
+	ChangeDelete
+	ChangePut
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeClearTable:
+		return "clear"
+	case ChangeDelete:
+		return "delete"
+	case ChangePut:
+		return "put"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(k))
+	}
+}
+
+type Change struct {
+	Kind  ChangeKind
+	Table string
+	Key   []byte // nil for ChangeClearTable
+	Value []byte // nil for ChangeClearTable and ChangeDelete
+}
+
+// Changeset - all changes accumulated by MemoryMutation, in order they must be applied:
+// cleared tables, then deleted keys, then put entries. Within each group - ordered by table and key
+// (entries of DupSort tables - also by value), so same mutation always produces same changeset.
+type Changeset []Change
+
+// Changeset - exports changes of mutation, result doesn't reference memory of mutation.
+// Applying it to the tx which mutation overlays gives same result as Flush.
+func (m *MemoryMutation) Changeset() (Changeset, error) {
+	var cs Changeset
+	for _, table := range sortedKeys(m.clearedTables) {
+		cs = append(cs, Change{Kind: ChangeClearTable, Table: table})
+	}
+	for _, table := range sortedKeys(m.deletedEntries) {
+		for _, key := range sortedKeys(m.deletedEntries[table]) {
+			cs = append(cs, Change{Kind: ChangeDelete, Table: table, Key: []byte(key)})
+		}
+	}
+	tables, err := m.memTx.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if err := m.memTx.ForEach(table, nil, func(k, v []byte) error {
+			cs = append(cs, Change{Kind: ChangePut, Table: table, Key: common.Copy(k), Value: common.Copy(v)})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+// Apply - replays changeset on given tx
+func (cs Changeset) Apply(tx kv.RwTx) error {
+	for _, c := range cs {
+		switch c.Kind {
+		case ChangeClearTable:
+			if err := tx.ClearBucket(c.Table); err != nil {
+				return err
+			}
+		case ChangeDelete:
+			if err := tx.Delete(c.Table, c.Key); err != nil {
+				return err
+			}
+		case ChangePut:
+			if err := tx.Put(c.Table, c.Key, c.Value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("apply changeset: unexpected change kind %s", c.Kind)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary - each change is encoded as: kind(1), uvarint(len(table)), table, uvarint(len(key)), key,
+// uvarint(len(value)), value
+func (cs Changeset) MarshalBinary() ([]byte, error) {
+	var buf []byte
+	var numBuf [binary.MaxVarintLen64]byte
+	appendBytes := func(b []byte) {
+		n := binary.PutUvarint(numBuf[:], uint64(len(b)))
+		buf = append(buf, numBuf[:n]...)
+		buf = append(buf, b...)
+	}
+	for _, c := range cs {
+		buf = append(buf, byte(c.Kind))
+		appendBytes([]byte(c.Table))
+		appendBytes(c.Key)
+		appendBytes(c.Value)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary - keys and values of decoded changes reference data
+func (cs *Changeset) UnmarshalBinary(data []byte) error {
+	readBytes := func() ([]byte, error) {
+		l, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("unmarshal changeset: bad length")
+		}
+		data = data[n:]
+		if uint64(len(data)) < l {
+			return nil, fmt.Errorf("unmarshal changeset: expected %d bytes, got %d", l, len(data))
+		}
+		b := data[:l:l]
+		data = data[l:]
+		return b, nil
+	}
+	*cs = (*cs)[:0]
+	for len(data) > 0 {
+		c := Change{Kind: ChangeKind(data[0])}
+		if c.Kind < ChangeClearTable || c.Kind > ChangePut {
+			return fmt.Errorf("unmarshal changeset: unexpected change kind %s", c.Kind)
+		}
+		data = data[1:]
+		table, err := readBytes()
+		if err != nil {
+			return err
+		}
+		c.Table = string(table)
+		if c.Key, err = readBytes(); err != nil {
+			return err
+		}
+		if c.Value, err = readBytes(); err != nil {
+			return err
+		}
+		if c.Kind != ChangePut {
+			c.Value = nil
+			if c.Kind == ChangeClearTable {
+				c.Key = nil
+			}
+		}
+		*cs = append(*cs, c)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
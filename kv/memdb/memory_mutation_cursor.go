@@ -325,6 +325,9 @@ func (m *memoryMutationCursor) Append(k []byte, v []byte) error {
 }
 
 func (m *memoryMutationCursor) AppendDup(k []byte, v []byte) error {
+	if err := m.mutation.saveKeyUndo(m.table, k); err != nil {
+		return err
+	}
 	return m.memCursor.AppendDup(common.Copy(k), common.Copy(v))
 }
 
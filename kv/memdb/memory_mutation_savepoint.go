@@ -0,0 +1,172 @@
+/*
+   Copyright 2023 Erigon contributors
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+       http://www.apache.org/licenses/LICENSE-2.0
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package memdb
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// Savepoints - nested marks of MemoryMutation state, which allow to undo part of changes
+// (for example: speculative execution of transaction, which may be discarded).
+//
+// While at least 1 savepoint exists - every change of mutation records "undo" entry: previous state of
+// changed key (or whole table - for ClearBucket). RollbackToSavepoint applies undo entries in reverse order.
+// Without savepoints mutation has no overhead.
+//
+// Pattern:
+//
+//	sp := batch.PushSavepoint()
+//	if err := speculativeExecution(batch); err != nil {
+//		return batch.RollbackToSavepoint(sp) // savepoint is released too
+//	}
+//	return batch.ReleaseSavepoint(sp)
+
+// undoEntry - restores state of mutation as it was before one change
+type undoEntry interface {
+	undo(m *MemoryMutation) error
+}
+
+// keyUndo - values of key in mutation (all duplicates for DupSort tables) and deletion mark of key
+type keyUndo struct {
+	table   string
+	key     []byte
+	values  [][]byte
+	deleted bool
+}
+
+func (u *keyUndo) undo(m *MemoryMutation) error {
+	if err := m.memTx.Delete(u.table, u.key); err != nil {
+		return err
+	}
+	for _, v := range u.values {
+		if err := m.memTx.Put(u.table, u.key, v); err != nil {
+			return err
+		}
+	}
+	if !u.deleted {
+		delete(m.deletedEntries[u.table], string(u.key))
+	}
+	return nil
+}
+
+// tableUndo - all entries of table in mutation and clear mark of table
+type tableUndo struct {
+	table   string
+	entries [][2][]byte
+	cleared bool
+}
+
+func (u *tableUndo) undo(m *MemoryMutation) error {
+	if err := m.memTx.ClearBucket(u.table); err != nil {
+		return err
+	}
+	for _, e := range u.entries {
+		if err := m.memTx.Put(u.table, e[0], e[1]); err != nil {
+			return err
+		}
+	}
+	if !u.cleared {
+		delete(m.clearedTables, u.table)
+	}
+	return nil
+}
+
+// PushSavepoint - marks current state of mutation, returns id of savepoint. Savepoints can be nested.
+func (m *MemoryMutation) PushSavepoint() int {
+	m.savepoints = append(m.savepoints, len(m.undoLog))
+	return len(m.savepoints) - 1
+}
+
+// Savepoints - amount of active savepoints
+func (m *MemoryMutation) Savepoints() int { return len(m.savepoints) }
+
+// RollbackToSavepoint - discards all changes made after savepoint `id` was pushed. Releases this savepoint and all nested ones.
+func (m *MemoryMutation) RollbackToSavepoint(id int) error {
+	if id < 0 || id >= len(m.savepoints) {
+		return fmt.Errorf("rollback to savepoint %d: only %d savepoints are active", id, len(m.savepoints))
+	}
+	m.statelessCursors = nil
+	from := m.savepoints[id]
+	for i := len(m.undoLog) - 1; i >= from; i-- {
+		if err := m.undoLog[i].undo(m); err != nil {
+			return fmt.Errorf("rollback to savepoint %d: %w", id, err)
+		}
+		m.undoLog[i] = nil
+	}
+	m.undoLog = m.undoLog[:from]
+	m.savepoints = m.savepoints[:id]
+	return nil
+}
+
+// ReleaseSavepoint - forgets savepoint `id` and all nested ones, changes made after it are kept
+// (and belong to outer savepoint - if any).
+func (m *MemoryMutation) ReleaseSavepoint(id int) error {
+	if id < 0 || id >= len(m.savepoints) {
+		return fmt.Errorf("release savepoint %d: only %d savepoints are active", id, len(m.savepoints))
+	}
+	m.savepoints = m.savepoints[:id]
+	if id == 0 { // no one can rollback these changes anymore
+		m.undoLog = nil
+	}
+	return nil
+}
+
+// saveKeyUndo - must be called before any change of key in memTx
+func (m *MemoryMutation) saveKeyUndo(table string, key []byte) error {
+	if len(m.savepoints) == 0 {
+		return nil
+	}
+	u := &keyUndo{table: table, key: common.Copy(key), deleted: m.isEntryDeleted(table, key)}
+	if isTablePurelyDupsort(table) {
+		c, err := m.memTx.CursorDupSort(table)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		for k, v, err := c.SeekExact(key); k != nil; k, v, err = c.NextDup() {
+			if err != nil {
+				return err
+			}
+			u.values = append(u.values, common.Copy(v))
+		}
+	} else {
+		v, err := m.memTx.GetOne(table, key)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			u.values = append(u.values, common.Copy(v))
+		}
+	}
+	m.undoLog = append(m.undoLog, u)
+	return nil
+}
+
+// saveTableUndo - must be called before ClearBucket
+func (m *MemoryMutation) saveTableUndo(table string) error {
+	if len(m.savepoints) == 0 {
+		return nil
+	}
+	u := &tableUndo{table: table, cleared: m.isTableCleared(table)}
+	if err := m.memTx.ForEach(table, nil, func(k, v []byte) error {
+		u.entries = append(u.entries, [2][]byte{common.Copy(k), common.Copy(v)})
+		return nil
+	}); err != nil {
+		return err
+	}
+	m.undoLog = append(m.undoLog, u)
+	return nil
+}
@@ -664,3 +664,209 @@ func TestAutoConversionSeekBothRange(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, v)
 }
+
+func TestSavepointRollback(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+
+	initializeDbNonDupSort(rwTx)
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+
+	sp := batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4.1")))
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BBAA"), []byte("value5")))
+	require.NoError(t, batch.Delete(kv.HashedAccounts, []byte("CAAA")))
+	_, err := batch.IncrementSequence(kv.HashedAccounts, 5)
+	require.NoError(t, err)
+
+	inner := batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("AAAA"), []byte("value1.1")))
+	require.NoError(t, batch.RollbackToSavepoint(inner))
+	require.Equal(t, 1, batch.Savepoints())
+
+	val, err := batch.GetOne(kv.HashedAccounts, []byte("AAAA"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), val)
+	val, err = batch.GetOne(kv.HashedAccounts, []byte("BAAA"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value4.1"), val)
+
+	require.NoError(t, batch.RollbackToSavepoint(sp))
+	require.Equal(t, 0, batch.Savepoints())
+	require.Error(t, batch.RollbackToSavepoint(sp))
+
+	val, err = batch.GetOne(kv.HashedAccounts, []byte("BAAA"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value4"), val)
+	exist, err := batch.Has(kv.HashedAccounts, []byte("BBAA"))
+	require.NoError(t, err)
+	require.False(t, exist)
+	val, err = batch.GetOne(kv.HashedAccounts, []byte("CAAA"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), val)
+	seq, err := batch.ReadSequence(kv.HashedAccounts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), seq)
+
+	require.NoError(t, batch.Flush(rwTx))
+	var keys []string
+	require.NoError(t, rwTx.ForEach(kv.HashedAccounts, nil, func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}))
+	require.Equal(t, []string{"AAAA", "BAAA", "CAAA", "CBAA", "CCAA"}, keys)
+}
+
+func TestSavepointRelease(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+
+	initializeDbNonDupSort(rwTx)
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	outer := batch.PushSavepoint()
+	inner := batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+	require.NoError(t, batch.ReleaseSavepoint(inner))
+	require.Equal(t, 1, batch.Savepoints())
+
+	// released changes belong to outer savepoint
+	require.NoError(t, batch.RollbackToSavepoint(outer))
+	exist, err := batch.Has(kv.HashedAccounts, []byte("BAAA"))
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	outer = batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+	require.NoError(t, batch.ReleaseSavepoint(outer))
+	require.Nil(t, batch.undoLog)
+	val, err := batch.GetOne(kv.HashedAccounts, []byte("BAAA"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value4"), val)
+}
+
+func TestSavepointClearBucket(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+
+	initializeDbNonDupSort(rwTx)
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+	sp := batch.PushSavepoint()
+	require.NoError(t, batch.ClearBucket(kv.HashedAccounts))
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("DAAA"), []byte("value6")))
+	exist, err := batch.Has(kv.HashedAccounts, []byte("AAAA"))
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	require.NoError(t, batch.RollbackToSavepoint(sp))
+	var keys []string
+	require.NoError(t, batch.ForEach(kv.HashedAccounts, nil, func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}))
+	require.Equal(t, []string{"AAAA", "BAAA", "CAAA", "CBAA", "CCAA"}, keys)
+}
+
+func TestSavepointDupSort(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+
+	initializeDbDupSort(rwTx)
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	require.NoError(t, batch.Put(kv.AccountChangeSet, []byte("key1"), []byte("value1.2")))
+	sp := batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.AccountChangeSet, []byte("key1"), []byte("value1.4")))
+	require.NoError(t, batch.Put(kv.AccountChangeSet, []byte("key2"), []byte("value2.1")))
+	require.NoError(t, batch.RollbackToSavepoint(sp))
+
+	var keys, values []string
+	require.NoError(t, batch.ForEach(kv.AccountChangeSet, nil, func(k, v []byte) error {
+		keys = append(keys, string(k))
+		values = append(values, string(v))
+		return nil
+	}))
+	require.Equal(t, []string{"key1", "key1", "key1", "key3", "key3"}, keys)
+	require.Equal(t, []string{"value1.1", "value1.2", "value1.3", "value3.1", "value3.3"}, values)
+}
+
+func TestSavepointAutoConversion(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+
+	initializeDbAutoConversion(rwTx)
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	sp := batch.PushSavepoint()
+	require.NoError(t, batch.Put(kv.PlainState, []byte("A..........................._______________________________A"), []byte("X")))
+	require.NoError(t, batch.Delete(kv.PlainState, []byte("D..........................._______________________________C")))
+	require.NoError(t, batch.RollbackToSavepoint(sp))
+
+	val, err := batch.GetOne(kv.PlainState, []byte("A..........................._______________________________A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), val)
+	val, err = batch.GetOne(kv.PlainState, []byte("D..........................._______________________________C"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("4"), val)
+}
+
+func TestChangeset(t *testing.T) {
+	_, rwTx := NewTestTx(t)
+	_, rwTx2 := NewTestTx(t)
+
+	initializeDbNonDupSort(rwTx)
+	initializeDbNonDupSort(rwTx2)
+	initializeDbDupSort(rwTx)
+	initializeDbDupSort(rwTx2)
+	require.NoError(t, rwTx.Put(kv.HashedStorage, []byte("AAAA"), []byte("value")))
+	require.NoError(t, rwTx2.Put(kv.HashedStorage, []byte("AAAA"), []byte("value")))
+
+	batch := NewMemoryBatch(rwTx, "")
+	defer batch.Close()
+
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("AAAA"), []byte("value1.1")))
+	require.NoError(t, batch.Delete(kv.HashedAccounts, []byte("CAAA")))
+	require.NoError(t, batch.Put(kv.AccountChangeSet, []byte("key2"), []byte("value2.1")))
+	require.NoError(t, batch.ClearBucket(kv.HashedStorage))
+	require.NoError(t, batch.Put(kv.HashedStorage, []byte("BBBB"), []byte("value")))
+
+	cs, err := batch.Changeset()
+	require.NoError(t, err)
+	require.Equal(t, Change{Kind: ChangeClearTable, Table: kv.HashedStorage}, cs[0])
+	require.Equal(t, Change{Kind: ChangeDelete, Table: kv.HashedAccounts, Key: []byte("CAAA")}, cs[1])
+
+	// over the wire
+	data, err := cs.MarshalBinary()
+	require.NoError(t, err)
+	var decoded Changeset
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, len(cs), len(decoded))
+	require.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+	require.NoError(t, decoded.UnmarshalBinary(data))
+
+	require.NoError(t, batch.Flush(rwTx))
+	require.NoError(t, decoded.Apply(rwTx2))
+
+	for _, table := range []string{kv.HashedAccounts, kv.AccountChangeSet, kv.HashedStorage} {
+		var expected, got []string
+		require.NoError(t, rwTx.ForEach(table, nil, func(k, v []byte) error {
+			expected = append(expected, string(k)+"="+string(v))
+			return nil
+		}))
+		require.NoError(t, rwTx2.ForEach(table, nil, func(k, v []byte) error {
+			got = append(got, string(k)+"="+string(v))
+			return nil
+		}))
+		require.Equal(t, expected, got, table)
+	}
+}
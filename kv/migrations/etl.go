@@ -0,0 +1,110 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/etl"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// progress of TransformTable
+const etlLoading = "etl_loading" // extraction finished, files of collector are in tmpdir
+
+// TransformTable - resumable ETL migration: entries of fromTable are passed to extract, collected entries are loaded
+// into toTable in one tx (if toTable == fromTable - table is cleared before load, in same tx).
+//
+// Extraction is done once: collected data is flushed to tmpdir and progress is saved. If process is interrupted
+// during loading - next run loads files left by previous run (tx of loading wasn't committed, so it's safe to repeat it).
+// If process is interrupted during extraction (or loading failed by error - then files are removed) - extraction
+// starts from beginning.
+func TransformTable(ctx context.Context, name string, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback,
+	fromTable, toTable string, extract etl.ExtractFunc, logger log.Logger) error {
+	var collector *etl.Collector
+	if string(progress) == etlLoading {
+		var err error
+		if collector, err = etl.NewCollectorFromFiles(name, tmpdir); err != nil {
+			return err
+		}
+		if collector != nil {
+			logger.Info(fmt.Sprintf("[%s] loading files of previous run", name), "dir", tmpdir)
+		}
+	}
+	if collector == nil {
+		var err error
+		if collector, err = extractTable(ctx, name, db, tmpdir, fromTable, extract); err != nil {
+			return err
+		}
+		if err = db.Update(ctx, func(tx kv.RwTx) error {
+			return beforeCommit(tx, []byte(etlLoading), false)
+		}); err != nil {
+			collector.Close()
+			return err
+		}
+	}
+	defer collector.Close()
+
+	return db.Update(ctx, func(tx kv.RwTx) error {
+		if fromTable == toTable {
+			if err := tx.ClearBucket(toTable); err != nil {
+				return err
+			}
+		}
+		if err := collector.Load(tx, toTable, etl.IdentityLoadFunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
+			return err
+		}
+		return beforeCommit(tx, nil, true)
+	})
+}
+
+// extractTable - collected data is flushed to tmpdir by critical collector: files survive kill of process
+func extractTable(ctx context.Context, name string, db kv.RoDB, tmpdir, fromTable string, extract etl.ExtractFunc) (*etl.Collector, error) {
+	if err := os.RemoveAll(tmpdir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tmpdir, 0o755); err != nil {
+		return nil, err
+	}
+	collector := etl.NewCriticalCollector(name, tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	next := func(_, k, v []byte) error { return collector.Collect(k, v) }
+	var i int
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		return tx.ForEach(fromTable, nil, func(k, v []byte) error {
+			if i++; i%4096 == 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+				}
+			}
+			return extract(k, v, next)
+		})
+	}); err != nil {
+		collector.Close()
+		return nil, err
+	}
+	if err := collector.Flush(); err != nil {
+		collector.Close()
+		return nil, err
+	}
+	return collector, nil
+}
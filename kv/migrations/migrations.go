@@ -0,0 +1,245 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package migrations
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// Migrations - ordered data migrations of db, for changes of tables layout which can't be done by TableCfg.
+//
+// Applied migrations are recorded in kv.Migrations table: key - name of migration, value - time of apply (unix seconds).
+// Migration may be interrupted (by error or by kill of process) - it saves progress by Callback, and next run of Apply
+// continues it from saved progress (progress is stored in kv.Migrations table under key "_progress_" + name).
+//
+// Schema version of binary (kv.DBSchemaVersion) is written to kv.DatabaseInfo after all migrations are applied.
+// Db with newer schema version is not opened by older binary: it can't know what newer migrations did.
+
+// Callback - must be called by migration before every commit, in same tx: progress is saved atomically with data.
+// isDone=true - migration finished: it's recorded as applied (and progress is deleted).
+type Callback func(tx kv.RwTx, progress []byte, isDone bool) error
+
+// Migration - Name is recorded in db, so it must never change.
+type Migration struct {
+	Name string
+	// Up - does migration. progress - saved by previous interrupted run (nil on first run).
+	// tmpdir - own directory of migration, not removed between runs (see TransformTable).
+	Up func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error
+}
+
+var ErrSchemaTooNew = errors.New("db schema version is newer than supported by binary")
+
+const progressKeyPrefix = "_progress_"
+
+type Migrator struct {
+	migrations []Migration
+	version    *types.VersionReply
+	dryRun     bool
+}
+
+func NewMigrator(migrations ...Migration) *Migrator {
+	return &Migrator{migrations: migrations, version: &kv.DBSchemaVersion}
+}
+
+// DryRun - Apply only checks schema version and logs which migrations will be applied, doesn't modify db
+func (m *Migrator) DryRun(v bool) { m.dryRun = v }
+
+// Pending - names of not applied migrations, in order of apply
+func (m *Migrator) Pending(ctx context.Context, db kv.RoDB) (pending []string, err error) {
+	if err = m.validate(); err != nil {
+		return nil, err
+	}
+	err = db.View(ctx, func(tx kv.Tx) error {
+		for _, mig := range m.migrations {
+			applied, err := tx.Has(kv.Migrations, []byte(mig.Name))
+			if err != nil {
+				return err
+			}
+			if !applied {
+				pending = append(pending, mig.Name)
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// Apply - applies pending migrations in order. tmpdir - parent of own directories of migrations.
+func (m *Migrator) Apply(ctx context.Context, db kv.RwDB, tmpdir string, logger log.Logger) error {
+	if err := db.View(ctx, func(tx kv.Tx) error { return CheckSchemaVersion(tx, m.version) }); err != nil {
+		return err
+	}
+	pending, err := m.Pending(ctx, db)
+	if err != nil {
+		return err
+	}
+	if m.dryRun {
+		logger.Info("[migrations] dry run", "pending", pending)
+		return nil
+	}
+
+	for _, mig := range m.migrations {
+		if !contains(pending, mig.Name) {
+			continue
+		}
+		var progress []byte
+		if err = db.View(ctx, func(tx kv.Tx) error {
+			v, err := tx.GetOne(kv.Migrations, []byte(progressKeyPrefix+mig.Name))
+			progress = common.Copy(v)
+			return err
+		}); err != nil {
+			return err
+		}
+		if progress != nil {
+			logger.Info("[migrations] continue", "name", mig.Name)
+		} else {
+			logger.Info("[migrations] apply", "name", mig.Name)
+		}
+
+		dir := filepath.Join(tmpdir, mig.Name)
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err = mig.Up(ctx, db, dir, progress, beforeCommit(mig.Name), logger); err != nil {
+			return fmt.Errorf("migration %s: %w", mig.Name, err)
+		}
+		var applied bool
+		if err = db.View(ctx, func(tx kv.Tx) (err error) {
+			applied, err = tx.Has(kv.Migrations, []byte(mig.Name))
+			return err
+		}); err != nil {
+			return err
+		}
+		if !applied {
+			return fmt.Errorf("migration %s: finished without beforeCommit(isDone=true)", mig.Name)
+		}
+		if err = os.RemoveAll(dir); err != nil {
+			return err
+		}
+		logger.Info("[migrations] applied", "name", mig.Name)
+	}
+
+	return db.Update(ctx, func(tx kv.RwTx) error {
+		v, err := ReadSchemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		if v != nil && !versionLess(v, m.version) {
+			return nil
+		}
+		return WriteSchemaVersion(tx, m.version)
+	})
+}
+
+func (m *Migrator) validate() error {
+	seen := map[string]struct{}{}
+	for _, mig := range m.migrations {
+		if mig.Name == "" || mig.Up == nil {
+			return fmt.Errorf("migration %q: no name or Up func", mig.Name)
+		}
+		if _, ok := seen[mig.Name]; ok {
+			return fmt.Errorf("migration %s: duplicated name", mig.Name)
+		}
+		seen[mig.Name] = struct{}{}
+	}
+	return nil
+}
+
+func beforeCommit(name string) Callback {
+	return func(tx kv.RwTx, progress []byte, isDone bool) error {
+		if !isDone {
+			return tx.Put(kv.Migrations, []byte(progressKeyPrefix+name), progress)
+		}
+		if err := tx.Delete(kv.Migrations, []byte(progressKeyPrefix+name)); err != nil {
+			return err
+		}
+		var applied [8]byte
+		binary.BigEndian.PutUint64(applied[:], uint64(time.Now().Unix()))
+		return tx.Put(kv.Migrations, []byte(name), applied[:])
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadSchemaVersion - nil if db has no version yet (new db)
+func ReadSchemaVersion(tx kv.Tx) (*types.VersionReply, error) {
+	enc, err := tx.GetOne(kv.DatabaseInfo, kv.DBSchemaVersionKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	if len(enc) != 12 {
+		return nil, fmt.Errorf("db schema version: expected 12 bytes, got %d", len(enc))
+	}
+	return &types.VersionReply{
+		Major: binary.BigEndian.Uint32(enc),
+		Minor: binary.BigEndian.Uint32(enc[4:]),
+		Patch: binary.BigEndian.Uint32(enc[8:]),
+	}, nil
+}
+
+func WriteSchemaVersion(tx kv.RwTx, v *types.VersionReply) error {
+	var enc [12]byte
+	binary.BigEndian.PutUint32(enc[:], v.Major)
+	binary.BigEndian.PutUint32(enc[4:], v.Minor)
+	binary.BigEndian.PutUint32(enc[8:], v.Patch)
+	return tx.Put(kv.DatabaseInfo, kv.DBSchemaVersionKey, enc[:])
+}
+
+// CheckSchemaVersion - returns ErrSchemaTooNew if schema version of db (major, minor) is newer than binary one
+func CheckSchemaVersion(tx kv.Tx, binaryVersion *types.VersionReply) error {
+	v, err := ReadSchemaVersion(tx)
+	if err != nil || v == nil {
+		return err
+	}
+	if versionLess(binaryVersion, &types.VersionReply{Major: v.Major, Minor: v.Minor}) {
+		return fmt.Errorf("%w: db %d.%d.%d, binary %d.%d.%d", ErrSchemaTooNew, v.Major, v.Minor, v.Patch,
+			binaryVersion.Major, binaryVersion.Minor, binaryVersion.Patch)
+	}
+	return nil
+}
+
+func versionLess(a, b *types.VersionReply) bool {
+	if a.Major != b.Major {
+		return a.Major < b.Major
+	}
+	if a.Minor != b.Minor {
+		return a.Minor < b.Minor
+	}
+	return a.Patch < b.Patch
+}
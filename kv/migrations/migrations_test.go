@@ -0,0 +1,230 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/etl"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+)
+
+func putMigration(name string, k, v string) Migration {
+	return Migration{Name: name, Up: func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error {
+		return db.Update(ctx, func(tx kv.RwTx) error {
+			if err := tx.Put(kv.DatabaseInfo, []byte(k), []byte(v)); err != nil {
+				return err
+			}
+			return beforeCommit(tx, nil, true)
+		})
+	}}
+}
+
+func TestApply(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	db := memdb.NewTestDB(t)
+	m := NewMigrator(putMigration("m1", "k", "1"), putMigration("m2", "k", "2"))
+
+	pending, err := m.Pending(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"m1", "m2"}, pending)
+
+	m.DryRun(true)
+	require.NoError(t, m.Apply(ctx, db, t.TempDir(), logger))
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.DatabaseInfo, []byte("k"))
+		require.NoError(t, err)
+		require.Nil(t, v)
+		version, err := ReadSchemaVersion(tx)
+		require.Nil(t, version)
+		return err
+	}))
+
+	m.DryRun(false)
+	require.NoError(t, m.Apply(ctx, db, t.TempDir(), logger))
+	pending, err = m.Pending(ctx, db)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.DatabaseInfo, []byte("k"))
+		require.NoError(t, err)
+		require.Equal(t, "2", string(v)) // applied in order
+		version, err := ReadSchemaVersion(tx)
+		require.NoError(t, err)
+		require.Equal(t, kv.DBSchemaVersion.Major, version.Major)
+		require.Equal(t, kv.DBSchemaVersion.Minor, version.Minor)
+		return err
+	}))
+
+	// applied migrations are not applied again, new ones are
+	m = NewMigrator(putMigration("m1", "k", "1"), putMigration("m2", "k", "2"), putMigration("m3", "k3", "3"))
+	require.NoError(t, m.Apply(ctx, db, t.TempDir(), logger))
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.DatabaseInfo, []byte("k"))
+		require.NoError(t, err)
+		require.Equal(t, "2", string(v))
+		v, err = tx.GetOne(kv.DatabaseInfo, []byte("k3"))
+		require.Equal(t, "3", string(v))
+		return err
+	}))
+
+	require.ErrorContains(t, NewMigrator(putMigration("m1", "k", "1"), putMigration("m1", "k", "1")).Apply(ctx, db, t.TempDir(), logger), "duplicated")
+	notDone := Migration{Name: "m4", Up: func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error {
+		return nil
+	}}
+	require.ErrorContains(t, NewMigrator(notDone).Apply(ctx, db, t.TempDir(), logger), "without beforeCommit")
+}
+
+func TestResume(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	db := memdb.NewTestDB(t)
+	errInterrupted := errors.New("interrupted")
+	var progresses []string
+	mig := Migration{Name: "batches", Up: func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error {
+		progresses = append(progresses, string(progress))
+		from := 0
+		if progress != nil {
+			from = int(progress[0])
+		}
+		for batch := from; batch < 3; batch++ {
+			if batch == 1 && progress == nil {
+				return errInterrupted
+			}
+			if err := db.Update(ctx, func(tx kv.RwTx) error {
+				if err := tx.Put(kv.DatabaseInfo, []byte(fmt.Sprintf("batch %d", batch)), []byte{1}); err != nil {
+					return err
+				}
+				return beforeCommit(tx, []byte{byte(batch + 1)}, batch == 2)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	m := NewMigrator(mig)
+	require.ErrorIs(t, m.Apply(ctx, db, t.TempDir(), logger), errInterrupted)
+	pending, err := m.Pending(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"batches"}, pending)
+
+	require.NoError(t, m.Apply(ctx, db, t.TempDir(), logger))
+	require.Equal(t, []string{"", "\x01"}, progresses)
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		for batch := 0; batch < 3; batch++ {
+			has, err := tx.Has(kv.DatabaseInfo, []byte(fmt.Sprintf("batch %d", batch)))
+			require.NoError(t, err)
+			require.True(t, has)
+		}
+		has, err := tx.Has(kv.Migrations, []byte(progressKeyPrefix+"batches"))
+		require.False(t, has)
+		return err
+	}))
+}
+
+func TestSchemaTooNew(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	db := memdb.NewTestDB(t)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return WriteSchemaVersion(tx, &types.VersionReply{Major: kv.DBSchemaVersion.Major, Minor: kv.DBSchemaVersion.Minor, Patch: kv.DBSchemaVersion.Patch + 1})
+	}))
+	require.NoError(t, NewMigrator().Apply(ctx, db, t.TempDir(), logger)) // newer patch is compatible
+
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return WriteSchemaVersion(tx, &types.VersionReply{Major: kv.DBSchemaVersion.Major, Minor: kv.DBSchemaVersion.Minor + 1})
+	}))
+	m := NewMigrator(putMigration("m1", "k", "1"))
+	require.ErrorIs(t, m.Apply(ctx, db, t.TempDir(), logger), ErrSchemaTooNew)
+	m.DryRun(true)
+	require.ErrorIs(t, m.Apply(ctx, db, t.TempDir(), logger), ErrSchemaTooNew)
+	pending, err := m.Pending(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"m1"}, pending)
+}
+
+func TestTransformTable(t *testing.T) {
+	ctx, logger := context.Background(), log.New()
+	db := memdb.NewTestDB(t)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		for i := 0; i < 100; i++ {
+			if err := tx.Put(kv.HeaderNumber, []byte(fmt.Sprintf("key %02d", i)), []byte{byte(i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	// rewrites keys of table in-place
+	extract := func(k, v []byte, next etl.ExtractNextFunc) error {
+		return next(k, append([]byte("new "), k...), v)
+	}
+	up := func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error {
+		return TransformTable(ctx, "rename", db, tmpdir, progress, beforeCommit, kv.HeaderNumber, kv.HeaderNumber, extract, logger)
+	}
+
+	// process was killed during loading: extraction finished, files and progress are left
+	tmpdir := t.TempDir()
+	collector, err := extractTable(ctx, "rename", db, filepath.Join(tmpdir, "rename"), kv.HeaderNumber, extract)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return beforeCommit("rename")(tx, []byte(etlLoading), false)
+	}))
+	_ = collector // not closed: files survive
+
+	// next run doesn't extract again
+	m := NewMigrator(Migration{Name: "rename", Up: func(ctx context.Context, db kv.RwDB, tmpdir string, progress []byte, beforeCommit Callback, logger log.Logger) error {
+		require.Equal(t, etlLoading, string(progress))
+		require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error { // table was changed after extraction - it's not visible in result
+			return tx.Put(kv.HeaderNumber, []byte("key 100"), []byte{100})
+		}))
+		return up(ctx, db, tmpdir, progress, beforeCommit, logger)
+	}})
+	require.NoError(t, m.Apply(ctx, db, tmpdir, logger))
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		var i int
+		require.NoError(t, tx.ForEach(kv.HeaderNumber, nil, func(k, v []byte) error {
+			require.Equal(t, fmt.Sprintf("new key %02d", i), string(k))
+			require.Equal(t, []byte{byte(i)}, v)
+			i++
+			return nil
+		}))
+		require.Equal(t, 100, i)
+		return nil
+	}))
+	_, err = os.Stat(filepath.Join(tmpdir, "rename"))
+	require.True(t, os.IsNotExist(err))
+
+	// run from scratch
+	db = memdb.NewTestDB(t)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(kv.HeaderNumber, []byte("key"), []byte{1})
+	}))
+	require.NoError(t, NewMigrator(Migration{Name: "rename", Up: up}).Apply(ctx, db, t.TempDir(), logger))
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.HeaderNumber, []byte("new key"))
+		require.Equal(t, []byte{1}, v)
+		return err
+	}))
+}
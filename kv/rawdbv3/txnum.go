@@ -103,10 +103,7 @@ func (txNums) Append(tx kv.RwTx, blockNum, maxTxNum uint64) (err error) {
 	return nil
 }
 func (txNums) WriteForGenesis(tx kv.RwTx, maxTxNum uint64) (err error) {
-	var k, v [8]byte
-	binary.BigEndian.PutUint64(k[:], 0)
-	binary.BigEndian.PutUint64(v[:], maxTxNum)
-	return tx.Put(kv.MaxTxNum, k[:], v[:])
+	return kv.MaxTxNumTbl.Put(tx, 0, maxTxNum)
 }
 func (txNums) Truncate(tx kv.RwTx, blockNum uint64) (err error) {
 	var seek [8]byte
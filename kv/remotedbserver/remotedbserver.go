@@ -17,6 +17,7 @@
 package remotedbserver
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
@@ -64,13 +65,17 @@ const MaxTxTTL = 60 * time.Second
 // 6.0.0 - Blocks now have system-txs - in the begin/end of block
 // 6.1.0 - Add methods Range, IndexRange, HistoryGet, HistoryRange
 // 6.2.0 - Add HistoryFiles to reply of Snapshots() method
-var KvServiceAPIVersion = &types.VersionReply{Major: 6, Minor: 2, Patch: 0}
+// 6.2.1 - Server-side implementation of DomainRange, HistoryRange
+// 6.3.0 - Add TableDiffs stream
+// 6.4.0 - Added pendingBlobFeePerGas to the StateChangeBatch
+var KvServiceAPIVersion = &types.VersionReply{Major: 6, Minor: 4, Patch: 0}
 
 type KvServer struct {
 	remote.UnimplementedKVServer // must be embedded to have forward compatible implementations.
 
 	kv                 kv.RoDB
 	stateChangeStreams *StateChangePubSub
+	tableDiffStreams   *TableDiffPubSub
 	blockSnapshots     Snapsthots
 	historySnapshots   Snapsthots
 	ctx                context.Context
@@ -87,6 +92,16 @@ type KvServer struct {
 type threadSafeTx struct {
 	kv.Tx
 	sync.Mutex
+
+	// historyPages - iterators of HistoryRange paused after returned page, by NextPageToken of the page.
+	// Next page continues iterator instead of re-scan from beginning. Iterators are closed by tx.Rollback
+	historyPages map[string]*pausedKV
+}
+
+// pausedKV - iterator and pair which it already returned but which wasn't sent to client
+type pausedKV struct {
+	it   iter.KV
+	k, v []byte
 }
 
 type Snapsthots interface {
@@ -97,7 +112,7 @@ func NewKvServer(ctx context.Context, db kv.RoDB, snapshots Snapsthots, historyS
 	return &KvServer{
 		trace:     false,
 		rangeStep: 1024,
-		kv:        db, stateChangeStreams: newStateChangeStreams(), ctx: ctx,
+		kv:        db, stateChangeStreams: newStateChangeStreams(), tableDiffStreams: newTableDiffStreams(), ctx: ctx,
 		blockSnapshots: snapshots, historySnapshots: historySnapshots,
 		txs: map[uint64]*threadSafeTx{}, txsMapLock: &sync.RWMutex{},
 	}
@@ -181,6 +196,11 @@ func (s *KvServer) rollback(id uint64) {
 //	client, portion of data it to client, then read next portion in another `with` call.
 //	It will allow cooperative access to `tx` object
 func (s *KvServer) with(id uint64, f func(kv.Tx) error) error {
+	return s.withTx(id, func(tx *threadSafeTx) error { return f(tx.Tx) })
+}
+
+// withTx - same as `with`, but gives access to state which server keeps for tx
+func (s *KvServer) withTx(id uint64, f func(*threadSafeTx) error) error {
 	s.txsMapLock.RLock()
 	tx, ok := s.txs[id]
 	s.txsMapLock.RUnlock()
@@ -201,7 +221,7 @@ func (s *KvServer) with(id uint64, f func(kv.Tx) error) error {
 			log.Info(fmt.Sprintf("[kv_server] with %d unlock %s\n", id, dbg.Stack()[:2]))
 		}
 	}()
-	return f(tx.Tx)
+	return f(tx)
 }
 
 func (s *KvServer) Tx(stream remote.KV_TxServer) error {
@@ -652,6 +672,130 @@ func (s *KvServer) Range(ctx context.Context, req *remote.RangeReq) (*remote.Pai
 	return reply, nil
 }
 
+func (s *KvServer) HistoryRange(ctx context.Context, req *remote.HistoryRangeReq) (*remote.Pairs, error) {
+	var fromKey []byte
+	limit := int(req.Limit)
+	if req.PageToken != "" {
+		var pagination remote.ParisPagination
+		if err := unmarshalPagination(req.PageToken, &pagination); err != nil {
+			return nil, err
+		}
+		fromKey, limit = pagination.NextKey, int(pagination.Limit)
+	}
+	if req.PageSize <= 0 || req.PageSize > PageSizeLimit {
+		req.PageSize = PageSizeLimit
+	}
+
+	reply := &remote.Pairs{}
+	if err := s.withTx(req.TxId, func(tx *threadSafeTx) error {
+		ttx, ok := tx.Tx.(kv.TemporalTx)
+		if !ok {
+			return fmt.Errorf("server DB doesn't implement kv.Temporal interface")
+		}
+		var it iter.KV
+		if paused, ok := tx.historyPages[req.PageToken]; ok && req.PageToken != "" {
+			delete(tx.historyPages, req.PageToken)
+			reply.Keys = append(reply.Keys, paused.k)
+			reply.Values = append(reply.Values, paused.v)
+			limit--
+			it = paused.it
+		} else {
+			// page of other server's iterator (or token was already used): history iterator is ordered by key,
+			// but can't seek by key - skip keys already sent on previous pages
+			var err error
+			if it, err = ttx.HistoryRange(kv.History(req.Table), int(req.FromTs), int(req.ToTs), order.By(req.OrderAscend), -1); err != nil {
+				return err
+			}
+			if fromKey != nil {
+				it = iter.FilterKV(it, func(k, _ []byte) bool {
+					if req.OrderAscend {
+						return bytes.Compare(k, fromKey) >= 0
+					}
+					return bytes.Compare(k, fromKey) <= 0
+				})
+			}
+		}
+		// `limit` is applied here - because skipped keys must not be counted.
+		// k, v are copied: history iterators re-use buffers (valid only for 2 .Next() calls)
+		for it.HasNext() && limit != 0 && len(reply.Keys) < int(req.PageSize) {
+			k, v, err := it.Next()
+			if err != nil {
+				return err
+			}
+			reply.Keys = append(reply.Keys, common.Copy(k))
+			reply.Values = append(reply.Values, common.Copy(v))
+			limit--
+		}
+		if it.HasNext() && limit != 0 {
+			nextK, nextV, err := it.Next()
+			if err != nil {
+				return err
+			}
+			reply.NextPageToken, err = marshalPagination(&remote.ParisPagination{NextKey: nextK, Limit: int64(limit)})
+			if err != nil {
+				return err
+			}
+			if tx.historyPages == nil {
+				tx.historyPages = map[string]*pausedKV{}
+			}
+			tx.historyPages[reply.NextPageToken] = &pausedKV{it: it, k: common.Copy(nextK), v: common.Copy(nextV)}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (s *KvServer) DomainRange(ctx context.Context, req *remote.DomainRangeReq) (*remote.Pairs, error) {
+	from, limit := req.FromKey, int(req.Limit)
+	if req.PageToken != "" {
+		var pagination remote.ParisPagination
+		if err := unmarshalPagination(req.PageToken, &pagination); err != nil {
+			return nil, err
+		}
+		from, limit = pagination.NextKey, int(pagination.Limit)
+	}
+	if req.PageSize <= 0 || req.PageSize > PageSizeLimit {
+		req.PageSize = PageSizeLimit
+	}
+
+	reply := &remote.Pairs{}
+	if err := s.with(req.TxId, func(tx kv.Tx) error {
+		ttx, ok := tx.(kv.TemporalTx)
+		if !ok {
+			return fmt.Errorf("server DB doesn't implement kv.Temporal interface")
+		}
+		it, err := ttx.DomainRange(kv.Domain(req.Table), from, req.ToKey, req.Ts, order.By(req.OrderAscend), limit)
+		if err != nil {
+			return err
+		}
+		for it.HasNext() && len(reply.Keys) < int(req.PageSize) {
+			k, v, err := it.Next()
+			if err != nil {
+				return err
+			}
+			reply.Keys = append(reply.Keys, k)
+			reply.Values = append(reply.Values, v)
+			limit--
+		}
+		if it.HasNext() && limit != 0 {
+			nextK, _, err := it.Next()
+			if err != nil {
+				return err
+			}
+			reply.NextPageToken, err = marshalPagination(&remote.ParisPagination{NextKey: nextK, Limit: int64(limit)})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 // see: https://cloud.google.com/apis/design/design_patterns
 func marshalPagination(m proto.Message) (string, error) {
 	pageToken, err := proto.Marshal(m)
@@ -17,12 +17,27 @@
 package remotedbserver
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"net"
 	"runtime"
 	"testing"
 
+	"github.com/ledgerwatch/log/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/iter"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/kv/order"
+	"github.com/ledgerwatch/erigon-lib/kv/remotedb"
+	"github.com/ledgerwatch/erigon-lib/kv/temporal"
+	"github.com/ledgerwatch/erigon-lib/kv/temporal/temporaltest"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
@@ -94,3 +109,257 @@ func TestKvServer_renew(t *testing.T) {
 	}
 	require.NoError(g.Wait())
 }
+
+// temporalDB - minimal kv.TemporalTx provider for tests: domain is served from kv.PlainState table,
+// history is served from kv.HashedAccounts table (timestamps ignored)
+type temporalDB struct{ kv.RwDB }
+type temporalTx struct{ kv.Tx }
+
+func (db *temporalDB) BeginRo(ctx context.Context) (kv.Tx, error) {
+	tx, err := db.RwDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &temporalTx{Tx: tx}, nil
+}
+
+func (tx *temporalTx) DomainGet(name kv.Domain, k, k2 []byte) (v []byte, ok bool, err error) {
+	panic("not implemented")
+}
+func (tx *temporalTx) DomainGetAsOf(name kv.Domain, k, k2 []byte, ts uint64) (v []byte, ok bool, err error) {
+	panic("not implemented")
+}
+func (tx *temporalTx) HistoryGet(name kv.History, k []byte, ts uint64) (v []byte, ok bool, err error) {
+	panic("not implemented")
+}
+func (tx *temporalTx) IndexRange(name kv.InvertedIdx, k []byte, fromTs, toTs int, asc order.By, limit int) (timestamps iter.U64, err error) {
+	panic("not implemented")
+}
+func (tx *temporalTx) HistoryRange(name kv.History, fromTs, toTs int, asc order.By, limit int) (it iter.KV, err error) {
+	if asc {
+		return tx.RangeAscend(kv.HashedAccounts, nil, nil, limit)
+	}
+	return tx.RangeDescend(kv.HashedAccounts, nil, nil, limit)
+}
+func (tx *temporalTx) DomainRange(name kv.Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (it iter.KV, err error) {
+	if asc {
+		return tx.RangeAscend(kv.PlainState, fromKey, toKey, limit)
+	}
+	return tx.RangeDescend(kv.PlainState, fromKey, toKey, limit)
+}
+
+func TestKvServer_TemporalRange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	require, ctx, db := require.New(t), context.Background(), memdb.NewTestDB(t)
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		for i := byte(1); i <= 5; i++ {
+			require.NoError(tx.Put(kv.PlainState, []byte{i}, []byte{i, i}))
+			require.NoError(tx.Put(kv.HashedAccounts, []byte{i}, []byte{i, i, i}))
+		}
+		return nil
+	}))
+
+	s := NewKvServer(ctx, &temporalDB{db}, nil, nil)
+	id, err := s.begin(ctx)
+	require.NoError(err)
+	defer s.rollback(id)
+
+	domainRange := func(asc order.By, limit int64) (keys [][]byte) {
+		req := &remote.DomainRangeReq{TxId: id, Table: "accounts", OrderAscend: bool(asc), Limit: limit, PageSize: 2}
+		for {
+			reply, err := s.DomainRange(ctx, req)
+			require.NoError(err)
+			require.LessOrEqual(len(reply.Keys), 2)
+			keys = append(keys, reply.Keys...)
+			if reply.NextPageToken == "" {
+				return keys
+			}
+			req.PageToken = reply.NextPageToken
+		}
+	}
+	require.Equal([][]byte{{1}, {2}, {3}, {4}, {5}}, domainRange(order.Asc, -1))
+	require.Equal([][]byte{{5}, {4}, {3}, {2}, {1}}, domainRange(order.Desc, -1))
+	require.Equal([][]byte{{1}, {2}, {3}}, domainRange(order.Asc, 3))
+	require.Equal([][]byte{{5}, {4}}, domainRange(order.Desc, 2))
+
+	historyRange := func(asc order.By, limit int64) (keys [][]byte) {
+		req := &remote.HistoryRangeReq{TxId: id, Table: "accounts", FromTs: -1, ToTs: -1, OrderAscend: bool(asc), Limit: limit, PageSize: 2}
+		for {
+			reply, err := s.HistoryRange(ctx, req)
+			require.NoError(err)
+			require.LessOrEqual(len(reply.Keys), 2)
+			keys = append(keys, reply.Keys...)
+			if reply.NextPageToken == "" {
+				return keys
+			}
+			req.PageToken = reply.NextPageToken
+		}
+	}
+	require.Equal([][]byte{{1}, {2}, {3}, {4}, {5}}, historyRange(order.Asc, -1))
+	require.Equal([][]byte{{5}, {4}, {3}, {2}, {1}}, historyRange(order.Desc, -1))
+	require.Equal([][]byte{{1}, {2}, {3}}, historyRange(order.Asc, 3))
+	require.Equal([][]byte{{5}, {4}}, historyRange(order.Desc, 2))
+
+	// non-temporal DB must return error instead of panic
+	s2 := NewKvServer(ctx, db, nil, nil)
+	id2, err := s2.begin(ctx)
+	require.NoError(err)
+	defer s2.rollback(id2)
+	_, err = s2.DomainRange(ctx, &remote.DomainRangeReq{TxId: id2, Table: "accounts"})
+	require.Error(err)
+	_, err = s2.HistoryRange(ctx, &remote.HistoryRangeReq{TxId: id2, Table: "accounts"})
+	require.Error(err)
+}
+
+func TestKvServer_HistoryRangeTemporal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	require, ctx, db := require.New(t), context.Background(), temporaltest.NewTestDB(t)
+	const n = 50
+	addr := func(i int) []byte { return bytes.Repeat([]byte{byte(i + 1)}, 20) }
+	prev := func(i int) []byte { return []byte{byte(i), 1} }
+	require.NoError(db.Update(ctx, func(tx kv.RwTx) error {
+		agg := db.Agg()
+		agg.SetTx(tx)
+		defer agg.StartWrites().FinishWrites()
+		for i := 0; i < n; i++ {
+			agg.SetTxNum(uint64(i + 1))
+			if err := agg.AddAccountPrev(addr(i), prev(i)); err != nil {
+				return err
+			}
+		}
+		return agg.Flush(ctx, tx)
+	}))
+
+	s := NewKvServer(ctx, db, nil, nil)
+	id, err := s.begin(ctx)
+	require.NoError(err)
+	defer s.rollback(id)
+
+	// resume - continue iterator of previous page, otherwise - re-scan history (page served by other server)
+	historyRange := func(fromTs int64, limit int64, resume bool) (keys, vals [][]byte) {
+		req := &remote.HistoryRangeReq{TxId: id, Table: string(temporal.AccountsHistory), FromTs: fromTs, ToTs: n + 1, OrderAscend: true, Limit: limit, PageSize: 3}
+		for {
+			if !resume {
+				s.txs[id].historyPages = nil
+			}
+			reply, err := s.HistoryRange(ctx, req)
+			require.NoError(err)
+			require.LessOrEqual(len(reply.Keys), 3)
+			keys, vals = append(keys, reply.Keys...), append(vals, reply.Values...)
+			if reply.NextPageToken == "" {
+				require.Empty(s.txs[id].historyPages)
+				return keys, vals
+			}
+			if resume {
+				require.Len(s.txs[id].historyPages, 1)
+			}
+			req.PageToken = reply.NextPageToken
+		}
+	}
+	for _, resume := range []bool{true, false} {
+		for _, c := range []struct {
+			fromTs, limit int64
+			from, to      int // expected accounts
+		}{
+			{0, -1, 0, n},
+			{0, 10, 0, 10},
+			{0, 3, 0, 3},
+			{21, -1, 20, n},
+		} {
+			keys, vals := historyRange(c.fromTs, c.limit, resume)
+			require.Equal(c.to-c.from, len(keys), "%+v resume=%t", c, resume)
+			for i := range keys {
+				require.Equal(addr(c.from+i), keys[i])
+				require.Equal(prev(c.from+i), vals[i])
+			}
+		}
+	}
+}
+
+func TestRemoteTemporalRange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	require, ctx, writeDB := require.New(t), context.Background(), memdb.NewTestDB(t)
+	const n = PageSizeLimit + PageSizeLimit/2 // more than 1 page
+	require.NoError(writeDB.Update(ctx, func(tx kv.RwTx) error {
+		k := make([]byte, 4)
+		for i := uint32(0); i < n; i++ {
+			binary.BigEndian.PutUint32(k, i)
+			require.NoError(tx.Put(kv.PlainState, k, k))
+			require.NoError(tx.Put(kv.HashedAccounts, k, k))
+		}
+		return nil
+	}))
+
+	grpcServer, conn := grpc.NewServer(), bufconn.Listen(1024*1024)
+	defer grpcServer.Stop()
+	remote.RegisterKVServer(grpcServer, NewKvServer(ctx, &temporalDB{writeDB}, nil, nil))
+	go func() {
+		if err := grpcServer.Serve(conn); err != nil {
+			log.Error("private RPC server fail", "err", err)
+		}
+	}()
+	cc, err := grpc.Dial("", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, url string) (net.Conn, error) { return conn.Dial() }))
+	require.NoError(err)
+	defer cc.Close()
+	db, err := remotedb.NewRemote(gointerfaces.VersionFromProto(KvServiceAPIVersion), log.New(), remote.NewKVClient(cc)).Open()
+	require.NoError(err)
+
+	tx, err := db.BeginTemporalRo(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	it, err := tx.DomainRange("accounts", nil, nil, 0, order.Asc, -1)
+	require.NoError(err)
+	keys, _, err := iter.ToDualArray[[]byte, []byte](it)
+	require.NoError(err)
+	require.Equal(n, len(keys))
+	require.Equal(uint32(n-1), binary.BigEndian.Uint32(keys[len(keys)-1]))
+
+	it, err = tx.DomainRange("accounts", nil, nil, 0, order.Desc, PageSizeLimit+1)
+	require.NoError(err)
+	keys, _, err = iter.ToDualArray[[]byte, []byte](it)
+	require.NoError(err)
+	require.Equal(PageSizeLimit+1, len(keys))
+	require.Equal(uint32(n-1), binary.BigEndian.Uint32(keys[0]))
+
+	it, err = tx.HistoryRange("accounts", -1, -1, order.Asc, -1)
+	require.NoError(err)
+	cnt, err := iter.CountDual[[]byte, []byte](it)
+	require.NoError(err)
+	require.Equal(n, cnt)
+}
+
+func TestRemoteTemporalConformance(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	ctx, localDB := context.Background(), temporaltest.NewTestDB(t)
+	temporaltest.WriteFixture(t, localDB)
+
+	grpcServer, conn := grpc.NewServer(), bufconn.Listen(1024*1024)
+	defer grpcServer.Stop()
+	remote.RegisterKVServer(grpcServer, NewKvServer(ctx, localDB, nil, nil))
+	go func() {
+		if err := grpcServer.Serve(conn); err != nil {
+			log.Error("private RPC server fail", "err", err)
+		}
+	}()
+	cc, err := grpc.Dial("", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, url string) (net.Conn, error) { return conn.Dial() }))
+	require.NoError(t, err)
+	defer cc.Close()
+	db, err := remotedb.NewRemote(gointerfaces.VersionFromProto(KvServiceAPIVersion), log.New(), remote.NewKVClient(cc)).Open()
+	require.NoError(t, err)
+	defer db.Close()
+
+	temporaltest.RunConformance(t, db)
+}
@@ -0,0 +1,184 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remotedbserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+)
+
+// Table diffs - changes of tables made by write transactions of db, for replicas (see kv/replica) which keep local copy
+// of db. If tx has StateChangeBatch - changes of StateChangesTables are not in its diff: replica builds them from
+// StateChanges stream.
+//
+// Served by KV.TableDiffs stream as remote.TableDiffBatch messages.
+
+// StateChangesTables - tables which changes are sent by StateChanges stream
+var StateChangesTables = []string{kv.PlainState, kv.PlainContractCode, kv.Code}
+
+// TableDiffBatch - changes made by one write transaction. State version of db is kv.PlainStateVersion sequence:
+// replica which has PrevStateVersionID applies batch and gets StateVersionID.
+type TableDiffBatch struct {
+	PrevStateVersionID uint64
+	StateVersionID     uint64
+	// WithStateChanges - StateChangeBatch with same StateVersionId is sent by StateChanges stream
+	WithStateChanges bool
+	Changeset        memdb.Changeset
+}
+
+// NewTableDiffBatch - changeset of tx, changes of StateChangesTables are stripped only if they come by StateChanges stream
+func NewTableDiffBatch(prevStateVersionID, stateVersionID uint64, withStateChanges bool, cs memdb.Changeset) *TableDiffBatch {
+	b := &TableDiffBatch{PrevStateVersionID: prevStateVersionID, StateVersionID: stateVersionID, WithStateChanges: withStateChanges}
+	if !withStateChanges {
+		b.Changeset = cs
+		return b
+	}
+	for _, c := range cs {
+		if !isStateChangesTable(c.Table) {
+			b.Changeset = append(b.Changeset, c)
+		}
+	}
+	return b
+}
+
+func isStateChangesTable(table string) bool {
+	for _, t := range StateChangesTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// ToProto - keys and values of changeset are copied
+func (b *TableDiffBatch) ToProto() (*remote.TableDiffBatch, error) {
+	cs, err := b.Changeset.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &remote.TableDiffBatch{
+		PrevStateVersionId: b.PrevStateVersionID,
+		StateVersionId:     b.StateVersionID,
+		WithStateChanges:   b.WithStateChanges,
+		Changeset:          cs,
+	}, nil
+}
+
+// TableDiffBatchFromProto - keys and values of changeset reference data of reply
+func TableDiffBatchFromProto(reply *remote.TableDiffBatch) (*TableDiffBatch, error) {
+	b := &TableDiffBatch{
+		PrevStateVersionID: reply.PrevStateVersionId,
+		StateVersionID:     reply.StateVersionId,
+		WithStateChanges:   reply.WithStateChanges,
+	}
+	if err := b.Changeset.UnmarshalBinary(reply.Changeset); err != nil {
+		return nil, fmt.Errorf("unmarshal table diff %d: %w", reply.StateVersionId, err)
+	}
+	return b, nil
+}
+
+type TableDiffPubSub struct {
+	chans map[uint]chan *TableDiffBatch
+	id    uint
+	mu    sync.RWMutex
+}
+
+func newTableDiffStreams() *TableDiffPubSub {
+	return &TableDiffPubSub{}
+}
+
+func (s *TableDiffPubSub) Sub() (ch chan *TableDiffBatch, remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chans == nil {
+		s.chans = make(map[uint]chan *TableDiffBatch)
+	}
+	s.id++
+	id := s.id
+	ch = make(chan *TableDiffBatch, 8)
+	s.chans[id] = ch
+	return ch, func() { s.remove(id) }
+}
+
+func (s *TableDiffPubSub) Pub(reply *TableDiffBatch) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ch := range s.chans {
+		common.PrioritizedSend(ch, reply)
+	}
+}
+
+func (s *TableDiffPubSub) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.chans)
+}
+
+func (s *TableDiffPubSub) remove(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.chans[id]
+	if !ok { // double-unsubscribe support
+		return
+	}
+	close(ch)
+	delete(s.chans, id)
+}
+
+// SendTableDiffs - must be called after commit of tx, in order of commits. Every tx must bump kv.PlainStateVersion
+// (StateVersionID > PrevStateVersionID): replicas order diffs by it
+func (s *KvServer) SendTableDiffs(ctx context.Context, b *TableDiffBatch) {
+	s.tableDiffStreams.Pub(b)
+}
+
+// Subscribers - amount of StateChanges and TableDiffs streams
+func (s *KvServer) Subscribers() (stateChanges, tableDiffs int) {
+	return s.stateChangeStreams.Len(), s.tableDiffStreams.Len()
+}
+
+// TableDiffs - streams diffs of txs committed after subscription
+func (s *KvServer) TableDiffs(_ *emptypb.Empty, server remote.KV_TableDiffsServer) error {
+	ch, remove := s.tableDiffStreams.Sub()
+	defer remove()
+	for {
+		select {
+		case b, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			reply, err := b.ToProto()
+			if err != nil {
+				return err
+			}
+			if err = server.Send(reply); err != nil {
+				return err
+			}
+		case <-s.ctx.Done():
+			return nil
+		case <-server.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,315 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package replica
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
+)
+
+// Follower - read-only replica of db: keeps local copy of primary db in sync by StateChanges and TableDiffs streams
+// of primary's remotedbserver.KvServer, and serves reads from local copy (without gRPC round-trip per read).
+//
+// Local db must start as copy of primary (for example made by mdbx.MdbxKV.Backup) - it has state version
+// (kv.PlainStateVersion sequence) of primary at moment of copy. Every diff batch is applied in one tx, so readers see
+// only states which primary had. If replica misses batch (was disconnected, or stream dropped it because replica
+// was too slow) - Run returns ErrGap: local db must be re-copied. Every write tx of primary must bump state version,
+// otherwise Run returns ErrNoVersionBump.
+type Follower struct {
+	db     kv.RwDB
+	cc     grpc.ClientConnInterface
+	logger log.Logger
+
+	lock     sync.Mutex
+	version  uint64
+	versionC chan struct{} // closed and replaced on every applied batch
+}
+
+var (
+	ErrGap           = errors.New("replica: missed diffs of primary db")
+	ErrNoVersionBump = errors.New("replica: diff of primary db doesn't bump state version")
+)
+
+func NewFollower(db kv.RwDB, cc grpc.ClientConnInterface, logger log.Logger) *Follower {
+	return &Follower{db: db, cc: cc, logger: logger, versionC: make(chan struct{})}
+}
+
+// roDB - hides write methods of local db
+type roDB struct{ kv.RoDB }
+
+// DB - local copy, for reads only
+func (f *Follower) DB() kv.RoDB { return roDB{f.db} }
+
+// StateVersion - version of primary db which local copy has
+func (f *Follower) StateVersion() uint64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.version
+}
+
+// WaitStateVersion - waits until local copy has version >= v
+func (f *Follower) WaitStateVersion(ctx context.Context, v uint64) error {
+	for {
+		f.lock.Lock()
+		version, ch := f.version, f.versionC
+		f.lock.Unlock()
+		if version >= v {
+			return nil
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *Follower) setVersion(v uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.version = v
+	close(f.versionC)
+	f.versionC = make(chan struct{})
+}
+
+// Run - follows primary until ctx is done or error (streams are closed, ErrGap, etc...)
+func (f *Follower) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// subscribe before reading version: batches which local copy already has are skipped
+	stateChanges, err := remote.NewKVClient(f.cc).StateChanges(ctx, &remote.StateChangeRequest{WithStorage: true}, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+	diffs, err := remote.NewKVClient(f.cc).TableDiffs(ctx, &emptypb.Empty{}, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+	var version uint64
+	if err = f.db.View(ctx, func(tx kv.Tx) (err error) {
+		version, err = readStateVersion(tx)
+		return err
+	}); err != nil {
+		return err
+	}
+	f.setVersion(version)
+
+	scCh, diffCh, errCh := make(chan *remote.StateChangeBatch, 16), make(chan *remotedbserver.TableDiffBatch, 16), make(chan error, 2)
+	go func() {
+		for {
+			sc, err := stateChanges.Recv()
+			if err != nil {
+				errCh <- fmt.Errorf("replica: state changes stream: %w", err)
+				return
+			}
+			select {
+			case scCh <- sc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			reply, err := diffs.Recv()
+			if err != nil {
+				errCh <- fmt.Errorf("replica: table diffs stream: %w", err)
+				return
+			}
+			d, err := remotedbserver.TableDiffBatchFromProto(reply)
+			if err != nil {
+				errCh <- fmt.Errorf("replica: table diffs stream: %w", err)
+				return
+			}
+			select {
+			case diffCh <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// diffs are applied in order they are received, StateChangeBatch with same version is awaited if diff has it.
+	// StateChangeBatch of commit is sent before diffs of next commits, and both streams are ordered: if newer
+	// StateChangeBatch or next diff is received while diff is waiting - its StateChangeBatch was dropped
+	var queue []*remotedbserver.TableDiffBatch
+	pending := map[uint64]*remote.StateChangeBatch{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			return err
+		case sc := <-scCh:
+			if sc.StateVersionId > version {
+				pending[sc.StateVersionId] = sc
+			}
+		case d := <-diffCh:
+			// version is the only order of diffs - diff which doesn't bump it can't be told from already applied one
+			if d.StateVersionID <= d.PrevStateVersionID {
+				return fmt.Errorf("%w: %d -> %d", ErrNoVersionBump, d.PrevStateVersionID, d.StateVersionID)
+			}
+			if d.StateVersionID > version {
+				queue = append(queue, d)
+			}
+		}
+		for len(queue) > 0 {
+			d := queue[0]
+			if d.PrevStateVersionID != version {
+				return fmt.Errorf("%w: local version %d, next diff %d -> %d", ErrGap, version, d.PrevStateVersionID, d.StateVersionID)
+			}
+			sc, ok := pending[d.StateVersionID]
+			if d.WithStateChanges && !ok {
+				if len(queue) > 1 || hasNewerStateChanges(pending, d.StateVersionID) {
+					return fmt.Errorf("%w: state changes of diff %d -> %d", ErrGap, d.PrevStateVersionID, d.StateVersionID)
+				}
+				break
+			}
+			if err = f.db.Update(ctx, func(tx kv.RwTx) error { return apply(tx, d, sc) }); err != nil {
+				return fmt.Errorf("replica: apply diff %d: %w", d.StateVersionID, err)
+			}
+			version = d.StateVersionID
+			f.setVersion(version)
+			queue = queue[1:]
+			for id := range pending {
+				if id <= version {
+					delete(pending, id)
+				}
+			}
+		}
+	}
+}
+
+func hasNewerStateChanges(pending map[uint64]*remote.StateChangeBatch, version uint64) bool {
+	for id := range pending {
+		if id > version {
+			return true
+		}
+	}
+	return false
+}
+
+func readStateVersion(tx kv.Tx) (uint64, error) {
+	v, err := tx.GetOne(kv.Sequence, kv.PlainStateVersion)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// apply - sc is nil if diff has no state changes
+func apply(tx kv.RwTx, d *remotedbserver.TableDiffBatch, sc *remote.StateChangeBatch) error {
+	if err := d.Changeset.Apply(tx); err != nil {
+		return err
+	}
+	if sc != nil {
+		for _, change := range sc.ChangeBatch {
+			if err := applyStateChange(tx, change); err != nil {
+				return err
+			}
+		}
+	}
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], d.StateVersionID)
+	return tx.Put(kv.Sequence, kv.PlainStateVersion, version[:])
+}
+
+// applyStateChange - writes changes to StateChangesTables, same way as kvcache.Coherent does in memory
+func applyStateChange(tx kv.RwTx, change *remote.StateChange) error {
+	for _, ac := range change.Changes {
+		addr := gointerfaces.ConvertH160toAddress(ac.Address)
+		storagePrefix := make([]byte, length.Addr+length.Incarnation)
+		copy(storagePrefix, addr[:])
+		binary.BigEndian.PutUint64(storagePrefix[length.Addr:], ac.Incarnation)
+
+		switch ac.Action {
+		case remote.Action_UPSERT, remote.Action_UPSERT_CODE:
+			if err := tx.Put(kv.PlainState, addr[:], ac.Data); err != nil {
+				return err
+			}
+		case remote.Action_REMOVE:
+			if err := tx.Delete(kv.PlainState, addr[:]); err != nil {
+				return err
+			}
+			if err := deleteStorage(tx, storagePrefix); err != nil {
+				return err
+			}
+			if err := tx.Delete(kv.PlainContractCode, storagePrefix); err != nil {
+				return err
+			}
+		}
+		if ac.Action == remote.Action_CODE || ac.Action == remote.Action_UPSERT_CODE {
+			h := sha3.NewLegacyKeccak256()
+			h.Write(ac.Code)
+			codeHash := h.Sum(nil)
+			if err := tx.Put(kv.Code, codeHash, ac.Code); err != nil {
+				return err
+			}
+			if err := tx.Put(kv.PlainContractCode, storagePrefix, codeHash); err != nil {
+				return err
+			}
+		}
+		for _, sc := range ac.StorageChanges {
+			loc := gointerfaces.ConvertH256ToHash(sc.Location)
+			k := append(append([]byte{}, storagePrefix...), loc[:]...)
+			if len(sc.Data) == 0 {
+				if err := tx.Delete(kv.PlainState, k); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Put(kv.PlainState, k, sc.Data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func deleteStorage(tx kv.RwTx, prefix []byte) error {
+	c, err := tx.RwCursorDupSort(kv.PlainState)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	k, _, err := c.SeekExact(prefix)
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		return nil
+	}
+	return c.DeleteCurrentDuplicates()
+}
@@ -0,0 +1,277 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package replica
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/iter"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
+)
+
+// primary - in-process primary db with KvServer, publishes changes of every commit
+type primary struct {
+	t       *testing.T
+	db      kv.RwDB
+	srv     *remotedbserver.KvServer
+	version uint64
+
+	dropStateChanges bool // stream drops StateChangeBatch of next commit
+	skipVersionBump  bool // next commit doesn't bump state version
+}
+
+func newPrimary(t *testing.T, ctx context.Context) (*primary, grpc.ClientConnInterface) {
+	p := &primary{t: t, db: memdb.NewTestDB(t)}
+	p.srv = remotedbserver.NewKvServer(ctx, p.db, nil, nil)
+	grpcServer, conn := grpc.NewServer(), bufconn.Listen(1024*1024)
+	t.Cleanup(grpcServer.Stop)
+	remote.RegisterKVServer(grpcServer, p.srv)
+	go func() { _ = grpcServer.Serve(conn) }()
+	cc, err := grpc.Dial("", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, url string) (net.Conn, error) { return conn.Dial() }))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return p, cc
+}
+
+// commit - write must do same changes of state as it returns (nil - no state changes)
+func (p *primary) commit(write func(tx kv.RwTx) []*remote.AccountChange, diffFirst bool) {
+	ctx := context.Background()
+	tx, err := p.db.BeginRw(ctx)
+	require.NoError(p.t, err)
+	defer tx.Rollback()
+	m := memdb.NewMemoryBatch(tx, p.t.TempDir())
+	defer m.Close()
+	changes := write(m)
+	prev := p.version
+	if p.skipVersionBump {
+		p.skipVersionBump = false
+	} else {
+		p.version++
+		var version [8]byte
+		binary.BigEndian.PutUint64(version[:], p.version)
+		require.NoError(p.t, m.Put(kv.Sequence, kv.PlainStateVersion, version[:]))
+	}
+	cs, err := m.Changeset()
+	require.NoError(p.t, err)
+	require.NoError(p.t, m.Flush(tx))
+	require.NoError(p.t, tx.Commit())
+
+	diff := remotedbserver.NewTableDiffBatch(prev, p.version, changes != nil, cs)
+	if diffFirst {
+		p.srv.SendTableDiffs(ctx, diff)
+	}
+	if changes != nil && p.dropStateChanges {
+		p.dropStateChanges = false
+	} else if changes != nil {
+		p.srv.SendStateChanges(ctx, &remote.StateChangeBatch{StateVersionId: p.version, ChangeBatch: []*remote.StateChange{
+			{Direction: remote.Direction_FORWARD, BlockHeight: p.version, Changes: changes},
+		}})
+	}
+	if !diffFirst {
+		p.srv.SendTableDiffs(ctx, diff)
+	}
+}
+
+func storageKey(addr common.Address, slot byte) []byte {
+	k := make([]byte, 20+8+32)
+	copy(k, addr[:])
+	binary.BigEndian.PutUint64(k[20:], 1)
+	k[20+8+31] = slot
+	return k
+}
+
+func storageChange(slot byte, v []byte) *remote.StorageChange {
+	return &remote.StorageChange{Location: gointerfaces.ConvertHashToH256(common.Hash{31: slot}), Data: v}
+}
+
+func requireEqualTables(t *testing.T, db1, db2 kv.RoDB, tables ...string) {
+	ctx := context.Background()
+	for _, table := range tables {
+		var k1, v1, k2, v2 [][]byte
+		require.NoError(t, db1.View(ctx, func(tx kv.Tx) (err error) {
+			it, err := tx.Range(table, nil, nil)
+			require.NoError(t, err)
+			k1, v1, err = iter.ToKVArray(it)
+			return err
+		}))
+		require.NoError(t, db2.View(ctx, func(tx kv.Tx) (err error) {
+			it, err := tx.Range(table, nil, nil)
+			require.NoError(t, err)
+			k2, v2, err = iter.ToKVArray(it)
+			return err
+		}))
+		require.Equal(t, k1, k2, table)
+		require.Equal(t, v1, v2, table)
+	}
+}
+
+func TestFollower(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	p, cc := newPrimary(t, ctx)
+
+	f := NewFollower(memdb.NewTestDB(t), cc, log.New())
+	runErr := make(chan error, 1)
+	go func() { runErr <- f.Run(ctx) }()
+	for sc, diffs := p.srv.Subscribers(); sc != 1 || diffs != 1; sc, diffs = p.srv.Subscribers() {
+		time.Sleep(time.Millisecond)
+	}
+
+	addr, contract := common.Address{1}, common.Address{2}
+	code := []byte{0x60, 0x00}
+	h := sha3.NewLegacyKeccak256()
+	h.Write(code)
+	codeHash := h.Sum(nil)
+	contractKey := append(append([]byte{}, contract[:]...), 0, 0, 0, 0, 0, 0, 0, 1)
+
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange {
+		require.NoError(t, tx.Put(kv.PlainState, addr[:], []byte{1}))
+		require.NoError(t, tx.Put(kv.PlainState, storageKey(addr, 1), []byte{0x11}))
+		require.NoError(t, tx.Put(kv.PlainState, storageKey(addr, 2), []byte{0x22}))
+		require.NoError(t, tx.Put(kv.PlainState, contract[:], []byte{2}))
+		require.NoError(t, tx.Put(kv.Code, codeHash, code))
+		require.NoError(t, tx.Put(kv.PlainContractCode, contractKey, codeHash))
+		require.NoError(t, kv.HeaderCanonicalTbl.Put(tx, 1, common.Hash{1}))
+		require.NoError(t, kv.HeadersTbl.Put(tx, kv.BlockNumHash{Num: 1, Hash: common.Hash{1}}, []byte("header 1")))
+		return []*remote.AccountChange{
+			{Address: gointerfaces.ConvertAddressToH160(addr), Incarnation: 1, Action: remote.Action_UPSERT, Data: []byte{1},
+				StorageChanges: []*remote.StorageChange{storageChange(1, []byte{0x11}), storageChange(2, []byte{0x22})}},
+			{Address: gointerfaces.ConvertAddressToH160(contract), Incarnation: 1, Action: remote.Action_UPSERT_CODE, Data: []byte{2}, Code: code},
+		}
+	}, false)
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange { // no state changes
+		require.NoError(t, kv.HeaderCanonicalTbl.Put(tx, 2, common.Hash{2}))
+		require.NoError(t, tx.Delete(kv.Headers, kv.BlockNumHashCodec.Encode(nil, kv.BlockNumHash{Num: 1, Hash: common.Hash{1}})))
+		return nil
+	}, false)
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange { // diff comes before state changes
+		require.NoError(t, tx.Put(kv.PlainState, addr[:], []byte{3}))
+		require.NoError(t, tx.Delete(kv.PlainState, storageKey(addr, 1)))
+		require.NoError(t, kv.HeaderCanonicalTbl.Put(tx, 3, common.Hash{3}))
+		return []*remote.AccountChange{
+			{Address: gointerfaces.ConvertAddressToH160(addr), Incarnation: 1, Action: remote.Action_UPSERT, Data: []byte{3},
+				StorageChanges: []*remote.StorageChange{storageChange(1, nil)}},
+		}
+	}, true)
+	require.NoError(t, f.WaitStateVersion(ctx, 3))
+	requireEqualTables(t, p.db, f.DB(), kv.PlainState, kv.Code, kv.PlainContractCode, kv.HeaderCanonical, kv.Headers, kv.Sequence)
+
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange {
+		require.NoError(t, tx.Delete(kv.PlainState, addr[:]))
+		require.NoError(t, tx.Delete(kv.PlainState, storageKey(addr, 2)))
+		return []*remote.AccountChange{
+			{Address: gointerfaces.ConvertAddressToH160(addr), Incarnation: 1, Action: remote.Action_REMOVE},
+		}
+	}, false)
+	require.NoError(t, f.WaitStateVersion(ctx, 4))
+	require.Equal(t, uint64(4), f.StateVersion())
+	requireEqualTables(t, p.db, f.DB(), kv.PlainState, kv.Code, kv.PlainContractCode, kv.HeaderCanonical, kv.Headers, kv.Sequence)
+
+	// tx which changes state without StateChangeBatch (not a block execution) - state comes in its diff
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange {
+		require.NoError(t, tx.Put(kv.PlainState, contract[:], []byte{5}))
+		require.NoError(t, tx.Put(kv.PlainState, storageKey(contract, 1), []byte{0x55}))
+		require.NoError(t, tx.Delete(kv.PlainContractCode, contractKey))
+		return nil
+	}, false)
+	require.NoError(t, f.WaitStateVersion(ctx, 5))
+	requireEqualTables(t, p.db, f.DB(), kv.PlainState, kv.Code, kv.PlainContractCode, kv.HeaderCanonical, kv.Headers, kv.Sequence)
+
+	_, isRw := f.DB().(kv.RwDB)
+	require.False(t, isRw)
+
+	// follower missed batch
+	p.version++
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange { return nil }, false)
+	require.ErrorIs(t, <-runErr, ErrGap)
+}
+
+func TestFollowerMissedStateChanges(t *testing.T) {
+	addr := common.Address{1}
+	write := func(v byte) func(tx kv.RwTx) []*remote.AccountChange {
+		return func(tx kv.RwTx) []*remote.AccountChange {
+			require.NoError(t, tx.Put(kv.PlainState, addr[:], []byte{v}))
+			return []*remote.AccountChange{{Address: gointerfaces.ConvertAddressToH160(addr), Incarnation: 1, Action: remote.Action_UPSERT, Data: []byte{v}}}
+		}
+	}
+	for name, diffFirst := range map[string]bool{"newer state changes": false, "next diff": true} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			p, cc := newPrimary(t, ctx)
+
+			f := NewFollower(memdb.NewTestDB(t), cc, log.New())
+			runErr := make(chan error, 1)
+			go func() { runErr <- f.Run(ctx) }()
+			for sc, diffs := p.srv.Subscribers(); sc != 1 || diffs != 1; sc, diffs = p.srv.Subscribers() {
+				time.Sleep(time.Millisecond)
+			}
+
+			p.commit(write(1), false)
+			require.NoError(t, f.WaitStateVersion(ctx, 1))
+			p.dropStateChanges = true
+			p.commit(write(2), false)
+			p.commit(write(3), diffFirst)
+			require.ErrorIs(t, <-runErr, ErrGap)
+			require.Equal(t, uint64(1), f.StateVersion())
+		})
+	}
+}
+
+func TestFollowerDiffWithoutVersionBump(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	p, cc := newPrimary(t, ctx)
+
+	f := NewFollower(memdb.NewTestDB(t), cc, log.New())
+	runErr := make(chan error, 1)
+	go func() { runErr <- f.Run(ctx) }()
+	for sc, diffs := p.srv.Subscribers(); sc != 1 || diffs != 1; sc, diffs = p.srv.Subscribers() {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange {
+		require.NoError(t, kv.HeaderCanonicalTbl.Put(tx, 1, common.Hash{1}))
+		return nil
+	}, false)
+	require.NoError(t, f.WaitStateVersion(ctx, 1))
+
+	// diff must not be silently skipped as already applied
+	p.skipVersionBump = true
+	p.commit(func(tx kv.RwTx) []*remote.AccountChange {
+		require.NoError(t, kv.HeaderCanonicalTbl.Put(tx, 2, common.Hash{2}))
+		return nil
+	}, false)
+	require.ErrorIs(t, <-runErr, ErrNoVersionBump)
+	require.Equal(t, uint64(1), f.StateVersion())
+}
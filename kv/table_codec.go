@@ -0,0 +1,188 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kv
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv/iter"
+)
+
+// Codec - encoding of keys or values of typed table. Encoding of keys must preserve order: db iterates keys in
+// lexicographic order of encoded bytes.
+type Codec[T any] interface {
+	// Encode - appends encoded v to buf
+	Encode(buf []byte, v T) []byte
+	// Decode - result may reference b (zero-copy): it's valid only until end of transaction, as result of GetOne
+	Decode(b []byte) (T, error)
+}
+
+// BlockNumHash - composite key: block_num_u64 + hash
+type BlockNumHash struct {
+	Num  uint64
+	Hash common.Hash
+}
+
+var (
+	U64Codec          Codec[uint64]         = u64Codec{}     // big-endian 8 bytes
+	BytesCodec        Codec[[]byte]         = bytesCodec{}   // as-is
+	HashCodec         Codec[common.Hash]    = hashCodec{}    // 32 bytes
+	AddressCodec      Codec[common.Address] = addressCodec{} // 20 bytes
+	BlockNumHashCodec Codec[BlockNumHash]   = blockNumHashCodec{}
+)
+
+func expectLen(b []byte, l int) error {
+	if len(b) != l {
+		return fmt.Errorf("expected %d bytes, got %d", l, len(b))
+	}
+	return nil
+}
+
+type u64Codec struct{}
+
+func (u64Codec) Encode(buf []byte, v uint64) []byte { return binary.BigEndian.AppendUint64(buf, v) }
+func (u64Codec) Decode(b []byte) (uint64, error) {
+	if err := expectLen(b, 8); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Encode(buf []byte, v []byte) []byte { return append(buf, v...) }
+func (bytesCodec) Decode(b []byte) ([]byte, error)    { return b, nil }
+
+type hashCodec struct{}
+
+func (hashCodec) Encode(buf []byte, v common.Hash) []byte { return append(buf, v[:]...) }
+func (hashCodec) Decode(b []byte) (h common.Hash, err error) {
+	if err = expectLen(b, length.Hash); err != nil {
+		return h, err
+	}
+	return common.BytesToHash(b), nil
+}
+
+type addressCodec struct{}
+
+func (addressCodec) Encode(buf []byte, v common.Address) []byte { return append(buf, v[:]...) }
+func (addressCodec) Decode(b []byte) (a common.Address, err error) {
+	if err = expectLen(b, length.Addr); err != nil {
+		return a, err
+	}
+	return common.BytesToAddress(b), nil
+}
+
+type blockNumHashCodec struct{}
+
+func (blockNumHashCodec) Encode(buf []byte, v BlockNumHash) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, v.Num)
+	return append(buf, v.Hash[:]...)
+}
+func (blockNumHashCodec) Decode(b []byte) (v BlockNumHash, err error) {
+	if err = expectLen(b, length.BlockNum+length.Hash); err != nil {
+		return v, err
+	}
+	v.Num = binary.BigEndian.Uint64(b)
+	v.Hash = common.BytesToHash(b[length.BlockNum:])
+	return v, nil
+}
+
+// Table - typed view of table: keys and values are encoded by codecs declared once (see typed tables in tables.go),
+// so app code can't pass mis-encoded key. It's optional layer on top of Tx - raw table API still works.
+type Table[K, V any] struct {
+	Name string
+	Key  Codec[K]
+	Val  Codec[V]
+}
+
+func NewTable[K, V any](name string, key Codec[K], val Codec[V]) *Table[K, V] {
+	return &Table[K, V]{Name: name, Key: key, Val: val}
+}
+
+// Get - ok=false if key not found
+func (t *Table[K, V]) Get(tx Getter, k K) (v V, ok bool, err error) {
+	enc, err := tx.GetOne(t.Name, t.Key.Encode(nil, k))
+	if err != nil {
+		return v, false, err
+	}
+	if enc == nil {
+		return v, false, nil
+	}
+	if v, err = t.Val.Decode(enc); err != nil {
+		return v, false, fmt.Errorf("table %s: value of key %v: %w", t.Name, k, err)
+	}
+	return v, true, nil
+}
+
+func (t *Table[K, V]) Has(tx Has, k K) (bool, error) {
+	return tx.Has(t.Name, t.Key.Encode(nil, k))
+}
+
+func (t *Table[K, V]) Put(tx Putter, k K, v V) error {
+	return tx.Put(t.Name, t.Key.Encode(nil, k), t.Val.Encode(nil, v))
+}
+
+// Append - see RwTx.Append: key must be bigger than all keys in table
+func (t *Table[K, V]) Append(tx RwTx, k K, v V) error {
+	return tx.Append(t.Name, t.Key.Encode(nil, k), t.Val.Encode(nil, v))
+}
+
+func (t *Table[K, V]) Delete(tx Deleter, k K) error {
+	return tx.Delete(t.Name, t.Key.Encode(nil, k))
+}
+
+// Range - [from, to)
+func (t *Table[K, V]) Range(tx Tx, from, to K) (iter.Dual[K, V], error) {
+	it, err := tx.Range(t.Name, t.Key.Encode(nil, from), t.Key.Encode(nil, to))
+	if err != nil {
+		return nil, err
+	}
+	return iter.TransformKV2Dual[K, V](it, t.decode), nil
+}
+
+// RangeFrom - [from, EndOfTable)
+func (t *Table[K, V]) RangeFrom(tx Tx, from K) (iter.Dual[K, V], error) {
+	it, err := tx.Range(t.Name, t.Key.Encode(nil, from), nil)
+	if err != nil {
+		return nil, err
+	}
+	return iter.TransformKV2Dual[K, V](it, t.decode), nil
+}
+
+// Prefix - all keys which start with encoded prefix. Useful for composite keys: for example all hashes of block
+// in table with BlockNumHash keys: Prefix(tx, U64Codec.Encode(nil, blockNum))
+func (t *Table[K, V]) Prefix(tx Tx, prefix []byte) (iter.Dual[K, V], error) {
+	it, err := tx.Prefix(t.Name, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return iter.TransformKV2Dual[K, V](it, t.decode), nil
+}
+
+func (t *Table[K, V]) decode(k, v []byte) (key K, val V, err error) {
+	if key, err = t.Key.Decode(k); err != nil {
+		return key, val, fmt.Errorf("table %s: key %x: %w", t.Name, k, err)
+	}
+	if val, err = t.Val.Decode(v); err != nil {
+		return key, val, fmt.Errorf("table %s: value of key %x: %w", t.Name, k, err)
+	}
+	return key, val, nil
+}
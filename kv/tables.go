@@ -365,6 +365,7 @@ const (
 	// Downloader
 	BittorrentCompletion = "BittorrentCompletion"
 	BittorrentInfo       = "BittorrentInfo"
+	BittorrentVerified   = "BittorrentVerified" // file_name -> size_u64 + mod_time_u64 + verified_at_u64 + info_hash + pieces_u32 + bad_pieces_u32
 
 	// Domains and Inverted Indices
 	AccountKeys        = "AccountKeys"
@@ -469,6 +470,18 @@ var (
 	LightClientOptimisticUpdate = []byte("LightClientOptimisticUpdate")
 )
 
+// Typed tables - key/value codecs of tables, see Table. New code should prefer them to hand-encoded keys.
+var (
+	HeaderNumberTbl    = NewTable(HeaderNumber, HashCodec, U64Codec)
+	HeaderCanonicalTbl = NewTable(HeaderCanonical, U64Codec, HashCodec)
+	HeadersTbl         = NewTable(Headers, BlockNumHashCodec, BytesCodec)
+	HeaderTDTbl        = NewTable(HeaderTD, BlockNumHashCodec, BytesCodec)
+	BlockBodyTbl       = NewTable(BlockBody, BlockNumHashCodec, BytesCodec)
+	SendersTbl         = NewTable(Senders, BlockNumHashCodec, BytesCodec)
+	MaxTxNumTbl        = NewTable(MaxTxNum, U64Codec, U64Codec)
+	ParliaSnapshotTbl  = NewTable(ParliaSnapshot, BlockNumHashCodec, BytesCodec)
+)
+
 // ChaindataTables - list of all buckets. App will panic if some bucket is not in this list.
 // This list will be sorted in `init` method.
 // ChaindataTablesCfg - can be used to find index in sorted version of ChaindataTables list by name
@@ -600,6 +613,7 @@ var SentryTables = []string{}
 var DownloaderTables = []string{
 	BittorrentCompletion,
 	BittorrentInfo,
+	BittorrentVerified,
 }
 var ReconTables = []string{
 	PlainStateR,
@@ -0,0 +1,421 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/iter"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon-lib/kv/order"
+	"github.com/ledgerwatch/erigon-lib/state"
+)
+
+const (
+	AccountsDomain kv.Domain = "AccountsDomain"
+	StorageDomain  kv.Domain = "StorageDomain"
+	CodeDomain     kv.Domain = "CodeDomain"
+)
+
+const (
+	AccountsHistory kv.History = "AccountsHistory"
+	StorageHistory  kv.History = "StorageHistory"
+	CodeHistory     kv.History = "CodeHistory"
+)
+
+const (
+	AccountsHistoryIdx kv.InvertedIdx = "AccountsHistoryIdx"
+	StorageHistoryIdx  kv.InvertedIdx = "StorageHistoryIdx"
+	CodeHistoryIdx     kv.InvertedIdx = "CodeHistoryIdx"
+
+	LogTopicIdx   kv.InvertedIdx = "LogTopicIdx"
+	LogAddrIdx    kv.InvertedIdx = "LogAddrIdx"
+	TracesFromIdx kv.InvertedIdx = "TracesFromIdx"
+	TracesToIdx   kv.InvertedIdx = "TracesToIdx"
+)
+
+const (
+	addrLen        = 20
+	incarnationLen = 8
+	locationLen    = 32
+)
+
+// DB - kv.TemporalRwDB over MDBX and AggregatorV3:
+//   - latest state (Domain without timestamp) is read from kv.PlainState and kv.Code tables
+//   - history and inverted indices are read from AggregatorV3 (its files and recent data in same MDBX)
+//
+// Domain keys:
+//   - AccountsDomain: k - address
+//   - StorageDomain: k - address+incarnation (incarnation is used only to read latest state), k2 - location
+//   - CodeDomain: k - address (used only to read history), k2 - codeHash (used only to read latest state)
+//
+// History values equal to empty slice mean "key didn't exist at given ts", Domain methods return them as not found.
+type DB struct {
+	kv.RwDB
+	agg *state.AggregatorV3
+
+	convertV3toV2 func(v []byte) ([]byte, error)
+}
+
+// New - db must be MDBX. convertV3toV2 converts accounts from encoding used by AggregatorV3.AddAccountPrev
+// to encoding of kv.PlainState, nil means that both use same encoding.
+func New(db kv.RwDB, agg *state.AggregatorV3, convertV3toV2 func(v []byte) ([]byte, error)) (*DB, error) {
+	if _, ok := db.(*mdbx.MdbxKV); !ok {
+		return nil, fmt.Errorf("temporal.New: expected MDBX database, got %T", db)
+	}
+	return &DB{RwDB: db, agg: agg, convertV3toV2: convertV3toV2}, nil
+}
+
+func (db *DB) Agg() *state.AggregatorV3 { return db.agg }
+
+func (db *DB) BeginTemporalRo(ctx context.Context) (kv.TemporalTx, error) {
+	kvTx, err := db.RwDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{MdbxTx: kvTx.(*mdbx.MdbxTx), db: db, aggCtx: db.agg.MakeContext()}, nil
+}
+func (db *DB) ViewTemporal(ctx context.Context, f func(tx kv.TemporalTx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+// BeginRo - returns kv.TemporalTx, so DB can be passed where kv.RoDB is expected (for example to remotedbserver)
+func (db *DB) BeginRo(ctx context.Context) (kv.Tx, error) {
+	return db.BeginTemporalRo(ctx)
+}
+func (db *DB) View(ctx context.Context, f func(tx kv.Tx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+func (db *DB) BeginTemporalRw(ctx context.Context) (kv.RwTx, error) {
+	kvTx, err := db.RwDB.BeginRw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{MdbxTx: kvTx.(*mdbx.MdbxTx), db: db, aggCtx: db.agg.MakeContext()}, nil
+}
+func (db *DB) BeginRw(ctx context.Context) (kv.RwTx, error) {
+	return db.BeginTemporalRw(ctx)
+}
+func (db *DB) Update(ctx context.Context, f func(tx kv.RwTx) error) error {
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *DB) BeginTemporalRwNosync(ctx context.Context) (kv.RwTx, error) {
+	kvTx, err := db.RwDB.BeginRwNosync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{MdbxTx: kvTx.(*mdbx.MdbxTx), db: db, aggCtx: db.agg.MakeContext()}, nil
+}
+func (db *DB) BeginRwNosync(ctx context.Context) (kv.RwTx, error) {
+	return db.BeginTemporalRwNosync(ctx)
+}
+func (db *DB) UpdateNosync(ctx context.Context, f func(tx kv.RwTx) error) error {
+	tx, err := db.BeginRwNosync(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type Tx struct {
+	*mdbx.MdbxTx
+	db               *DB
+	aggCtx           *state.AggregatorV3Context
+	resourcesToClose []kv.Closer
+}
+
+func (tx *Tx) AggCtx() *state.AggregatorV3Context { return tx.aggCtx }
+
+func (tx *Tx) closeResources() {
+	for _, closer := range tx.resourcesToClose {
+		closer.Close()
+	}
+	tx.resourcesToClose = nil
+	if tx.aggCtx != nil {
+		tx.aggCtx.Close()
+		tx.aggCtx = nil
+	}
+}
+
+func (tx *Tx) Rollback() {
+	tx.closeResources()
+	tx.MdbxTx.Rollback()
+}
+
+func (tx *Tx) Commit() error {
+	tx.closeResources()
+	return tx.MdbxTx.Commit()
+}
+
+func (tx *Tx) trackCloser(it any) {
+	if closer, ok := it.(kv.Closer); ok {
+		tx.resourcesToClose = append(tx.resourcesToClose, closer)
+	}
+}
+
+func (tx *Tx) DomainGet(name kv.Domain, k, k2 []byte) (v []byte, ok bool, err error) {
+	switch name {
+	case AccountsDomain:
+		v, err = tx.GetOne(kv.PlainState, k)
+	case StorageDomain:
+		v, err = tx.GetOne(kv.PlainState, append(append(make([]byte, 0, len(k)+len(k2)), k...), k2...))
+	case CodeDomain:
+		v, err = tx.GetOne(kv.Code, k2)
+	default:
+		return nil, false, fmt.Errorf("unexpected domain: %s", name)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return v, v != nil, nil
+}
+
+func (tx *Tx) DomainGetAsOf(name kv.Domain, k, k2 []byte, ts uint64) (v []byte, ok bool, err error) {
+	switch name {
+	case AccountsDomain:
+		v, ok, err = tx.HistoryGet(AccountsHistory, k, ts)
+	case StorageDomain:
+		if len(k) < addrLen {
+			return nil, false, fmt.Errorf("DomainGetAsOf(%s): expected address+incarnation, got %x", name, k)
+		}
+		v, ok, err = tx.HistoryGet(StorageHistory, append(append(make([]byte, 0, addrLen+len(k2)), k[:addrLen]...), k2...), ts)
+	case CodeDomain:
+		v, ok, err = tx.HistoryGet(CodeHistory, k, ts)
+	default:
+		return nil, false, fmt.Errorf("unexpected domain: %s", name)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if ok { // key was changed after ts
+		return v, len(v) > 0, nil
+	}
+	return tx.DomainGet(name, k, k2)
+}
+
+func (tx *Tx) HistoryGet(name kv.History, k []byte, ts uint64) (v []byte, ok bool, err error) {
+	switch name {
+	case AccountsHistory:
+		v, ok, err = tx.aggCtx.ReadAccountDataNoStateWithRecent(k, ts, tx.MdbxTx)
+		if err != nil || !ok || len(v) == 0 || tx.db.convertV3toV2 == nil {
+			return v, ok, err
+		}
+		v, err = tx.db.convertV3toV2(v)
+		if err != nil {
+			return nil, false, err
+		}
+		return v, true, nil
+	case StorageHistory:
+		return tx.aggCtx.ReadAccountStorageNoStateWithRecent2(k, ts, tx.MdbxTx)
+	case CodeHistory:
+		return tx.aggCtx.ReadAccountCodeNoStateWithRecent(k, ts, tx.MdbxTx)
+	default:
+		return nil, false, fmt.Errorf("unexpected history name: %s", name)
+	}
+}
+
+// IndexRange - order.Desc is supported only by LogTopicIdx, LogAddrIdx, TracesFromIdx, TracesToIdx
+func (tx *Tx) IndexRange(name kv.InvertedIdx, k []byte, fromTs, toTs int, asc order.By, limit int) (timestamps iter.U64, err error) {
+	switch name {
+	case AccountsHistoryIdx, StorageHistoryIdx, CodeHistoryIdx:
+		if asc == order.Desc {
+			return nil, fmt.Errorf("IndexRange(%s): descending order is not supported", name)
+		}
+	}
+	switch name {
+	case AccountsHistoryIdx:
+		timestamps, err = tx.aggCtx.AccountHistoyIdxRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case StorageHistoryIdx:
+		timestamps, err = tx.aggCtx.StorageHistoyIdxRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case CodeHistoryIdx:
+		timestamps, err = tx.aggCtx.CodeHistoyIdxRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case LogTopicIdx:
+		timestamps, err = tx.aggCtx.LogTopicRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case LogAddrIdx:
+		timestamps, err = tx.aggCtx.LogAddrRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case TracesFromIdx:
+		timestamps, err = tx.aggCtx.TraceFromRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	case TracesToIdx:
+		timestamps, err = tx.aggCtx.TraceToRange(k, fromTs, toTs, asc, limit, tx.MdbxTx)
+	default:
+		return nil, fmt.Errorf("unexpected inverted index name: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tx.trackCloser(timestamps)
+	return timestamps, nil
+}
+
+// HistoryRange - keys changed in [fromTs, toTs) with their values before the first change. Only order.Asc is supported
+func (tx *Tx) HistoryRange(name kv.History, fromTs, toTs int, asc order.By, limit int) (it iter.KV, err error) {
+	if asc == order.Desc {
+		return nil, fmt.Errorf("HistoryRange(%s): descending order is not supported", name)
+	}
+	switch name {
+	case AccountsHistory:
+		it, err = tx.aggCtx.AccountHistoryRange(fromTs, toTs, asc, limit, tx.MdbxTx)
+		if err == nil && tx.db.convertV3toV2 != nil {
+			tx.trackCloser(it)
+			it = iter.TransformKV(it, func(k, v []byte) ([]byte, []byte, error) {
+				if len(v) == 0 {
+					return k, v, nil
+				}
+				v, err := tx.db.convertV3toV2(v)
+				return k, v, err
+			})
+		}
+	case StorageHistory:
+		it, err = tx.aggCtx.StorageHistoryRange(fromTs, toTs, asc, limit, tx.MdbxTx)
+	case CodeHistory:
+		it, err = tx.aggCtx.CodeHistoryRange(fromTs, toTs, asc, limit, tx.MdbxTx)
+	default:
+		return nil, fmt.Errorf("unexpected history name: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tx.trackCloser(it)
+	return it, nil
+}
+
+// DomainRange - state as of ts in [fromKey, toKey): historical values of keys changed after ts
+// and latest values of other keys. Keys which didn't exist at ts are skipped.
+// Keys of StorageDomain are address+location. CodeDomain and order.Desc are not supported.
+func (tx *Tx) DomainRange(name kv.Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (it iter.KV, err error) {
+	if asc == order.Desc {
+		return nil, fmt.Errorf("DomainRange(%s): descending order is not supported", name)
+	}
+	var histIt, latestIt iter.KV
+	switch name {
+	case AccountsDomain:
+		histIt = tx.aggCtx.AccountHistoricalStateRange(ts, fromKey, toKey, -1, tx.MdbxTx)
+		tx.trackCloser(histIt)
+		if tx.db.convertV3toV2 != nil {
+			histIt = iter.TransformKV(histIt, func(k, v []byte) ([]byte, []byte, error) {
+				if len(v) == 0 {
+					return k, v, nil
+				}
+				v, err := tx.db.convertV3toV2(v)
+				return k, v, err
+			})
+		}
+		if latestIt, err = tx.RangeAscend(kv.PlainState, fromKey, toKey, -1); err != nil {
+			return nil, err
+		}
+		latestIt = iter.FilterKV(latestIt, func(k, v []byte) bool { return len(k) == addrLen })
+	case StorageDomain:
+		histIt = tx.aggCtx.StorageHistoricalStateRange(ts, fromKey, toKey, -1, tx.MdbxTx)
+		tx.trackCloser(histIt)
+		if latestIt, err = tx.storageRange(fromKey, toKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("DomainRange(%s): not supported", name)
+	}
+	tx.trackCloser(latestIt)
+	it = iter.UnionKV(histIt, latestIt, -1) // history has priority
+	it = iter.FilterKV(it, func(k, v []byte) bool { return len(v) > 0 })
+	return limitKV(it, limit), nil
+}
+
+// storageRange - latest storage in [fromKey, toKey), keys of kv.PlainState are converted
+// from address+incarnation+location to address+location
+func (tx *Tx) storageRange(fromKey, toKey []byte) (iter.KV, error) {
+	var fromPrefix, toPrefix []byte
+	if len(fromKey) > 0 {
+		fromPrefix = fromKey[:minInt(len(fromKey), addrLen)]
+	}
+	if toKey != nil {
+		var ok bool
+		if toPrefix, ok = kv.NextSubtree(toKey[:minInt(len(toKey), addrLen)]); !ok {
+			toPrefix = nil
+		}
+	}
+	it, err := tx.RangeAscend(kv.PlainState, fromPrefix, toPrefix, -1)
+	if err != nil {
+		return nil, err
+	}
+	it = iter.FilterKV(it, func(k, v []byte) bool { return len(k) == addrLen+incarnationLen+locationLen })
+	it = iter.TransformKV(it, func(k, v []byte) ([]byte, []byte, error) {
+		key := make([]byte, addrLen+locationLen)
+		copy(key, k[:addrLen])
+		copy(key[addrLen:], k[addrLen+incarnationLen:])
+		return key, v, nil
+	})
+	return iter.FilterKV(it, func(k, v []byte) bool {
+		return bytes.Compare(k, fromKey) >= 0 && (toKey == nil || bytes.Compare(k, toKey) < 0)
+	}), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// limitKV - stops iteration after `limit` pairs, limit -1 means unlimited
+func limitKV(it iter.KV, limit int) iter.KV {
+	if limit < 0 {
+		return it
+	}
+	return &limitedKV{it: it, limit: limit}
+}
+
+type limitedKV struct {
+	it    iter.KV
+	limit int
+}
+
+func (l *limitedKV) HasNext() bool { return l.limit > 0 && l.it.HasNext() }
+func (l *limitedKV) Next() ([]byte, []byte, error) {
+	l.limit--
+	return l.it.Next()
+}
+func (l *limitedKV) Close() {
+	if closer, ok := l.it.(kv.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,29 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package temporal_test
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/temporal/temporaltest"
+)
+
+func TestTemporalDB(t *testing.T) {
+	db := temporaltest.NewTestDB(t)
+	temporaltest.WriteFixture(t, db)
+	temporaltest.RunConformance(t, db)
+}
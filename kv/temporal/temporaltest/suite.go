@@ -0,0 +1,337 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package temporaltest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/iter"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon-lib/kv/order"
+	"github.com/ledgerwatch/erigon-lib/kv/temporal"
+	"github.com/ledgerwatch/erigon-lib/state"
+)
+
+// NewTestDB - temporal.DB over in-memory MDBX and empty AggregatorV3
+func NewTestDB(tb testing.TB) *temporal.DB {
+	tb.Helper()
+	dir := tb.TempDir()
+	db := mdbx.NewMDBX(log.New()).InMem(filepath.Join(dir, "chaindata")).MustOpen()
+	tb.Cleanup(db.Close)
+	aggDir := filepath.Join(dir, "history")
+	if err := os.MkdirAll(aggDir, 0755); err != nil {
+		tb.Fatal(err)
+	}
+	agg, err := state.NewAggregatorV3(context.Background(), aggDir, filepath.Join(dir, "tmp"), 16, db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(agg.Close)
+	if err = agg.OpenFolder(); err != nil {
+		tb.Fatal(err)
+	}
+	tdb, err := temporal.New(db, agg, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return tdb
+}
+
+var (
+	addr1   = bytes.Repeat([]byte{1}, 20)
+	addr2   = bytes.Repeat([]byte{2}, 20)
+	addr3   = bytes.Repeat([]byte{3}, 20) // never existed
+	inc1    = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	loc1    = bytes.Repeat([]byte{0xa1}, 32)
+	loc2    = bytes.Repeat([]byte{0xa2}, 32)
+	topic1  = bytes.Repeat([]byte{0xf1}, 32)
+	hash1   = bytes.Repeat([]byte{0xc1}, 32)
+	code1   = []byte("code1")
+	a1v1    = []byte("a1v1")
+	a1v2    = []byte("a1v2")
+	a2v1    = []byte("a2v1")
+	l1v1    = []byte("l1v1")
+	l1v2    = []byte("l1v2")
+	l2v1    = []byte("l2v1")
+	addr1L1 = concat(addr1, loc1)
+	addr1L2 = concat(addr1, loc2)
+)
+
+func concat(parts ...[]byte) []byte {
+	var res []byte
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
+
+// WriteFixture - writes history of few accounts into db, RunConformance expects exactly this data.
+// History by txNum:
+//
+//	1: addr1 created, code of addr1 deployed, trace from addr1
+//	2: addr1.loc1 = l1v1, log of addr1 with topic1
+//	3: addr1 changed, trace to addr2
+//	4: addr1.loc1 = l1v2, addr1.loc2 = l2v1, log of addr1
+//	5: addr2 created, log of addr2
+func WriteFixture(tb testing.TB, db *temporal.DB) {
+	tb.Helper()
+	ctx, agg := context.Background(), db.Agg()
+	err := db.Update(ctx, func(tx kv.RwTx) error {
+		agg.SetTx(tx)
+		defer agg.StartWrites().FinishWrites()
+		steps := []func() error{
+			1: func() error {
+				if err := agg.AddAccountPrev(addr1, nil); err != nil {
+					return err
+				}
+				if err := agg.AddCodePrev(addr1, nil); err != nil {
+					return err
+				}
+				return agg.AddTraceFrom(addr1)
+			},
+			2: func() error {
+				if err := agg.AddStoragePrev(addr1, loc1, nil); err != nil {
+					return err
+				}
+				if err := agg.AddLogAddr(addr1); err != nil {
+					return err
+				}
+				return agg.AddLogTopic(topic1)
+			},
+			3: func() error {
+				if err := agg.AddAccountPrev(addr1, a1v1); err != nil {
+					return err
+				}
+				return agg.AddTraceTo(addr2)
+			},
+			4: func() error {
+				if err := agg.AddStoragePrev(addr1, loc1, l1v1); err != nil {
+					return err
+				}
+				if err := agg.AddStoragePrev(addr1, loc2, nil); err != nil {
+					return err
+				}
+				return agg.AddLogAddr(addr1)
+			},
+			5: func() error {
+				if err := agg.AddAccountPrev(addr2, nil); err != nil {
+					return err
+				}
+				return agg.AddLogAddr(addr2)
+			},
+		}
+		for txNum := 1; txNum < len(steps); txNum++ {
+			agg.SetTxNum(uint64(txNum))
+			if err := steps[txNum](); err != nil {
+				return err
+			}
+		}
+		if err := agg.Flush(ctx, tx); err != nil {
+			return err
+		}
+
+		latest := [][2][]byte{
+			{addr1, a1v2},
+			{addr2, a2v1},
+			{concat(addr1, inc1, loc1), l1v2},
+			{concat(addr1, inc1, loc2), l2v1},
+		}
+		for _, kv2 := range latest {
+			if err := tx.Put(kv.PlainState, kv2[0], kv2[1]); err != nil {
+				return err
+			}
+		}
+		return tx.Put(kv.Code, hash1, code1)
+	})
+	require.NoError(tb, err)
+}
+
+// RunConformance - checks that db serves data written by WriteFixture. All implementations
+// of kv.TemporalRoDB (local and remote) must pass it.
+func RunConformance(t *testing.T, db kv.TemporalRoDB) {
+	ctx := context.Background()
+	tx, err := db.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	t.Run("DomainGet", func(t *testing.T) {
+		checkGet := func(expect []byte) func(v []byte, ok bool, err error) {
+			return func(v []byte, ok bool, err error) {
+				t.Helper()
+				require.NoError(t, err)
+				require.Equal(t, expect != nil, ok)
+				require.Equal(t, expect, nilIfEmpty(v))
+			}
+		}
+		checkGet(a1v2)(tx.DomainGet(temporal.AccountsDomain, addr1, nil))
+		checkGet(nil)(tx.DomainGet(temporal.AccountsDomain, addr3, nil))
+		checkGet(l1v2)(tx.DomainGet(temporal.StorageDomain, concat(addr1, inc1), loc1))
+		checkGet(code1)(tx.DomainGet(temporal.CodeDomain, addr1, hash1))
+
+		_, _, err := tx.DomainGet("unknown", addr1, nil)
+		require.Error(t, err)
+	})
+	t.Run("DomainGetAsOf", func(t *testing.T) {
+		cases := []struct {
+			name   kv.Domain
+			k, k2  []byte
+			ts     uint64
+			expect []byte
+		}{
+			{temporal.AccountsDomain, addr1, nil, 1, nil},
+			{temporal.AccountsDomain, addr1, nil, 2, a1v1},
+			{temporal.AccountsDomain, addr1, nil, 3, a1v1},
+			{temporal.AccountsDomain, addr1, nil, 4, a1v2},
+			{temporal.AccountsDomain, addr2, nil, 5, nil},
+			{temporal.AccountsDomain, addr2, nil, 6, a2v1},
+			{temporal.AccountsDomain, addr3, nil, 1, nil},
+			{temporal.StorageDomain, concat(addr1, inc1), loc1, 2, nil},
+			{temporal.StorageDomain, concat(addr1, inc1), loc1, 3, l1v1},
+			{temporal.StorageDomain, concat(addr1, inc1), loc1, 4, l1v1},
+			{temporal.StorageDomain, concat(addr1, inc1), loc1, 5, l1v2},
+			{temporal.StorageDomain, concat(addr1, inc1), loc2, 4, nil},
+			{temporal.StorageDomain, concat(addr1, inc1), loc2, 5, l2v1},
+			{temporal.CodeDomain, addr1, hash1, 1, nil},
+			{temporal.CodeDomain, addr1, hash1, 2, code1},
+		}
+		for _, c := range cases {
+			v, ok, err := tx.DomainGetAsOf(c.name, c.k, c.k2, c.ts)
+			require.NoError(t, err)
+			require.Equal(t, c.expect != nil, ok, "%s %x %x %d", c.name, c.k, c.k2, c.ts)
+			require.Equal(t, c.expect, nilIfEmpty(v), "%s %x %x %d", c.name, c.k, c.k2, c.ts)
+		}
+	})
+	t.Run("HistoryGet", func(t *testing.T) {
+		v, ok, err := tx.HistoryGet(temporal.AccountsHistory, addr1, 2)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, a1v1, v)
+
+		v, ok, err = tx.HistoryGet(temporal.AccountsHistory, addr1, 1) // didn't exist before txNum 1
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Empty(t, v)
+
+		_, ok, err = tx.HistoryGet(temporal.AccountsHistory, addr1, 4) // no changes since txNum 4
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		v, ok, err = tx.HistoryGet(temporal.StorageHistory, addr1L1, 3)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, l1v1, v)
+	})
+	t.Run("IndexRange", func(t *testing.T) {
+		cases := []struct {
+			name         kv.InvertedIdx
+			k            []byte
+			from, to     int
+			asc          order.By
+			limit        int
+			expectTxNums []uint64
+		}{
+			{temporal.AccountsHistoryIdx, addr1, 0, 10, order.Asc, -1, []uint64{1, 3}},
+			{temporal.AccountsHistoryIdx, addr1, 2, 10, order.Asc, -1, []uint64{3}},
+			{temporal.AccountsHistoryIdx, addr1, 0, 10, order.Asc, 1, []uint64{1}},
+			{temporal.AccountsHistoryIdx, addr3, 0, 10, order.Asc, -1, nil},
+			{temporal.StorageHistoryIdx, addr1L1, 0, 10, order.Asc, -1, []uint64{2, 4}},
+			{temporal.CodeHistoryIdx, addr1, 0, 10, order.Asc, -1, []uint64{1}},
+			{temporal.LogAddrIdx, addr1, 0, 10, order.Asc, -1, []uint64{2, 4}},
+			{temporal.LogAddrIdx, addr1, -1, -1, order.Desc, -1, []uint64{4, 2}},
+			{temporal.LogAddrIdx, addr1, 10, 3, order.Desc, -1, []uint64{4}},
+			{temporal.LogAddrIdx, addr2, 0, 10, order.Asc, -1, []uint64{5}},
+			{temporal.LogTopicIdx, topic1, 0, 10, order.Asc, -1, []uint64{2}},
+			{temporal.TracesFromIdx, addr1, 0, 10, order.Asc, -1, []uint64{1}},
+			{temporal.TracesToIdx, addr2, 0, 10, order.Asc, -1, []uint64{3}},
+		}
+		for _, c := range cases {
+			it, err := tx.IndexRange(c.name, c.k, c.from, c.to, c.asc, c.limit)
+			require.NoError(t, err)
+			txNums, err := iter.ToArr[uint64](it)
+			require.NoError(t, err)
+			require.Equal(t, c.expectTxNums, nilIfEmptyU64(txNums), "%s %x [%d, %d) %t %d", c.name, c.k, c.from, c.to, c.asc, c.limit)
+		}
+	})
+	t.Run("HistoryRange", func(t *testing.T) {
+		it, err := tx.HistoryRange(temporal.AccountsHistory, 2, 10, order.Asc, -1)
+		require.NoError(t, err)
+		keys, vals, err := iter.ToDualArray[[]byte, []byte](it)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{addr1, addr2}, keys)
+		require.Equal(t, a1v1, vals[0])
+		require.Empty(t, vals[1])
+
+		it, err = tx.HistoryRange(temporal.StorageHistory, 0, 10, order.Asc, 1)
+		require.NoError(t, err)
+		keys, _, err = iter.ToDualArray[[]byte, []byte](it)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{addr1L1}, keys)
+	})
+	t.Run("DomainRange", func(t *testing.T) {
+		domainRange := func(name kv.Domain, ts uint64, limit int) (keys, vals [][]byte) {
+			t.Helper()
+			it, err := tx.DomainRange(name, nil, nil, ts, order.Asc, limit)
+			require.NoError(t, err)
+			keys, vals, err = iter.ToDualArray[[]byte, []byte](it)
+			require.NoError(t, err)
+			return keys, vals
+		}
+		keys, vals := domainRange(temporal.AccountsDomain, 2, -1)
+		require.Equal(t, [][]byte{addr1}, keys)
+		require.Equal(t, [][]byte{a1v1}, vals)
+		keys, vals = domainRange(temporal.AccountsDomain, 6, -1)
+		require.Equal(t, [][]byte{addr1, addr2}, keys)
+		require.Equal(t, [][]byte{a1v2, a2v1}, vals)
+		keys, _ = domainRange(temporal.AccountsDomain, 6, 1)
+		require.Equal(t, [][]byte{addr1}, keys)
+
+		keys, vals = domainRange(temporal.StorageDomain, 3, -1)
+		require.Equal(t, [][]byte{addr1L1}, keys)
+		require.Equal(t, [][]byte{l1v1}, vals)
+		keys, vals = domainRange(temporal.StorageDomain, 5, -1)
+		require.Equal(t, [][]byte{addr1L1, addr1L2}, keys)
+		require.Equal(t, [][]byte{l1v2, l2v1}, vals)
+
+		it, err := tx.DomainRange(temporal.AccountsDomain, addr2, nil, 6, order.Asc, -1)
+		require.NoError(t, err)
+		keys, _, err = iter.ToDualArray[[]byte, []byte](it)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{addr2}, keys)
+	})
+}
+
+func nilIfEmpty(v []byte) []byte {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func nilIfEmptyU64(v []uint64) []uint64 {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
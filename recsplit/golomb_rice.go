@@ -82,6 +82,17 @@ func (g *GolombRice) appendFixed(v uint64, log2golomb int) {
 	g.bitCount += log2golomb
 }
 
+// appendBits adds all bits of other encoding to the end of the current encoding
+func (g *GolombRice) appendBits(other *GolombRice) {
+	for i := 0; i < other.bitCount; i += 64 {
+		n := other.bitCount - i
+		if n > 64 {
+			n = 64
+		}
+		g.appendFixed(other.data[i/64], n)
+	}
+}
+
 // Bits returns currrent number of bits in the compact encoding of the hash function representation
 func (g *GolombRice) Bits() int {
 	return g.bitCount
@@ -37,6 +37,7 @@ import (
 // Index implements index lookup from the file created by the RecSplit
 type Index struct {
 	offsetEf           *eliasfano32.EliasFano
+	filter             *xorFilter // optional membership filter, see RecSplitArgs.MembershipFilter
 	f                  *os.File
 	mmapHandle2        *[mmap.MaxMapSize]byte // mmap handle for windows (this is used to close mmap)
 	filePath, fileName string
@@ -158,6 +159,14 @@ func OpenIndex(indexFilePath string) (*Index, error) {
 	offset += 8 * int(l)
 	idx.ef.Read(idx.data[offset:])
 
+	if idx.filter, err = readFilterFile(FilterFilePath(indexFilePath), idx.salt, idx.keyCount); err != nil {
+		// filter is optional: stale or broken filter must not make index unusable
+		if !os.IsNotExist(err) {
+			log.Warn("[recsplit] can't use membership filter, ignoring it", "file", idx.fileName, "err", err)
+		}
+		idx.filter = nil
+	}
+
 	idx.readers = &sync.Pool{
 		New: func() interface{} {
 			return NewIndexReader(idx)
@@ -208,6 +217,21 @@ func (idx *Index) KeyCount() uint64 {
 	return idx.keyCount
 }
 
+// HasFilter - whether index was opened together with membership filter file
+func (idx *Index) HasFilter() bool { return idx.filter != nil }
+
+// Has returns false if key with given hash is definitely not in the index. Without membership filter
+// it can't know it, so returns true for any key of non-empty index.
+func (idx *Index) Has(bucketHash, fingerprint uint64) bool {
+	if idx.keyCount == 0 {
+		return false
+	}
+	if idx.filter == nil {
+		return true
+	}
+	return idx.filter.contains(filterKey(bucketHash, fingerprint))
+}
+
 // Lookup is not thread-safe because it used id.hasher
 func (idx *Index) Lookup(bucketHash, fingerprint uint64) uint64 {
 	if idx.keyCount == 0 {
@@ -71,6 +71,22 @@ func (r *IndexReader) Lookup2(key1, key2 []byte) uint64 {
 	return 0
 }
 
+// Has - false if key is definitely not in the index (see Index.Has)
+func (r *IndexReader) Has(key []byte) bool {
+	bucketHash, fingerprint := r.sum(key)
+	return r.index.Has(bucketHash, fingerprint)
+}
+
+// TryLookup - like Lookup, but reports absent keys instead of returning offset of some other key.
+// Reliable only if index has membership filter (see Index.HasFilter), false-positive rate is ~1/65536.
+func (r *IndexReader) TryLookup(key []byte) (uint64, bool) {
+	bucketHash, fingerprint := r.sum(key)
+	if !r.index.Has(bucketHash, fingerprint) {
+		return 0, false
+	}
+	return r.index.Lookup(bucketHash, fingerprint), true
+}
+
 func (r *IndexReader) Empty() bool {
 	return r.index.Empty()
 }
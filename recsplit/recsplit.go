@@ -20,12 +20,15 @@ import (
 	"bufio"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/bits"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon-lib/common/assert"
@@ -74,12 +77,14 @@ type RecSplit struct {
 	bucketPosAcc      []uint64   // Accumulator for position of every bucket in the encoding of the hash function
 	startSeed         []uint64
 	count             []uint16
-	currentBucket     []uint64 // 64-bit fingerprints of keys in the current bucket accumulated before the recsplit is performed for that bucket
-	currentBucketOffs []uint64 // Index offsets for the current bucket
-	offsetBuffer      []uint64
-	buffer            []uint64
-	golombRice        []uint32
-	bucketSizeAcc     []uint64 // Bucket size accumulator
+	currentBucket     []uint64          // 64-bit fingerprints of keys in the current bucket accumulated before the recsplit is performed for that bucket
+	currentBucketOffs []uint64          // Index offsets for the current bucket
+	bucketSizeAcc     []uint64          // Bucket size accumulator
+	splitters         []*bucketSplitter // One per worker
+	jobs              []bucketJob       // Buckets waiting to be split by workers (first pendingJobs of them)
+	pendingJobs       int
+	golombRiceLen     int // Number of Golomb-Rice params to store: largest split bucket size + 1
+	workers           int
 	// Helper object to encode the sequence of cumulative number of keys in the buckets
 	// and the sequence of of cumulative bit offsets of buckets in the Golomb-Rice code.
 	ef                 eliasfano16.DoubleEliasFano
@@ -103,6 +108,8 @@ type RecSplit struct {
 	numBuf             [8]byte
 	collision          bool
 	enums              bool // Whether to build two level index with perfect hash table pointing to enumeration and enumeration pointing to offsets
+	filter             bool // Whether to build membership filter file alongside the index
+	filterKeys         []uint64
 	built              bool // Flag indicating that the hash function has been built and no more keys can be added
 	trace              bool
 }
@@ -122,6 +129,11 @@ type RecSplitArgs struct {
 	EtlBufLimit datasize.ByteSize
 	Salt        uint32 // Hash seed (salt) for the hash function used for allocating the initial buckets - need to be generated randomly
 	LeafSize    uint16
+	Workers     int // Number of goroutines splitting buckets, index file doesn't depend on it
+
+	// Whether membership filter needs to be built (written to FilterFilePath(IndexFile)), it allows IndexReader.Has
+	// to report absent keys. Filter building keeps 8 bytes per key in RAM, filter file takes ~2.5 bytes per key
+	MembershipFilter bool
 }
 
 // NewRecSplit creates a new RecSplit instance with given number of keys and given bucket size
@@ -157,6 +169,7 @@ func NewRecSplit(args RecSplitArgs) (*RecSplit, error) {
 	rs.bucketCollector = etl.NewCollector(RecSplitLogPrefix+" "+fname, rs.tmpDir, etl.NewSortableBuffer(rs.etlBufLimit))
 	rs.bucketCollector.LogLvl(log.LvlDebug)
 	rs.enums = args.Enums
+	rs.filter = args.MembershipFilter
 	if args.Enums {
 		rs.offsetCollector = etl.NewCollector(RecSplitLogPrefix+" "+fname, rs.tmpDir, etl.NewSortableBuffer(rs.etlBufLimit))
 		rs.offsetCollector.LogLvl(log.LvlDebug)
@@ -177,7 +190,15 @@ func NewRecSplit(args RecSplitArgs) (*RecSplit, error) {
 		rs.secondaryAggrBound = rs.primaryAggrBound * uint16(math.Ceil(0.21*float64(rs.leafSize)+9./10.))
 	}
 	rs.startSeed = args.StartSeed
-	rs.count = make([]uint16, rs.secondaryAggrBound)
+	rs.workers = args.Workers
+	if rs.workers < 1 {
+		rs.workers = 1
+	}
+	rs.splitters = make([]*bucketSplitter, rs.workers)
+	for i := range rs.splitters {
+		rs.splitters[i] = rs.newBucketSplitter()
+	}
+	rs.jobs = make([]bucketJob, 1, rs.workers*bucketsPerWorker)
 	return rs, nil
 }
 
@@ -197,6 +218,9 @@ func (rs *RecSplit) LogLvl(lvl log.Lvl) { rs.lvl = lvl }
 
 func (rs *RecSplit) SetTrace(trace bool) {
 	rs.trace = trace
+	for _, s := range rs.splitters {
+		s.trace = trace
+	}
 }
 
 // remap converts the number x which is assumed to be uniformly distributed over the range [0..2^64) to the number that is uniformly
@@ -232,9 +256,11 @@ func (rs *RecSplit) ResetNextSalt() {
 	}
 	rs.currentBucket = rs.currentBucket[:0]
 	rs.currentBucketOffs = rs.currentBucketOffs[:0]
+	rs.pendingJobs = 0
 	rs.maxOffset = 0
 	rs.bucketSizeAcc = rs.bucketSizeAcc[:1] // First entry is always zero
 	rs.bucketPosAcc = rs.bucketPosAcc[:1]   // First entry is always zero
+	rs.filterKeys = rs.filterKeys[:0]
 }
 
 func splitParams(m, leafSize, primaryAggrBound, secondaryAggrBound uint16) (fanout, unit uint16) {
@@ -286,26 +312,6 @@ func computeGolombRice(m uint16, table []uint32, leafSize, primaryAggrBound, sec
 	table[m] |= nodes << 16
 }
 
-// golombParam returns the optimal Golomb parameter to use for encoding
-// salt for the part of the hash function separating m elements. It is based on
-// calculations with assumptions that we draw hash functions at random
-func (rs *RecSplit) golombParam(m uint16) int {
-	s := uint16(len(rs.golombRice))
-	for m >= s {
-		rs.golombRice = append(rs.golombRice, 0)
-		// For the case where bucket is larger than planned
-		if s == 0 {
-			rs.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
-		} else if s <= rs.leafSize {
-			rs.golombRice[s] = (bijMemo[s] << 27) | (uint32(1) << 16) | bijMemo[s]
-		} else {
-			computeGolombRice(s, rs.golombRice, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
-		}
-		s++
-	}
-	return int(rs.golombRice[m] >> 27)
-}
-
 // Add key to the RecSplit. There can be many more keys than what fits in RAM, and RecSplit
 // spills data onto disk to accomodate that. The key gets copied by the collector, therefore
 // the slice underlying key is not getting accessed by RecSplit after this invocation.
@@ -319,6 +325,9 @@ func (rs *RecSplit) AddKey(key []byte, offset uint64) error {
 	binary.BigEndian.PutUint64(rs.bucketKeyBuf[:], remap(hi, rs.bucketCount))
 	binary.BigEndian.PutUint64(rs.bucketKeyBuf[8:], lo)
 	binary.BigEndian.PutUint64(rs.numBuf[:], offset)
+	if rs.filter {
+		rs.filterKeys = append(rs.filterKeys, filterKey(hi, lo))
+	}
 	if offset > rs.maxOffset {
 		rs.maxOffset = offset
 	}
@@ -347,66 +356,97 @@ func (rs *RecSplit) AddKey(key []byte, offset uint64) error {
 	return nil
 }
 
-func (rs *RecSplit) recsplitCurrentBucket() error {
-	// Extend rs.bucketSizeAcc to accomodate current bucket index + 1
-	for len(rs.bucketSizeAcc) <= int(rs.currentBucketIdx)+1 {
-		rs.bucketSizeAcc = append(rs.bucketSizeAcc, rs.bucketSizeAcc[len(rs.bucketSizeAcc)-1])
+// bucketSplitter finds salts of recursive split of one bucket. Splitter doesn't share state with others,
+// so buckets can be split by many workers concurrently (see RecSplitArgs.Workers)
+type bucketSplitter struct {
+	startSeed          []uint64
+	golombRice         []uint32
+	buffer             []uint64
+	offsetBuffer       []uint64
+	count              []uint16
+	leafSize           uint16
+	primaryAggrBound   uint16
+	secondaryAggrBound uint16
+	trace              bool
+}
+
+// bucketJob - keys of one bucket and results of their split. Results are applied to RecSplit
+// in order of buckets, so index file doesn't depend on amount of workers.
+type bucketJob struct {
+	err       error
+	keys      []uint64   // 64-bit fingerprints of keys of the bucket
+	offsets   []uint64   // index offsets of keys (permuted by recsplit together with keys)
+	unary     []uint64   // unary part of golomb-rice encoding of the salts
+	out       []uint64   // offsets of keys in order of their records in the index file
+	gr        GolombRice // fixed part of golomb-rice encoding of the salts
+	bucketIdx uint64
+}
+
+func (rs *RecSplit) newBucketSplitter() *bucketSplitter {
+	return &bucketSplitter{
+		startSeed:          rs.startSeed,
+		count:              make([]uint16, rs.secondaryAggrBound),
+		leafSize:           rs.leafSize,
+		primaryAggrBound:   rs.primaryAggrBound,
+		secondaryAggrBound: rs.secondaryAggrBound,
+		trace:              rs.trace,
 	}
-	rs.bucketSizeAcc[int(rs.currentBucketIdx)+1] += uint64(len(rs.currentBucket))
-	// Sets of size 0 and 1 are not further processed, just write them to index
-	if len(rs.currentBucket) > 1 {
-		for i, key := range rs.currentBucket[1:] {
-			if key == rs.currentBucket[i] {
-				rs.collision = true
-				return fmt.Errorf("%w: %x", ErrCollision, key)
-			}
-		}
-		bitPos := rs.gr.bitCount
-		if rs.buffer == nil {
-			rs.buffer = make([]uint64, len(rs.currentBucket))
-			rs.offsetBuffer = make([]uint64, len(rs.currentBucketOffs))
+}
+
+// golombParam returns the optimal Golomb parameter to use for encoding
+// salt for the part of the hash function separating m elements. It is based on
+// calculations with assumptions that we draw hash functions at random
+func (s *bucketSplitter) golombParam(m uint16) int {
+	l := uint16(len(s.golombRice))
+	for m >= l {
+		s.golombRice = append(s.golombRice, 0)
+		// For the case where bucket is larger than planned
+		if l == 0 {
+			s.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
+		} else if l <= s.leafSize {
+			s.golombRice[l] = (bijMemo[l] << 27) | (uint32(1) << 16) | bijMemo[l]
 		} else {
-			for len(rs.buffer) < len(rs.currentBucket) {
-				rs.buffer = append(rs.buffer, 0)
-				rs.offsetBuffer = append(rs.offsetBuffer, 0)
-			}
+			computeGolombRice(l, s.golombRice, s.leafSize, s.primaryAggrBound, s.secondaryAggrBound)
 		}
-		unary, err := rs.recsplit(0 /* level */, rs.currentBucket, rs.currentBucketOffs, nil /* unary */)
-		if err != nil {
-			return err
-		}
-		rs.gr.appendUnaryAll(unary)
-		if rs.trace {
-			fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", rs.currentBucketIdx, len(rs.currentBucket), rs.gr.bitCount-bitPos)
-		}
-	} else {
-		for _, offset := range rs.currentBucketOffs {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return err
-			}
+		l++
+	}
+	return int(s.golombRice[m] >> 27)
+}
+
+// split - checks the bucket for collisions and applies recSplit algorithm to it, results are stored in the job
+func (s *bucketSplitter) split(job *bucketJob) {
+	job.err = nil
+	job.unary = job.unary[:0]
+	job.out = job.out[:0]
+	job.gr.data = job.gr.data[:0]
+	job.gr.bitCount = 0
+	// Sets of size 0 and 1 are not further processed, just write them to index
+	if len(job.keys) <= 1 {
+		job.out = append(job.out, job.offsets...)
+		return
+	}
+	for i, key := range job.keys[1:] {
+		if key == job.keys[i] {
+			job.err = fmt.Errorf("%w: %x", ErrCollision, key)
+			return
 		}
 	}
-	// Extend rs.bucketPosAcc to accomodate current bucket index + 1
-	for len(rs.bucketPosAcc) <= int(rs.currentBucketIdx)+1 {
-		rs.bucketPosAcc = append(rs.bucketPosAcc, rs.bucketPosAcc[len(rs.bucketPosAcc)-1])
+	for len(s.buffer) < len(job.keys) {
+		s.buffer = append(s.buffer, 0)
+		s.offsetBuffer = append(s.offsetBuffer, 0)
 	}
-	rs.bucketPosAcc[int(rs.currentBucketIdx)+1] = uint64(rs.gr.Bits())
-	// clear for the next buckey
-	rs.currentBucket = rs.currentBucket[:0]
-	rs.currentBucketOffs = rs.currentBucketOffs[:0]
-	return nil
+	s.recsplit(0 /* level */, job.keys, job.offsets, job)
 }
 
 // recsplit applies recSplit algorithm to the given bucket
-func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary []uint64) ([]uint64, error) {
-	if rs.trace {
+func (s *bucketSplitter) recsplit(level int, bucket []uint64, offsets []uint64, job *bucketJob) {
+	if s.trace {
 		fmt.Printf("recsplit(%d, %d, %x)\n", level, len(bucket), bucket)
 	}
 	// Pick initial salt for this level of recursive split
-	salt := rs.startSeed[level]
+	salt := s.startSeed[level]
 	m := uint16(len(bucket))
-	if m <= rs.leafSize {
+	if m <= s.leafSize {
 		// No need to build aggregation levels - just find find bijection
 		var mask uint32
 		for {
@@ -427,24 +467,19 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m)
-			rs.offsetBuffer[j] = offsets[i]
+			s.offsetBuffer[j] = offsets[i]
 		}
-		for _, offset := range rs.offsetBuffer[:m] {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
-			}
+		job.out = append(job.out, s.offsetBuffer[:m]...)
+		salt -= s.startSeed[level]
+		log2golomb := s.golombParam(m)
+		if s.trace {
+			fmt.Printf("encode bij %d with log2golomn %d at p = %d\n", salt, log2golomb, job.gr.bitCount)
 		}
-		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
-		if rs.trace {
-			fmt.Printf("encode bij %d with log2golomn %d at p = %d\n", salt, log2golomb, rs.gr.bitCount)
-		}
-		rs.gr.appendFixed(salt, log2golomb)
-		unary = append(unary, salt>>log2golomb)
+		job.gr.appendFixed(salt, log2golomb)
+		job.unary = append(job.unary, salt>>log2golomb)
 	} else {
-		fanout, unit := splitParams(m, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
-		count := rs.count
+		fanout, unit := splitParams(m, s.leafSize, s.primaryAggrBound, s.secondaryAggrBound)
+		count := s.count
 		for {
 			for i := uint16(0); i < fanout-1; i++ {
 				count[i] = 0
@@ -467,38 +502,122 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m) / unit
-			rs.buffer[count[j]] = bucket[i]
-			rs.offsetBuffer[count[j]] = offsets[i]
+			s.buffer[count[j]] = bucket[i]
+			s.offsetBuffer[count[j]] = offsets[i]
 			count[j]++
 		}
-		copy(bucket, rs.buffer)
-		copy(offsets, rs.offsetBuffer)
-		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
-		if rs.trace {
-			fmt.Printf("encode fanout %d: %d with log2golomn %d at p = %d\n", fanout, salt, log2golomb, rs.gr.bitCount)
+		copy(bucket, s.buffer)
+		copy(offsets, s.offsetBuffer)
+		salt -= s.startSeed[level]
+		log2golomb := s.golombParam(m)
+		if s.trace {
+			fmt.Printf("encode fanout %d: %d with log2golomn %d at p = %d\n", fanout, salt, log2golomb, job.gr.bitCount)
 		}
-		rs.gr.appendFixed(salt, log2golomb)
-		unary = append(unary, salt>>log2golomb)
-		var err error
+		job.gr.appendFixed(salt, log2golomb)
+		job.unary = append(job.unary, salt>>log2golomb)
 		var i uint16
 		for i = 0; i < m-unit; i += unit {
-			if unary, err = rs.recsplit(level+1, bucket[i:i+unit], offsets[i:i+unit], unary); err != nil {
-				return nil, err
-			}
+			s.recsplit(level+1, bucket[i:i+unit], offsets[i:i+unit], job)
 		}
 		if m-i > 1 {
-			if unary, err = rs.recsplit(level+1, bucket[i:], offsets[i:], unary); err != nil {
-				return nil, err
-			}
+			s.recsplit(level+1, bucket[i:], offsets[i:], job)
 		} else if m-i == 1 {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offsets[i])
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
+			job.out = append(job.out, offsets[i])
+		}
+	}
+}
+
+func (rs *RecSplit) recsplitCurrentBucket() error {
+	if rs.workers <= 1 {
+		job := &rs.jobs[0]
+		job.bucketIdx, job.keys, job.offsets = rs.currentBucketIdx, rs.currentBucket, rs.currentBucketOffs
+		rs.splitters[0].split(job)
+		err := rs.applyBucket(job)
+		// clear for the next bucket
+		rs.currentBucket = rs.currentBucket[:0]
+		rs.currentBucketOffs = rs.currentBucketOffs[:0]
+		return err
+	}
+	if rs.pendingJobs == len(rs.jobs) {
+		rs.jobs = append(rs.jobs, bucketJob{})
+	}
+	job := &rs.jobs[rs.pendingJobs]
+	rs.pendingJobs++
+	job.bucketIdx = rs.currentBucketIdx
+	job.keys = append(job.keys[:0], rs.currentBucket...)
+	job.offsets = append(job.offsets[:0], rs.currentBucketOffs...)
+	rs.currentBucket = rs.currentBucket[:0]
+	rs.currentBucketOffs = rs.currentBucketOffs[:0]
+	if rs.pendingJobs == rs.workers*bucketsPerWorker {
+		return rs.splitPendingBuckets()
+	}
+	return nil
+}
+
+// bucketsPerWorker - how many buckets are accumulated per worker before they are split in parallel
+const bucketsPerWorker = 256
+
+// splitPendingBuckets - splits accumulated buckets by all workers, then applies results in order of buckets
+func (rs *RecSplit) splitPendingBuckets() error {
+	jobs := rs.jobs[:rs.pendingJobs]
+	rs.pendingJobs = 0
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for _, s := range rs.splitters {
+		wg.Add(1)
+		go func(s *bucketSplitter) {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(jobs); i = int(next.Add(1) - 1) {
+				s.split(&jobs[i])
 			}
+		}(s)
+	}
+	wg.Wait()
+	for i := range jobs {
+		if err := rs.applyBucket(&jobs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBucket - appends results of bucket split to the hash function encoding and to the index file
+func (rs *RecSplit) applyBucket(job *bucketJob) error {
+	// Extend rs.bucketSizeAcc to accomodate current bucket index + 1
+	for len(rs.bucketSizeAcc) <= int(job.bucketIdx)+1 {
+		rs.bucketSizeAcc = append(rs.bucketSizeAcc, rs.bucketSizeAcc[len(rs.bucketSizeAcc)-1])
+	}
+	rs.bucketSizeAcc[int(job.bucketIdx)+1] += uint64(len(job.keys))
+	if job.err != nil {
+		if errors.Is(job.err, ErrCollision) {
+			rs.collision = true
 		}
+		return job.err
 	}
-	return unary, nil
+	if len(job.keys) > 1 {
+		bitPos := rs.gr.bitCount
+		rs.gr.appendBits(&job.gr)
+		rs.gr.appendUnaryAll(job.unary)
+		// Golomb-Rice params are stored for all sizes up to the largest split bucket
+		if len(job.keys) >= rs.golombRiceLen {
+			rs.golombRiceLen = len(job.keys) + 1
+		}
+		if rs.trace {
+			fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", job.bucketIdx, len(job.keys), rs.gr.bitCount-bitPos)
+		}
+	}
+	for _, offset := range job.out {
+		binary.BigEndian.PutUint64(rs.numBuf[:], offset)
+		if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
+			return err
+		}
+	}
+	// Extend rs.bucketPosAcc to accomodate current bucket index + 1
+	for len(rs.bucketPosAcc) <= int(job.bucketIdx)+1 {
+		rs.bucketPosAcc = append(rs.bucketPosAcc, rs.bucketPosAcc[len(rs.bucketPosAcc)-1])
+	}
+	rs.bucketPosAcc[int(job.bucketIdx)+1] = uint64(rs.gr.Bits())
+	return nil
 }
 
 // loadFuncBucket is required to satisfy the type etl.LoadFunc type, to use with collector.Load
@@ -573,6 +692,11 @@ func (rs *RecSplit) Build() error {
 			return err
 		}
 	}
+	if rs.pendingJobs > 0 {
+		if err := rs.splitPendingBuckets(); err != nil {
+			return err
+		}
+	}
 
 	if assert.Enable {
 		rs.indexW.Flush()
@@ -643,7 +767,7 @@ func (rs *RecSplit) Build() error {
 		}
 	}
 	// Write out the size of golomb rice params
-	binary.BigEndian.PutUint16(rs.numBuf[:], uint16(len(rs.golombRice)))
+	binary.BigEndian.PutUint16(rs.numBuf[:], uint16(rs.golombRiceLen))
 	if _, err := rs.indexW.Write(rs.numBuf[:4]); err != nil {
 		return fmt.Errorf("writing golomb rice param size: %w", err)
 	}
@@ -659,6 +783,21 @@ func (rs *RecSplit) Build() error {
 	_ = rs.indexW.Flush()
 	_ = rs.indexF.Sync()
 	_ = rs.indexF.Close()
+
+	// Filter file is renamed before index file: index must never be visible with stale filter (built with other salt)
+	filterFile := FilterFilePath(rs.indexFile)
+	if rs.filter {
+		filter, err := buildXorFilter(rs.filterKeys)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rs.indexFileName, err)
+		}
+		rs.filterKeys = nil
+		if err = writeFilterFile(filterFile, filter, rs.salt, rs.keysAdded); err != nil {
+			return err
+		}
+	} else if err := os.Remove(filterFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove stale filter file: %w", err)
+	}
 	_ = os.Rename(tmpIdxFilePath, rs.indexFile)
 	return nil
 }
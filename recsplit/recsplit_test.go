@@ -17,9 +17,14 @@
 package recsplit
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRecSplit2(t *testing.T) {
@@ -165,3 +170,142 @@ func TestTwoLayerIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexMembershipFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	indexFile := filepath.Join(tmpDir, "index.idx")
+	build := func(filter bool) {
+		rs, err := NewRecSplit(RecSplitArgs{
+			KeyCount:         10_000,
+			BucketSize:       100,
+			Salt:             0,
+			TmpDir:           tmpDir,
+			IndexFile:        indexFile,
+			LeafSize:         8,
+			MembershipFilter: filter,
+		})
+		require.NoError(t, err)
+		defer rs.Close()
+		for i := 0; i < 10_000; i++ {
+			require.NoError(t, rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)))
+		}
+		require.NoError(t, rs.Build())
+	}
+
+	build(true)
+	require.FileExists(t, filepath.Join(tmpDir, "index.xf"))
+	idx := MustOpen(indexFile)
+	defer idx.Close()
+	require.True(t, idx.HasFilter())
+	reader := NewIndexReader(idx)
+	for i := 0; i < 10_000; i++ {
+		offset, ok := reader.TryLookup([]byte(fmt.Sprintf("key %d", i)))
+		require.True(t, ok)
+		require.Equal(t, uint64(i*17), offset)
+	}
+	var falsePositives int
+	for i := 0; i < 100_000; i++ {
+		if reader.Has([]byte(fmt.Sprintf("absent key %d", i))) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 20) // expected ~1.5
+
+	// rebuild without filter must not leave stale filter file
+	build(false)
+	_, err := os.Stat(filepath.Join(tmpDir, "index.xf"))
+	require.True(t, os.IsNotExist(err))
+	idx2 := MustOpen(indexFile)
+	defer idx2.Close()
+	require.False(t, idx2.HasFilter())
+	require.True(t, NewIndexReader(idx2).Has([]byte("absent key")))
+
+	// stale filter file must be ignored
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "index.xf"), []byte("stale"), 0644))
+	idx3, err := OpenIndex(indexFile)
+	require.NoError(t, err)
+	defer idx3.Close()
+	require.False(t, idx3.HasFilter())
+	offset, ok := NewIndexReader(idx3).TryLookup([]byte("key 1"))
+	require.True(t, ok)
+	require.Equal(t, uint64(17), offset)
+}
+
+func TestXorFilterDuplicateKeys(t *testing.T) {
+	keys := []uint64{1, 2, 3, 2, 1, 100}
+	f, err := buildXorFilter(keys)
+	require.NoError(t, err)
+	for _, k := range []uint64{1, 2, 3, 100} {
+		require.True(t, f.contains(k))
+	}
+	f, err = buildXorFilter(nil)
+	require.NoError(t, err)
+	require.False(t, f.contains(1))
+}
+
+func newRecSplitWithWorkers(tb testing.TB, tmpDir string, keys int, enums bool, workers int) (*RecSplit, string) {
+	tb.Helper()
+	indexFile := filepath.Join(tmpDir, fmt.Sprintf("index_%t_%d", enums, workers))
+	rs, err := NewRecSplit(RecSplitArgs{
+		KeyCount:   keys,
+		BucketSize: 2000,
+		Salt:       1,
+		TmpDir:     tmpDir,
+		IndexFile:  indexFile,
+		LeafSize:   8,
+		Enums:      enums,
+		Workers:    workers,
+	})
+	require.NoError(tb, err)
+	rs.LogLvl(log.LvlTrace)
+	for i := 0; i < keys; i++ {
+		require.NoError(tb, rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)))
+	}
+	return rs, indexFile
+}
+
+func TestRecSplitWorkers(t *testing.T) {
+	tmpDir := t.TempDir()
+	const keys = 100_000
+	for _, enums := range []bool{false, true} {
+		rs, indexFile := newRecSplitWithWorkers(t, tmpDir, keys, enums, 1)
+		require.NoError(t, rs.Build())
+		rs.Close()
+		expected, err := os.ReadFile(indexFile)
+		require.NoError(t, err)
+		for _, workers := range []int{2, 3, 8} {
+			rs, indexFile := newRecSplitWithWorkers(t, tmpDir, keys, enums, workers)
+			require.NoError(t, rs.Build())
+			rs.Close()
+			data, err := os.ReadFile(indexFile)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(expected, data), "enums=%t, workers=%d", enums, workers)
+
+			idx := MustOpen(indexFile)
+			reader := NewIndexReader(idx)
+			for i := 0; i < keys; i += 97 {
+				offset := reader.Lookup([]byte(fmt.Sprintf("key %d", i)))
+				if enums {
+					offset = idx.OrdinalLookup(offset)
+				}
+				require.Equal(t, uint64(i*17), offset)
+			}
+			idx.Close()
+		}
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			tmpDir := b.TempDir()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				rs, _ := newRecSplitWithWorkers(b, tmpDir, 1_000_000, false, workers)
+				b.StartTimer()
+				require.NoError(b, rs.Build())
+				rs.Close()
+			}
+		})
+	}
+}
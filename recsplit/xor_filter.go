@@ -0,0 +1,224 @@
+/*
+   Copyright 2023 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package recsplit
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Membership filter - optional companion file of index (see RecSplitArgs.MembershipFilter).
+// Perfect hash function maps any key (also absent one) to some record, so Lookup of absent key
+// returns offset of some other key. Filter allows to detect absent keys without reading data file.
+//
+// It's xor filter with 16-bit fingerprints: https://arxiv.org/abs/1912.08258 Thomas Mueller Graf, Daniel Lemire.
+// Xor Filters: Faster and Smaller Than Bloom and Cuckoo Filters. Journal of Experimental Algorithmics 25 (1), 2020.
+// It takes ~2.5 bytes per key and has false-positive probability ~1/65536.
+//
+// File format: salt(4), keyCount(8), seed(8), blockLength(4), fingerprints (2 bytes each, 3*blockLength of them).
+// Filter keys are derived from salted hash of keys, so filter is valid only with the index built with same salt.
+
+const (
+	filterFileExt       = ".xf"
+	filterHeaderSize    = 4 + 8 + 8 + 4
+	filterMaxIterations = 100
+)
+
+// FilterFilePath - path of membership filter file of given index file: "a.idx" -> "a.xf"
+func FilterFilePath(indexFilePath string) string {
+	return strings.TrimSuffix(indexFilePath, ".idx") + filterFileExt
+}
+
+// filterKey - mixes both halves of salted 128-bit hash of key
+func filterKey(bucketHash, fingerprint uint64) uint64 {
+	return remix(bucketHash) ^ fingerprint
+}
+
+type xorFilter struct {
+	fingerprints []byte // 2 bytes per fingerprint, BigEndian
+	seed         uint64
+	blockLength  uint32
+}
+
+func (f *xorFilter) hashes(hash uint64) (h0, h1, h2 uint32) {
+	h0 = reduce(uint32(hash), f.blockLength)
+	h1 = reduce(uint32(bits.RotateLeft64(hash, 21)), f.blockLength) + f.blockLength
+	h2 = reduce(uint32(bits.RotateLeft64(hash, 42)), f.blockLength) + 2*f.blockLength
+	return h0, h1, h2
+}
+
+func (f *xorFilter) fingerprint(i uint32) uint16 {
+	return binary.BigEndian.Uint16(f.fingerprints[2*i:])
+}
+
+func (f *xorFilter) contains(key uint64) bool {
+	hash := remix(key + f.seed)
+	h0, h1, h2 := f.hashes(hash)
+	return filterFingerprint(hash) == f.fingerprint(h0)^f.fingerprint(h1)^f.fingerprint(h2)
+}
+
+// reduce maps x uniformly to [0..n)
+func reduce(x, n uint32) uint32 { return uint32((uint64(x) * uint64(n)) >> 32) }
+
+func filterFingerprint(hash uint64) uint16 { return uint16(hash ^ (hash >> 32)) }
+
+type xorSet struct {
+	mask  uint64
+	count uint32
+}
+
+type keyIndex struct {
+	hash  uint64
+	index uint32
+}
+
+// buildXorFilter - keys slice is sorted and de-duplicated in place
+func buildXorFilter(keys []uint64) (*xorFilter, error) {
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	capacity := 32 + uint32(math.Ceil(1.23*float64(len(keys))))
+	capacity = capacity / 3 * 3
+	f := &xorFilter{blockLength: capacity / 3}
+	fingerprints := make([]uint16, capacity)
+
+	sets := make([]xorSet, capacity)
+	queue := make([]uint32, 0, capacity)
+	stack := make([]keyIndex, 0, len(keys))
+	seed := uint64(0x9E3779B97F4A7C15)
+	for iteration := 0; ; iteration++ {
+		if iteration == filterMaxIterations {
+			return nil, fmt.Errorf("building membership filter: failed after %d iterations", iteration)
+		}
+		f.seed = remix(seed + uint64(iteration))
+		for i := range sets {
+			sets[i] = xorSet{}
+		}
+		for _, key := range keys {
+			hash := remix(key + f.seed)
+			h0, h1, h2 := f.hashes(hash)
+			sets[h0].mask ^= hash
+			sets[h0].count++
+			sets[h1].mask ^= hash
+			sets[h1].count++
+			sets[h2].mask ^= hash
+			sets[h2].count++
+		}
+		queue = queue[:0]
+		for i := range sets {
+			if sets[i].count == 1 {
+				queue = append(queue, uint32(i))
+			}
+		}
+		// Peeling: slot used by single key is assigned to this key, then key is removed from other slots
+		stack = stack[:0]
+		for len(queue) > 0 {
+			i := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			if sets[i].count != 1 {
+				continue
+			}
+			hash := sets[i].mask
+			stack = append(stack, keyIndex{hash: hash, index: i})
+			h0, h1, h2 := f.hashes(hash)
+			for _, j := range [3]uint32{h0, h1, h2} {
+				sets[j].mask ^= hash
+				sets[j].count--
+				if sets[j].count == 1 {
+					queue = append(queue, j)
+				}
+			}
+		}
+		if len(stack) == len(keys) {
+			break
+		}
+	}
+	// Assign fingerprints in reverse order of peeling: slots of keys peeled later are already final
+	for k := len(stack) - 1; k >= 0; k-- {
+		ki := stack[k]
+		h0, h1, h2 := f.hashes(ki.hash)
+		fingerprints[ki.index] = filterFingerprint(ki.hash) ^ fingerprints[h0] ^ fingerprints[h1] ^ fingerprints[h2]
+	}
+	f.fingerprints = make([]byte, 2*capacity)
+	for i, fp := range fingerprints {
+		binary.BigEndian.PutUint16(f.fingerprints[2*i:], fp)
+	}
+	return f, nil
+}
+
+func writeFilterFile(fileName string, f *xorFilter, salt uint32, keyCount uint64) error {
+	tmpFileName := fileName + ".tmp"
+	defer os.Remove(tmpFileName)
+	file, err := os.Create(tmpFileName)
+	if err != nil {
+		return fmt.Errorf("create filter file %s: %w", fileName, err)
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	var header [filterHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], salt)
+	binary.BigEndian.PutUint64(header[4:], keyCount)
+	binary.BigEndian.PutUint64(header[12:], f.seed)
+	binary.BigEndian.PutUint32(header[20:], f.blockLength)
+	if _, err = w.Write(header[:]); err != nil {
+		return fmt.Errorf("writing filter header: %w", err)
+	}
+	if _, err = w.Write(f.fingerprints); err != nil {
+		return fmt.Errorf("writing filter fingerprints: %w", err)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFileName, fileName)
+}
+
+func readFilterFile(fileName string, salt uint32, keyCount uint64) (*xorFilter, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < filterHeaderSize {
+		return nil, fmt.Errorf("filter file %s is too short: %d", fileName, len(data))
+	}
+	if fileSalt := binary.BigEndian.Uint32(data); fileSalt != salt {
+		return nil, fmt.Errorf("filter file %s is built with salt %d, but index has salt %d", fileName, fileSalt, salt)
+	}
+	if fileKeyCount := binary.BigEndian.Uint64(data[4:]); fileKeyCount != keyCount {
+		return nil, fmt.Errorf("filter file %s is built for %d keys, but index has %d keys", fileName, fileKeyCount, keyCount)
+	}
+	f := &xorFilter{
+		seed:         binary.BigEndian.Uint64(data[12:]),
+		blockLength:  binary.BigEndian.Uint32(data[20:]),
+		fingerprints: data[filterHeaderSize:],
+	}
+	if len(f.fingerprints) != 6*int(f.blockLength) {
+		return nil, fmt.Errorf("filter file %s: expected %d bytes of fingerprints, got %d", fileName, 6*int(f.blockLength), len(f.fingerprints))
+	}
+	return f, nil
+}
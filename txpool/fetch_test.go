@@ -146,6 +146,46 @@ func TestSendTxPropagate(t *testing.T) {
 	})
 }
 
+// canonical form of blob tx (without blobs) as it is in block
+const blobTxInBlock = "03f885010301028252089411000000000000000000000000000000000000008080c005e1a0010000000000000000000000000000000000000000000000000000000000000001a09ea7e35f2e1d7404d083457df03c5af6673a891a8a03bd66fca4380aaaebcb37a057f589be33f88759acb62c249eb240b50afd1aff4c288f621f8ab735c9c30887"
+
+func TestOnNewBlockUnwindSkipsBlobTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	coreDB, db := memdb.NewTestDB(t), memdb.NewTestDB(t)
+
+	i := 0
+	stream := &remote.KV_StateChangesClientMock{
+		RecvFunc: func() (*remote.StateChangeBatch, error) {
+			if i > 0 {
+				return nil, io.EOF
+			}
+			i++
+			return &remote.StateChangeBatch{
+				StateVersionId: 1,
+				ChangeBatch: []*remote.StateChange{
+					{Direction: remote.Direction_UNWIND, Txs: [][]byte{decodeHex(types3.TxParseMainnetTests[0].PayloadStr), decodeHex(blobTxInBlock), decodeHex(types3.TxParseMainnetTests[1].PayloadStr)}, BlockHeight: 1, BlockHash: gointerfaces.ConvertHashToH256([32]byte{})},
+				},
+			}, nil
+		},
+	}
+	stateChanges := &remote.KVClientMock{
+		StateChangesFunc: func(ctx context.Context, in *remote.StateChangeRequest, opts ...grpc.CallOption) (remote.KV_StateChangesClient, error) {
+			return stream, nil
+		},
+	}
+	pool := &PoolMock{}
+	fetch := NewFetch(ctx, nil, pool, stateChanges, coreDB, db, *u256.N1)
+	err := fetch.handleStateChanges(ctx, stateChanges)
+	assert.ErrorIs(t, io.EOF, err)
+	assert.Equal(t, 1, len(pool.OnNewBlockCalls()))
+	unwindTxs := pool.OnNewBlockCalls()[0].UnwindTxs
+	assert.Equal(t, 2, len(unwindTxs.Txs))
+	for _, txn := range unwindTxs.Txs {
+		assert.NotEqual(t, types3.BlobTxType, txn.Type)
+	}
+}
+
 func decodeHex(in string) []byte {
 	payload, err := hex.DecodeString(in)
 	if err != nil {
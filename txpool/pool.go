@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"container/heap"
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
@@ -33,6 +34,7 @@ import (
 	"time"
 
 	"github.com/VictoriaMetrics/metrics"
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/go-stack/stack"
 	"github.com/google/btree"
@@ -49,6 +51,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
 	emath "github.com/ledgerwatch/erigon-lib/common/math"
 	"github.com/ledgerwatch/erigon-lib/common/u256"
+	"github.com/ledgerwatch/erigon-lib/crypto/kzg"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
@@ -70,6 +73,7 @@ var (
 	pendingSubCounter       = metrics.GetOrCreateCounter(`txpool_pending`)
 	queuedSubCounter        = metrics.GetOrCreateCounter(`txpool_queued`)
 	basefeeSubCounter       = metrics.GetOrCreateCounter(`txpool_basefee`)
+	blobSubCounter          = metrics.GetOrCreateCounter(`txpool_blob`)
 )
 
 // Pool is interface for the transaction pool
@@ -139,6 +143,13 @@ const (
 	NotReplaced         DiscardReason = 20 // There was an existing transaction with the same sender and nonce, not enough price bump to replace
 	DuplicateHash       DiscardReason = 21 // There was an existing transaction with the same hash
 	InitCodeTooLarge    DiscardReason = 22 // EIP-3860 - transaction init code is too large
+	BlobPoolOverflow    DiscardReason = 23
+	NoBlobs             DiscardReason = 24 // EIP-4844 - blob transaction must carry at least one blob
+	TooManyBlobs        DiscardReason = 25 // EIP-4844 - blob transaction carries more blobs than fit into a block
+	BlobHashMismatch    DiscardReason = 26 // EIP-4844 - versioned hashes don't match KZG commitments of the network wrapper
+	BlobsLimitReached   DiscardReason = 27 // total amount of blobs in the pool reached cfg.TotalBlobPoolLimit
+	NoBlobSidecar       DiscardReason = 28 // EIP-4844 - blob transaction came without blobs, commitments and proofs of network wrapper
+	InvalidBlobProof    DiscardReason = 29 // EIP-4844 - KZG proofs of blobs failed verification
 )
 
 func (r DiscardReason) String() string {
@@ -189,6 +200,20 @@ func (r DiscardReason) String() string {
 		return "existing tx with same hash"
 	case InitCodeTooLarge:
 		return "initcode too large"
+	case BlobPoolOverflow:
+		return "blob sub-pool is full"
+	case NoBlobs:
+		return "blob transaction without blobs"
+	case TooManyBlobs:
+		return "too many blobs"
+	case BlobHashMismatch:
+		return "blob versioned hashes don't match commitments"
+	case BlobsLimitReached:
+		return "blobs limit in txpool is full"
+	case NoBlobSidecar:
+		return "blob transaction without blobs sidecar"
+	case InvalidBlobProof:
+		return "invalid blob KZG proof"
 	default:
 		panic(fmt.Sprintf("discard reason: %d", r))
 	}
@@ -198,8 +223,9 @@ func (r DiscardReason) String() string {
 type metaTx struct {
 	Tx                        *types.TxSlot
 	minFeeCap                 uint256.Int
-	nonceDistance             uint64 // how far their nonces are from the state's nonce for the sender
-	cumulativeBalanceDistance uint64 // how far their cumulativeRequiredBalance are from the state's balance for the sender
+	minBlobFeeCap             uint256.Int // minimal blob fee cap of this and all prior blob transactions of the sender
+	nonceDistance             uint64      // how far their nonces are from the state's nonce for the sender
+	cumulativeBalanceDistance uint64      // how far their cumulativeRequiredBalance are from the state's balance for the sender
 	minTip                    uint64
 	bestIndex                 int
 	worstIndex                int
@@ -222,6 +248,7 @@ type SubPoolType uint8
 const PendingSubPool SubPoolType = 1
 const BaseFeeSubPool SubPoolType = 2
 const QueuedSubPool SubPoolType = 3
+const BlobSubPool SubPoolType = 4
 
 func (sp SubPoolType) String() string {
 	switch sp {
@@ -231,6 +258,8 @@ func (sp SubPoolType) String() string {
 		return "BaseFee"
 	case QueuedSubPool:
 		return "Queued"
+	case BlobSubPool:
+		return "Blob"
 	}
 	return fmt.Sprintf("Unknown:%d", sp)
 }
@@ -284,6 +313,8 @@ type TxPool struct {
 	pending                 *PendingPool
 	baseFee                 *SubPool
 	queued                  *SubPool
+	blob                    *SubPool                         // transactions which qualify for pending sub-pool, but can't pay pendingBlobFee
+	totalBlobsInPool        uint64                           // amount of blobs carried by all transactions in the pool
 	isLocalLRU              *simplelru.LRU[string, struct{}] // tx_hash => is_local : to restore isLocal flag of unwinded transactions
 	newPendingTxs           chan types.Announcements         // notifications about new txs in Pending sub-pool
 	all                     *BySenderAndNonce                // senderID => (sorted map of tx nonce => *metaTx)
@@ -294,6 +325,7 @@ type TxPool struct {
 	lastSeenBlock           atomic.Uint64
 	started                 atomic.Bool
 	pendingBaseFee          atomic.Uint64
+	pendingBlobFee          atomic.Uint64
 	blockGasLimit           atomic.Uint64
 	shanghaiTime            *big.Int
 	isPostShanghai          atomic.Bool
@@ -319,7 +351,7 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 	for _, sender := range cfg.TracedSenders {
 		tracedSenders[common.BytesToAddress([]byte(sender))] = struct{}{}
 	}
-	return &TxPool{
+	p := &TxPool{
 		lock:                    &sync.Mutex{},
 		byHash:                  map[string]*metaTx{},
 		isLocalLRU:              localsHistory,
@@ -329,6 +361,7 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		pending:                 NewPendingSubPool(PendingSubPool, cfg.PendingSubPoolLimit),
 		baseFee:                 NewSubPool(BaseFeeSubPool, cfg.BaseFeeSubPoolLimit),
 		queued:                  NewSubPool(QueuedSubPool, cfg.QueuedSubPoolLimit),
+		blob:                    NewSubPool(BlobSubPool, cfg.BlobSubPoolLimit),
 		newPendingTxs:           newTxs,
 		_stateCache:             cache,
 		senders:                 newSendersCache(tracedSenders),
@@ -338,7 +371,9 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		unprocessedRemoteTxs:    &types.TxSlots{},
 		unprocessedRemoteByHash: map[string]int{},
 		shanghaiTime:            shanghaiTime,
-	}, nil
+	}
+	p.pendingBlobFee.Store(fixedgas.MinBlobGasPrice)
+	return p, nil
 }
 
 func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChangeBatch, unwindTxs, minedTxs types.TxSlots, tx kv.Tx) error {
@@ -387,9 +422,14 @@ func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChang
 		p.baseFee.worst.pendingBaseFee = pendingBaseFee
 		p.queued.best.pendingBastFee = pendingBaseFee
 		p.queued.worst.pendingBaseFee = pendingBaseFee
+		p.blob.best.pendingBastFee = pendingBaseFee
+		p.blob.worst.pendingBaseFee = pendingBaseFee
 	}
 
 	p.blockGasLimit.Store(stateChanges.BlockGasLimit)
+	if stateChanges.PendingBlobFeePerGas > 0 {
+		p.SetPendingBlobFee(stateChanges.PendingBlobFeePerGas)
+	}
 	if err := p.senders.onNewBlock(stateChanges, unwindTxs, minedTxs); err != nil {
 		return err
 	}
@@ -411,7 +451,7 @@ func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChang
 		}
 	}
 
-	if err := removeMined(p.all, minedTxs.Txs, p.pending, p.baseFee, p.queued, p.discardLocked); err != nil {
+	if err := removeMined(p.all, minedTxs.Txs, p.pending, p.baseFee, p.queued, p.blob, p.discardLocked); err != nil {
 		return err
 	}
 
@@ -419,14 +459,15 @@ func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChang
 
 	announcements, err := addTxsOnNewBlock(p.lastSeenBlock.Load(), cacheView, stateChanges, p.senders, unwindTxs,
 		pendingBaseFee, stateChanges.BlockGasLimit,
-		p.pending, p.baseFee, p.queued, p.all, p.byHash, p.addLocked, p.discardLocked)
+		p.pending, p.baseFee, p.queued, p.blob, p.all, p.byHash, p.addLocked, p.discardLocked)
 	if err != nil {
 		return err
 	}
 	p.pending.EnforceWorstInvariants()
 	p.baseFee.EnforceInvariants()
 	p.queued.EnforceInvariants()
-	promote(p.pending, p.baseFee, p.queued, pendingBaseFee, p.discardLocked, &announcements)
+	p.blob.EnforceInvariants()
+	promote(p.pending, p.baseFee, p.queued, p.blob, pendingBaseFee, p.pendingBlobFee.Load(), p.discardLocked, &announcements)
 	p.pending.EnforceBestInvariants()
 	p.promoted.Reset()
 	p.promoted.AppendOther(announcements)
@@ -483,7 +524,7 @@ func (p *TxPool) processRemoteTxs(ctx context.Context) error {
 	}
 
 	announcements, _, err := addTxs(p.lastSeenBlock.Load(), cacheView, p.senders, newTxs,
-		p.pendingBaseFee.Load(), p.blockGasLimit.Load(), p.pending, p.baseFee, p.queued, p.all, p.byHash, p.addLocked, p.discardLocked, true)
+		p.pendingBaseFee.Load(), p.pendingBlobFee.Load(), p.blockGasLimit.Load(), p.pending, p.baseFee, p.queued, p.blob, p.all, p.byHash, p.addLocked, p.discardLocked, true)
 	if err != nil {
 		return err
 	}
@@ -585,7 +626,7 @@ func (p *TxPool) IsLocal(idHash []byte) bool {
 func (p *TxPool) AddNewGoodPeer(peerID types.PeerID) { p.recentlyConnectedPeers.AddPeer(peerID) }
 func (p *TxPool) Started() bool                      { return p.started.Load() }
 
-func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas uint64, toSkip mapset.Set[[32]byte]) (bool, int, error) {
+func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas, availableBlobGas uint64, toSkip mapset.Set[[32]byte]) (bool, int, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -617,6 +658,11 @@ func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableG
 			// Skip transactions with very large gas limit
 			continue
 		}
+		// blob transactions must fit into the blob gas budget of the block, others may still fit
+		blobGas := uint64(len(mt.Tx.BlobHashes)) * fixedgas.BlobGasPerBlob
+		if blobGas > availableBlobGas {
+			continue
+		}
 		rlpTx, sender, isLocal, err := p.getRlpLocked(tx, mt.Tx.IDHash[:])
 		if err != nil {
 			return false, count, err
@@ -638,6 +684,7 @@ func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableG
 		if intrinsicGas <= availableGas { // check for potential underflow
 			availableGas -= intrinsicGas
 		}
+		availableBlobGas -= blobGas
 
 		txs.Txs[count] = rlpTx
 		copy(txs.Senders.At(count), sender.Bytes())
@@ -664,20 +711,31 @@ func (p *TxPool) ResetYieldedStatus() {
 	}
 }
 
-func (p *TxPool) YieldBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas uint64, toSkip mapset.Set[[32]byte]) (bool, int, error) {
-	return p.best(n, txs, tx, onTopOf, availableGas, toSkip)
+// YieldBest - returns up to n best pending transactions which fit into availableGas and availableBlobGas
+// (fixedgas.MaxBlobGasPerBlock for an empty block) budgets of the block built on top of onTopOf
+func (p *TxPool) YieldBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas, availableBlobGas uint64, toSkip mapset.Set[[32]byte]) (bool, int, error) {
+	return p.best(n, txs, tx, onTopOf, availableGas, availableBlobGas, toSkip)
 }
 
-func (p *TxPool) PeekBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas uint64) (bool, error) {
+func (p *TxPool) PeekBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas, availableBlobGas uint64) (bool, error) {
 	set := mapset.NewThreadUnsafeSet[[32]byte]()
-	onTime, _, err := p.best(n, txs, tx, onTopOf, availableGas, set)
+	onTime, _, err := p.best(n, txs, tx, onTopOf, availableGas, availableBlobGas, set)
 	return onTime, err
 }
 
-func (p *TxPool) CountContent() (int, int, int) {
+func (p *TxPool) CountContent() (int, int, int, int) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
-	return p.pending.Len(), p.baseFee.Len(), p.queued.Len()
+	return p.pending.Len(), p.baseFee.Len(), p.queued.Len(), p.blob.Len()
+}
+
+// SetPendingBlobFee - sets fee per blob gas of the pending block. OnNewBlock sets it from StateChangeBatch,
+// use it only if StateChangeBatch doesn't carry it (old server): before OnNewBlock, which will re-promote transactions.
+func (p *TxPool) SetPendingBlobFee(blobFee uint64) {
+	if blobFee < fixedgas.MinBlobGasPrice {
+		blobFee = fixedgas.MinBlobGasPrice
+	}
+	p.pendingBlobFee.Store(blobFee)
 }
 func (p *TxPool) AddRemoteTxs(_ context.Context, newTxs types.TxSlots) {
 	defer addRemoteTxsTimer.UpdateDuration(time.Now())
@@ -724,6 +782,14 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 		}
 		return IntrinsicGas
 	}
+	if txn.Type == types.BlobTxType {
+		if reason := p.validateBlobTx(txn); reason != Success {
+			if txn.Traced {
+				log.Info(fmt.Sprintf("TX TRACING: validateTx blob tx validation failed idHash=%x reason=%s", txn.IDHash, reason))
+			}
+			return reason
+		}
+	}
 	if !isLocal && uint64(p.all.count(txn.SenderID)) > p.cfg.AccountSlots {
 		if txn.Traced {
 			log.Info(fmt.Sprintf("TX TRACING: validateTx marked as spamming idHash=%x slots=%d, limit=%d", txn.IDHash, p.all.count(txn.SenderID), p.cfg.AccountSlots))
@@ -740,9 +806,7 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 		return NonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
-	total := uint256.NewInt(txn.Gas)
-	total.Mul(total, &txn.FeeCap)
-	total.Add(total, &txn.Value)
+	total := requiredBalance(txn)
 	if senderBalance.Cmp(total) < 0 {
 		if txn.Traced {
 			log.Info(fmt.Sprintf("TX TRACING: validateTx insufficient funds idHash=%x balance in state=%d, txn.gas*txn.tip=%d", txn.IDHash, senderBalance, total))
@@ -752,6 +816,77 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 	return Success
 }
 
+func (p *TxPool) validateBlobTx(txn *types.TxSlot) DiscardReason {
+	blobCount := uint64(len(txn.BlobHashes))
+	if blobCount == 0 {
+		return NoBlobs
+	}
+	if blobCount > fixedgas.MaxBlobsPerBlock {
+		return TooManyBlobs
+	}
+	// Blobs are not part of the block, so pool accepts blob txs only in network wrapper form
+	if len(txn.Blobs) != len(txn.BlobHashes) || len(txn.Commitments) != len(txn.BlobHashes) || len(txn.Proofs) != len(txn.BlobHashes) {
+		return NoBlobSidecar
+	}
+	// versioned hashes must be derived from KZG commitments
+	for i := range txn.Commitments {
+		if kzgToVersionedHash(txn.Commitments[i]) != txn.BlobHashes[i] {
+			return BlobHashMismatch
+		}
+	}
+	if txn.BlobFeeCap.Cmp(uint256.NewInt(fixedgas.MinBlobGasPrice)) < 0 {
+		return UnderPriced
+	}
+	if p.totalBlobsInPool+blobCount > p.cfg.TotalBlobPoolLimit {
+		return BlobsLimitReached
+	}
+	// proofs verification is the most expensive check - keep it last
+	if err := verifyBlobProofs(txn); err != nil {
+		if txn.Traced {
+			log.Info(fmt.Sprintf("TX TRACING: validateBlobTx invalid proofs idHash=%x err=%s", txn.IDHash, err))
+		}
+		return InvalidBlobProof
+	}
+	return Success
+}
+
+// verifyBlobProofs - checks KZG proofs of blobs against their commitments
+func verifyBlobProofs(txn *types.TxSlot) error {
+	kzgCtx, err := kzg.Ctx()
+	if err != nil {
+		return err
+	}
+	blobs := make([]gokzg4844.Blob, len(txn.Blobs))
+	commitments := make([]gokzg4844.KZGCommitment, len(txn.Commitments))
+	proofs := make([]gokzg4844.KZGProof, len(txn.Proofs))
+	for i := range txn.Blobs {
+		copy(blobs[i][:], txn.Blobs[i])
+		commitments[i] = gokzg4844.KZGCommitment(txn.Commitments[i])
+		proofs[i] = gokzg4844.KZGProof(txn.Proofs[i])
+	}
+	return kzgCtx.VerifyBlobKZGProofBatch(blobs, commitments, proofs)
+}
+
+// kzgToVersionedHash - versioned hash of blob is sha256 of its KZG commitment with first byte replaced by version
+func kzgToVersionedHash(commitment types.KZGCommitment) common.Hash {
+	h := sha256.Sum256(commitment[:])
+	h[0] = fixedgas.BlobVersionedHashKZG
+	return h
+}
+
+// requiredBalance - gasLimit x feeCap + transferred_value + blobGas x blobFeeCap
+func requiredBalance(txn *types.TxSlot) *uint256.Int {
+	total := uint256.NewInt(txn.Gas)
+	total.Mul(total, &txn.FeeCap)
+	total.Add(total, &txn.Value)
+	if blobCount := uint64(len(txn.BlobHashes)); blobCount > 0 {
+		blobFee := uint256.NewInt(blobCount * fixedgas.BlobGasPerBlob)
+		blobFee.Mul(blobFee, &txn.BlobFeeCap)
+		total.Add(total, blobFee)
+	}
+	return total
+}
+
 func (p *TxPool) isShanghai() bool {
 	// once this flag has been set for the first time we no longer need to check the timestamp
 	set := p.isPostShanghai.Load()
@@ -790,8 +925,16 @@ func (p *TxPool) ValidateSerializedTxn(serializedTxn []byte) error {
 		// more expensive to propagate; larger transactions also take more resources
 		// to validate whether they fit into the pool or not.
 		txMaxSize = 4 * txSlotSize // 128KB
+
+		// blobTxMaxSize is the maximum size of blob transaction in network wrapper form: blobs,
+		// commitments and proofs (with their RLP prefixes) are carried on top of the transaction itself.
+		blobTxMaxSize = txMaxSize + int(fixedgas.MaxBlobsPerBlock)*(fixedgas.BlobSize+2*types.LenKZG+3*4)
 	)
-	if len(serializedTxn) > txMaxSize {
+	maxSize := txMaxSize
+	if len(serializedTxn) > 0 && serializedTxn[0] == types.BlobTxType {
+		maxSize = blobTxMaxSize
+	}
+	if len(serializedTxn) > maxSize {
 		return types.ErrRlpTooBig
 	}
 	return nil
@@ -898,7 +1041,7 @@ func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots,
 	}
 
 	announcements, addReasons, err := addTxs(p.lastSeenBlock.Load(), cacheView, p.senders, newTxs,
-		p.pendingBaseFee.Load(), p.blockGasLimit.Load(), p.pending, p.baseFee, p.queued, p.all, p.byHash, p.addLocked, p.discardLocked, true)
+		p.pendingBaseFee.Load(), p.pendingBlobFee.Load(), p.blockGasLimit.Load(), p.pending, p.baseFee, p.queued, p.blob, p.all, p.byHash, p.addLocked, p.discardLocked, true)
 	if err == nil {
 		for i, reason := range addReasons {
 			if reason != NotSet {
@@ -943,8 +1086,8 @@ func (p *TxPool) cache() kvcache.Cache {
 }
 
 func addTxs(blockNum uint64, cacheView kvcache.CacheView, senders *sendersBatch,
-	newTxs types.TxSlots, pendingBaseFee, blockGasLimit uint64,
-	pending *PendingPool, baseFee, queued *SubPool,
+	newTxs types.TxSlots, pendingBaseFee, pendingBlobFee, blockGasLimit uint64,
+	pending *PendingPool, baseFee, queued, blob *SubPool,
 	byNonce *BySenderAndNonce, byHash map[string]*metaTx, add func(*metaTx, *types.Announcements) DiscardReason, discard func(*metaTx, DiscardReason), collect bool) (types.Announcements, []DiscardReason, error) {
 	protocolBaseFee := calcProtocolBaseFee(pendingBaseFee)
 	if assert.Enable {
@@ -993,17 +1136,17 @@ func addTxs(blockNum uint64, cacheView kvcache.CacheView, senders *sendersBatch,
 			return announcements, discardReasons, err
 		}
 		onSenderStateChange(senderID, nonce, balance, byNonce,
-			protocolBaseFee, blockGasLimit, pending, baseFee, queued, discard)
+			protocolBaseFee, blockGasLimit, pending, baseFee, queued, blob, discard)
 	}
 
-	promote(pending, baseFee, queued, pendingBaseFee, discard, &announcements)
+	promote(pending, baseFee, queued, blob, pendingBaseFee, pendingBlobFee, discard, &announcements)
 	pending.EnforceBestInvariants()
 
 	return announcements, discardReasons, nil
 }
 func addTxsOnNewBlock(blockNum uint64, cacheView kvcache.CacheView, stateChanges *remote.StateChangeBatch,
 	senders *sendersBatch, newTxs types.TxSlots, pendingBaseFee uint64, blockGasLimit uint64,
-	pending *PendingPool, baseFee, queued *SubPool,
+	pending *PendingPool, baseFee, queued, blob *SubPool,
 	byNonce *BySenderAndNonce, byHash map[string]*metaTx, add func(*metaTx, *types.Announcements) DiscardReason, discard func(*metaTx, DiscardReason)) (types.Announcements, error) {
 	protocolBaseFee := calcProtocolBaseFee(pendingBaseFee)
 	if assert.Enable {
@@ -1059,7 +1202,7 @@ func addTxsOnNewBlock(blockNum uint64, cacheView kvcache.CacheView, stateChanges
 			return announcements, err
 		}
 		onSenderStateChange(senderID, nonce, balance, byNonce,
-			protocolBaseFee, blockGasLimit, pending, baseFee, queued, discard)
+			protocolBaseFee, blockGasLimit, pending, baseFee, queued, blob, discard)
 	}
 
 	return announcements, nil
@@ -1103,6 +1246,8 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) Disca
 			p.baseFee.Remove(found)
 		case QueuedSubPool:
 			p.queued.Remove(found)
+		case BlobSubPool:
+			p.blob.Remove(found)
 		default:
 			//already removed
 		}
@@ -1121,6 +1266,7 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) Disca
 	if mt.subPool&IsLocal != 0 {
 		p.isLocalLRU.Add(string(mt.Tx.IDHash[:]), struct{}{})
 	}
+	p.totalBlobsInPool += uint64(len(mt.Tx.BlobHashes))
 	// All transactions are first added to the queued pool and then immediately promoted from there if required
 	p.queued.Add(mt)
 	return NotSet
@@ -1129,9 +1275,14 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) Disca
 // dropping transaction from all sub-structures and from db
 // Important: don't call it while iterating by all
 func (p *TxPool) discardLocked(mt *metaTx, reason DiscardReason) {
-	delete(p.byHash, string(mt.Tx.IDHash[:]))
-	p.deletedTxs = append(p.deletedTxs, mt)
-	p.all.delete(mt)
+	// mt, which wasn't added (NotReplaced, etc...), isn't in pool: `all` is keyed by sender+nonce
+	// and deleting it would drop tx which pool holds instead, and its blobs were never counted
+	if found, ok := p.byHash[string(mt.Tx.IDHash[:])]; ok && found == mt {
+		p.totalBlobsInPool -= uint64(len(mt.Tx.BlobHashes))
+		delete(p.byHash, string(mt.Tx.IDHash[:]))
+		p.deletedTxs = append(p.deletedTxs, mt)
+		p.all.delete(mt)
+	}
 	p.discardReasonsLRU.Add(string(mt.Tx.IDHash[:]), reason)
 }
 
@@ -1152,7 +1303,7 @@ func (p *TxPool) NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool) {
 // modify state_balance and state_nonce, potentially remove some elements (if transaction with some nonce is
 // included into a block), and finally, walk over the transaction records and update SubPool fields depending on
 // the actual presence of nonce gaps and what the balance is.
-func removeMined(byNonce *BySenderAndNonce, minedTxs []*types.TxSlot, pending *PendingPool, baseFee, queued *SubPool, discard func(*metaTx, DiscardReason)) error {
+func removeMined(byNonce *BySenderAndNonce, minedTxs []*types.TxSlot, pending *PendingPool, baseFee, queued, blob *SubPool, discard func(*metaTx, DiscardReason)) error {
 	noncesToRemove := map[uint64]uint64{}
 	for _, txn := range minedTxs {
 		nonce, ok := noncesToRemove[txn.SenderID]
@@ -1184,6 +1335,8 @@ func removeMined(byNonce *BySenderAndNonce, minedTxs []*types.TxSlot, pending *P
 				baseFee.Remove(mt)
 			case QueuedSubPool:
 				queued.Remove(mt)
+			case BlobSubPool:
+				blob.Remove(mt)
 			default:
 				//already removed
 			}
@@ -1203,10 +1356,11 @@ func removeMined(byNonce *BySenderAndNonce, minedTxs []*types.TxSlot, pending *P
 // nonces, and also affect other transactions from the same sender with higher nonce, it loops through all transactions
 // for a given senderID
 func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint256.Int, byNonce *BySenderAndNonce,
-	protocolBaseFee, blockGasLimit uint64, pending *PendingPool, baseFee, queued *SubPool, discard func(*metaTx, DiscardReason)) {
+	protocolBaseFee, blockGasLimit uint64, pending *PendingPool, baseFee, queued, blob *SubPool, discard func(*metaTx, DiscardReason)) {
 	noGapsNonce := senderNonce
 	cumulativeRequiredBalance := uint256.NewInt(0)
 	minFeeCap := uint256.NewInt(0).SetAllOne()
+	minBlobFeeCap := uint256.NewInt(0).SetAllOne()
 	minTip := uint64(math.MaxUint64)
 	var toDel []*metaTx // can't delete items while iterate them
 	byNonce.ascend(senderID, func(mt *metaTx) bool {
@@ -1225,6 +1379,8 @@ func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint
 				baseFee.Remove(mt)
 			case QueuedSubPool:
 				queued.Remove(mt)
+			case BlobSubPool:
+				blob.Remove(mt)
 			default:
 				//already removed
 			}
@@ -1235,6 +1391,11 @@ func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint
 			*minFeeCap = mt.Tx.FeeCap
 		}
 		mt.minFeeCap = *minFeeCap
+		// transaction can't be included until all prior blob transactions of the sender are included
+		if mt.Tx.Type == types.BlobTxType && minBlobFeeCap.Gt(&mt.Tx.BlobFeeCap) {
+			*minBlobFeeCap = mt.Tx.BlobFeeCap
+		}
+		mt.minBlobFeeCap = *minBlobFeeCap
 		if mt.Tx.Tip.IsUint64() {
 			minTip = cmp.Min(minTip, mt.Tx.Tip.Uint64())
 		}
@@ -1245,10 +1406,8 @@ func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint
 			mt.nonceDistance = mt.Tx.Nonce - senderNonce
 		}
 
-		// Sender has enough balance for: gasLimit x feeCap + transferred_value
-		needBalance := uint256.NewInt(mt.Tx.Gas)
-		needBalance.Mul(needBalance, &mt.Tx.FeeCap)
-		needBalance.Add(needBalance, &mt.Tx.Value)
+		// Sender has enough balance for: gasLimit x feeCap + transferred_value + blobGas x blobFeeCap
+		needBalance := requiredBalance(mt.Tx)
 		// 1. Minimum fee requirement. Set to 1 if feeCap of the transaction is no less than in-protocol
 		// parameter of minimal base fee. Set to 0 if feeCap is less than minimum base fee, which means
 		// this transaction will never be included into this particular chain.
@@ -1305,6 +1464,8 @@ func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint
 			baseFee.Updated(mt)
 		case QueuedSubPool:
 			queued.Updated(mt)
+		case BlobSubPool:
+			blob.Updated(mt)
 		}
 		return true
 	})
@@ -1315,7 +1476,18 @@ func onSenderStateChange(senderID uint64, senderNonce uint64, senderBalance uint
 
 // promote reasserts invariants of the subpool and returns the list of transactions that ended up
 // being promoted to the pending or basefee pool, for re-broadcasting
-func promote(pending *PendingPool, baseFee, queued *SubPool, pendingBaseFee uint64, discard func(*metaTx, DiscardReason), announcements *types.Announcements) {
+func promote(pending *PendingPool, baseFee, queued, blob *SubPool, pendingBaseFee, pendingBlobFee uint64, discard func(*metaTx, DiscardReason), announcements *types.Announcements) {
+	blobFee := uint256.NewInt(pendingBlobFee)
+	// Transactions qualifying for pending sub pool go to blob sub pool instead, if they can't pay for blob gas
+	toPending := func(mt *metaTx) {
+		if mt.minBlobFeeCap.Cmp(blobFee) < 0 {
+			blob.Add(mt)
+			return
+		}
+		announcements.Append(mt.Tx.Type, mt.Tx.Size, mt.Tx.IDHash[:])
+		pending.Add(mt)
+	}
+
 	// Demote worst transactions that do not qualify for pending sub pool anymore, to other sub pools, or discard
 	for worst := pending.Worst(); pending.Len() > 0 && (worst.subPool < BaseFeePoolBits || worst.minFeeCap.Cmp(uint256.NewInt(pendingBaseFee)) < 0); worst = pending.Worst() {
 		if worst.subPool >= BaseFeePoolBits {
@@ -1329,11 +1501,22 @@ func promote(pending *PendingPool, baseFee, queued *SubPool, pendingBaseFee uint
 		}
 	}
 
+	// Demote transactions that can't pay for blob gas of the pending block anymore to blob sub pool.
+	// Pending sub pool is not ordered by blob fee, so all of it has to be checked
+	var toMove []*metaTx
+	for _, mt := range pending.best.ms {
+		if mt.minBlobFeeCap.Cmp(blobFee) < 0 {
+			toMove = append(toMove, mt)
+		}
+	}
+	for _, mt := range toMove {
+		pending.Remove(mt)
+		blob.Add(mt)
+	}
+
 	// Promote best transactions from base fee pool to pending pool while they qualify
 	for best := baseFee.Best(); baseFee.Len() > 0 && best.subPool >= BaseFeePoolBits && best.minFeeCap.Cmp(uint256.NewInt(pendingBaseFee)) >= 0; best = baseFee.Best() {
-		tx := baseFee.PopBest()
-		announcements.Append(tx.Tx.Type, tx.Tx.Size, tx.Tx.IDHash[:])
-		pending.Add(tx)
+		toPending(baseFee.PopBest())
 	}
 
 	// Demote worst transactions that do not qualify for base fee pool anymore, to queued sub pool, or discard
@@ -1345,12 +1528,34 @@ func promote(pending *PendingPool, baseFee, queued *SubPool, pendingBaseFee uint
 		}
 	}
 
-	// Promote best transactions from the queued pool to either pending or base fee pool, while they qualify
+	// Demote worst transactions that do not qualify for pending sub pool anymore from blob sub pool, to other sub pools, or discard
+	for worst := blob.Worst(); blob.Len() > 0 && (worst.subPool < BaseFeePoolBits || worst.minFeeCap.Cmp(uint256.NewInt(pendingBaseFee)) < 0); worst = blob.Worst() {
+		if worst.subPool >= BaseFeePoolBits {
+			baseFee.Add(blob.PopWorst())
+		} else if worst.subPool >= QueuedPoolBits {
+			queued.Add(blob.PopWorst())
+		} else {
+			discard(blob.PopWorst(), FeeTooLow)
+		}
+	}
+
+	// Promote transactions which can pay for blob gas of the pending block from blob sub pool to pending pool.
+	// Blob sub pool is ordered by blob fee only among transactions of the same locality, so all of it has to be checked
+	toMove = toMove[:0]
+	for _, mt := range blob.best.ms {
+		if mt.minBlobFeeCap.Cmp(blobFee) >= 0 {
+			toMove = append(toMove, mt)
+		}
+	}
+	for _, mt := range toMove {
+		blob.Remove(mt)
+		toPending(mt)
+	}
+
+	// Promote best transactions from the queued pool to either pending, blob or base fee pool, while they qualify
 	for best := queued.Best(); queued.Len() > 0 && best.subPool >= BaseFeePoolBits; best = queued.Best() {
 		if best.minFeeCap.Cmp(uint256.NewInt(pendingBaseFee)) >= 0 {
-			tx := queued.PopBest()
-			announcements.Append(tx.Tx.Type, tx.Tx.Size, tx.Tx.IDHash[:])
-			pending.Add(tx)
+			toPending(queued.PopBest())
 		} else {
 			baseFee.Add(queued.PopBest())
 		}
@@ -1375,6 +1580,11 @@ func promote(pending *PendingPool, baseFee, queued *SubPool, pendingBaseFee uint
 	for _ = queued.Worst(); queued.Len() > queued.limit; _ = queued.Worst() {
 		discard(queued.PopWorst(), QueuedPoolOverflow)
 	}
+
+	// Discard worst transactions from the blob sub pool until it is within its capacity limits
+	for blob.Len() > blob.limit {
+		discard(blob.PopWorst(), BlobPoolOverflow)
+	}
 }
 
 // MainLoop - does:
@@ -1657,7 +1867,7 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		addr, txRlp := *(*[20]byte)(v[:20]), v[20:]
 		txn := &types.TxSlot{}
 
-		_, err = parseCtx.ParseTransaction(txRlp, 0, txn, nil, false /* hasEnvelope */, nil)
+		_, err = parseCtx.ParseTransaction(txRlp, 0, txn, nil, false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
 		if err != nil {
 			err = fmt.Errorf("err: %w, rlp: %x", err, txRlp)
 			log.Warn("[txpool] fromDB: parseTransaction", "err", err)
@@ -1695,7 +1905,7 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		return err
 	}
 	if _, _, err := addTxs(p.lastSeenBlock.Load(), cacheView, p.senders, txs,
-		pendingBaseFee, math.MaxUint64 /* blockGasLimit */, p.pending, p.baseFee, p.queued, p.all, p.byHash, p.addLocked, p.discardLocked, false); err != nil {
+		pendingBaseFee, p.pendingBlobFee.Load(), math.MaxUint64 /* blockGasLimit */, p.pending, p.baseFee, p.queued, p.blob, p.all, p.byHash, p.addLocked, p.discardLocked, false); err != nil {
 		return err
 	}
 	p.pendingBaseFee.Store(pendingBaseFee)
@@ -1752,7 +1962,7 @@ func (p *TxPool) printDebug(prefix string) {
 	for _, j := range p.byHash {
 		fmt.Printf("\tsenderID=%d, nonce=%d, tip=%d\n", j.Tx.SenderID, j.Tx.Nonce, j.Tx.Tip)
 	}
-	fmt.Printf("%s.pool.queues.len: %d,%d,%d\n", prefix, p.pending.Len(), p.baseFee.Len(), p.queued.Len())
+	fmt.Printf("%s.pool.queues.len: %d,%d,%d,%d\n", prefix, p.pending.Len(), p.baseFee.Len(), p.queued.Len(), p.blob.Len())
 	for _, mt := range p.pending.best.ms {
 		mt.Tx.PrintDebug(fmt.Sprintf("%s.pending: %b,%d,%d,%d", prefix, mt.subPool, mt.Tx.SenderID, mt.Tx.Nonce, mt.Tx.Tip))
 	}
@@ -1762,6 +1972,9 @@ func (p *TxPool) printDebug(prefix string) {
 	for _, mt := range p.queued.best.ms {
 		mt.Tx.PrintDebug(fmt.Sprintf("%s.queued : %b,%d,%d,%d", prefix, mt.subPool, mt.Tx.SenderID, mt.Tx.Nonce, mt.Tx.Tip))
 	}
+	for _, mt := range p.blob.best.ms {
+		mt.Tx.PrintDebug(fmt.Sprintf("%s.blob : %b,%d,%d,%d", prefix, mt.subPool, mt.Tx.SenderID, mt.Tx.Nonce, mt.Tx.Tip))
+	}
 }
 func (p *TxPool) logStats() {
 	if !p.started.Load() {
@@ -1779,6 +1992,7 @@ func (p *TxPool) logStats() {
 		"pending", p.pending.Len(),
 		"baseFee", p.baseFee.Len(),
 		"queued", p.queued.Len(),
+		"blob", p.blob.Len(),
 	}
 	cacheKeys := p._stateCache.Len()
 	if cacheKeys > 0 {
@@ -1789,6 +2003,7 @@ func (p *TxPool) logStats() {
 	pendingSubCounter.Set(uint64(p.pending.Len()))
 	basefeeSubCounter.Set(uint64(p.baseFee.Len()))
 	queuedSubCounter.Set(uint64(p.queued.Len()))
+	blobSubCounter.Set(uint64(p.blob.Len()))
 }
 
 // Deprecated need switch to streaming-like
@@ -1970,18 +2185,14 @@ func (sc *sendersBatch) info(cacheView kvcache.CacheView, id uint64) (nonce uint
 	if !ok {
 		panic("must not happen")
 	}
-	encoded, err := cacheView.Get(addr.Bytes())
+	acc, err := cacheView.GetAccount(addr)
 	if err != nil {
 		return 0, emptySender.balance, err
 	}
-	if len(encoded) == 0 {
+	if acc == nil {
 		return emptySender.nonce, emptySender.balance, nil
 	}
-	nonce, balance, err = types.DecodeSender(encoded)
-	if err != nil {
-		return 0, emptySender.balance, err
-	}
-	return nonce, balance, nil
+	return acc.Nonce, acc.Balance, nil
 }
 
 func (sc *sendersBatch) registerNewSenders(newTxs *types.TxSlots) (err error) {
@@ -2328,6 +2539,10 @@ func (mt *metaTx) better(than *metaTx, pendingBaseFee uint256.Int) bool {
 		if mt.minFeeCap.Cmp(&than.minFeeCap) != 0 {
 			return mt.minFeeCap.Cmp(&than.minFeeCap) > 0
 		}
+	case BlobSubPool:
+		if mt.minBlobFeeCap.Cmp(&than.minBlobFeeCap) != 0 {
+			return mt.minBlobFeeCap.Cmp(&than.minBlobFeeCap) > 0
+		}
 	case QueuedSubPool:
 		if mt.nonceDistance != than.nonceDistance {
 			return mt.nonceDistance < than.nonceDistance
@@ -2363,6 +2578,10 @@ func (mt *metaTx) worse(than *metaTx, pendingBaseFee uint256.Int) bool {
 		if mt.cumulativeBalanceDistance != than.cumulativeBalanceDistance {
 			return mt.cumulativeBalanceDistance > than.cumulativeBalanceDistance
 		}
+	case BlobSubPool:
+		if mt.minBlobFeeCap.Cmp(&than.minBlobFeeCap) != 0 {
+			return mt.minBlobFeeCap.Cmp(&than.minBlobFeeCap) < 0
+		}
 	case BaseFeeSubPool, QueuedSubPool:
 		if mt.nonceDistance != than.nonceDistance {
 			return mt.nonceDistance > than.nonceDistance
@@ -22,8 +22,11 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sync"
 	"testing"
 
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +35,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
 	"github.com/ledgerwatch/erigon-lib/common/u256"
+	"github.com/ledgerwatch/erigon-lib/crypto/kzg"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -712,3 +716,303 @@ func TestShanghaiValidateTx(t *testing.T) {
 		})
 	}
 }
+
+func TestBlobTxs(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	ch := make(chan types.Announcements, 100)
+	db, coreDB := memdb.NewTestPoolDB(t), memdb.NewTestDB(t)
+
+	cfg := txpoolcfg.DefaultConfig
+	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	pool, err := New(ch, coreDB, cfg, sendersCache, *u256.N1, nil)
+	assert.NoError(err)
+	require.True(pool != nil)
+	ctx := context.Background()
+	var stateVersionID uint64 = 0
+	pendingBaseFee := uint64(200000)
+	h1 := gointerfaces.ConvertHashToH256([32]byte{})
+	change := &remote.StateChangeBatch{
+		StateVersionId:      stateVersionID,
+		PendingBlockBaseFee: pendingBaseFee,
+		BlockGasLimit:       1000000,
+		ChangeBatch: []*remote.StateChange{
+			{BlockHeight: 0, BlockHash: h1},
+		},
+	}
+	var addr1, addr2 [20]byte
+	addr1[0], addr2[0] = 1, 2
+	v := make([]byte, types.EncodeSenderLengthForStorage(0, *uint256.NewInt(1 * common.Ether)))
+	types.EncodeSender(0, *uint256.NewInt(1 * common.Ether), v)
+	for _, addr := range [][20]byte{addr1, addr2} {
+		change.ChangeBatch[0].Changes = append(change.ChangeBatch[0].Changes, &remote.AccountChange{
+			Action:  remote.Action_UPSERT,
+			Address: gointerfaces.ConvertAddressToH160(addr),
+			Data:    v,
+		})
+	}
+	tx, err := db.BeginRw(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+	change.PendingBlobFeePerGas = 100
+	err = pool.OnNewBlock(ctx, change, types.TxSlots{}, types.TxSlots{}, tx)
+	assert.NoError(err)
+
+	blobTx := func(idHash byte, blobFeeCap uint64, blobs int) *types.TxSlot {
+		txSlot := &types.TxSlot{
+			Type:       types.BlobTxType,
+			Tip:        *uint256.NewInt(300000),
+			FeeCap:     *uint256.NewInt(300000),
+			BlobFeeCap: *uint256.NewInt(blobFeeCap),
+			Gas:        100000,
+			Rlp:        []byte{idHash},
+		}
+		txSlot.IDHash[0] = idHash
+		withBlobs(t, txSlot, blobs)
+		return txSlot
+	}
+
+	{
+		var txSlots types.TxSlots
+		txSlots.Append(blobTx(1, 50, 2), addr1[:], true)  // can't pay pending blob fee
+		txSlots.Append(blobTx(2, 200, 1), addr2[:], true) // can pay pending blob fee
+		reasons, err := pool.AddLocalTxs(ctx, txSlots, tx)
+		assert.NoError(err)
+		for _, reason := range reasons {
+			assert.Equal(Success, reason, reason.String())
+		}
+	}
+	pending, baseFee, queued, blob := pool.CountContent()
+	assert.Equal(1, pending)
+	assert.Equal(0, baseFee)
+	assert.Equal(0, queued)
+	assert.Equal(1, blob)
+	assert.Equal(uint64(3), pool.totalBlobsInPool)
+
+	// blob fee drops - transaction is promoted from blob sub-pool to pending
+	change.ChangeBatch[0].Changes = nil
+	change.PendingBlobFeePerGas = 10
+	err = pool.OnNewBlock(ctx, change, types.TxSlots{}, types.TxSlots{}, tx)
+	assert.NoError(err)
+	pending, _, _, blob = pool.CountContent()
+	assert.Equal(2, pending)
+	assert.Equal(0, blob)
+
+	// only one of transactions fits into blob gas budget of 2 blobs
+	txs := types.TxsRlp{}
+	_, count, err := pool.YieldBest(10, &txs, tx, 0, math.MaxUint64, 2*fixedgas.BlobGasPerBlob, mapset.NewThreadUnsafeSet[[32]byte]())
+	assert.NoError(err)
+	assert.Equal(1, count)
+
+	// both of them fit into blob gas budget of a block
+	txs = types.TxsRlp{}
+	_, count, err = pool.YieldBest(10, &txs, tx, 0, math.MaxUint64, fixedgas.MaxBlobGasPerBlock, mapset.NewThreadUnsafeSet[[32]byte]())
+	assert.NoError(err)
+	assert.Equal(2, count)
+
+	// blob fee rises - transaction is demoted from pending to blob sub-pool
+	change.PendingBlobFeePerGas = 100
+	err = pool.OnNewBlock(ctx, change, types.TxSlots{}, types.TxSlots{}, tx)
+	assert.NoError(err)
+	pending, _, _, blob = pool.CountContent()
+	assert.Equal(1, pending)
+	assert.Equal(1, blob)
+
+	// StateChangeBatch without blob fee (old server) - fee set by SetPendingBlobFee is used
+	change.PendingBlobFeePerGas = 0
+	pool.SetPendingBlobFee(10)
+	err = pool.OnNewBlock(ctx, change, types.TxSlots{}, types.TxSlots{}, tx)
+	assert.NoError(err)
+	pending, _, _, blob = pool.CountContent()
+	assert.Equal(2, pending)
+	assert.Equal(0, blob)
+}
+
+func TestBlobTxUnwind(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	ch := make(chan types.Announcements, 100)
+	db, coreDB := memdb.NewTestPoolDB(t), memdb.NewTestDB(t)
+
+	cfg := txpoolcfg.DefaultConfig
+	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	pool, err := New(ch, coreDB, cfg, sendersCache, *u256.N1, nil)
+	assert.NoError(err)
+	require.True(pool != nil)
+	ctx := context.Background()
+	h1 := gointerfaces.ConvertHashToH256([32]byte{})
+	var addr1 [20]byte
+	addr1[0] = 1
+	v := make([]byte, types.EncodeSenderLengthForStorage(0, *uint256.NewInt(1 * common.Ether)))
+	types.EncodeSender(0, *uint256.NewInt(1 * common.Ether), v)
+	change := &remote.StateChangeBatch{
+		PendingBlockBaseFee: 200000,
+		BlockGasLimit:       1000000,
+		ChangeBatch: []*remote.StateChange{
+			{BlockHeight: 0, BlockHash: h1, Changes: []*remote.AccountChange{
+				{Action: remote.Action_UPSERT, Address: gointerfaces.ConvertAddressToH160(addr1), Data: v},
+			}},
+		},
+	}
+	tx, err := db.BeginRw(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+	err = pool.OnNewBlock(ctx, change, types.TxSlots{}, types.TxSlots{}, tx)
+	assert.NoError(err)
+
+	blobTx := func(idHash byte, tip uint64, blobs int) *types.TxSlot {
+		txSlot := &types.TxSlot{
+			Type:       types.BlobTxType,
+			Tip:        *uint256.NewInt(tip),
+			FeeCap:     *uint256.NewInt(tip),
+			BlobFeeCap: *uint256.NewInt(200),
+			Gas:        100000,
+			Rlp:        []byte{idHash},
+		}
+		txSlot.IDHash[0] = idHash
+		withBlobs(t, txSlot, blobs)
+		return txSlot
+	}
+	var txSlots types.TxSlots
+	txSlots.Append(blobTx(1, 300000, 2), addr1[:], true)
+	reasons, err := pool.AddLocalTxs(ctx, txSlots, tx)
+	assert.NoError(err)
+	assert.Equal(Success, reasons[0], reasons[0].String())
+	assert.Equal(uint64(2), pool.totalBlobsInPool)
+
+	// unwound block has tx which pool holds, and tx with same nonce and lower tip - which is not added
+	var unwindTxs types.TxSlots
+	unwindTxs.Append(blobTx(1, 300000, 2), addr1[:], false)
+	unwindTxs.Append(blobTx(2, 250000, 1), addr1[:], false)
+	change.ChangeBatch[0].Changes = nil
+	change.ChangeBatch[0].Direction = remote.Direction_UNWIND
+	err = pool.OnNewBlock(ctx, change, unwindTxs, types.TxSlots{}, tx)
+	assert.NoError(err)
+	assert.Equal(uint64(2), pool.totalBlobsInPool)
+	pending, _, _, _ := pool.CountContent()
+	assert.Equal(1, pending)
+
+	// blob limit of pool isn't reached
+	txSlots = types.TxSlots{}
+	txSlots.Append(blobTx(3, 400000, 1), addr1[:], true)
+	reasons, err = pool.AddLocalTxs(ctx, txSlots, tx)
+	assert.NoError(err)
+	assert.Equal(Success, reasons[0], reasons[0].String())
+	assert.Equal(uint64(1), pool.totalBlobsInPool)
+}
+
+func TestValidateBlobTx(t *testing.T) {
+	tests := map[string]struct {
+		expected    DiscardReason
+		blobs       int
+		blobFeeCap  uint64
+		blobsInPool uint64
+		corrupt     func(txn *types.TxSlot)
+	}{
+		"valid": {
+			expected:   Success,
+			blobs:      2,
+			blobFeeCap: 1,
+		},
+		"no blobs": {
+			expected:   NoBlobs,
+			blobFeeCap: 1,
+		},
+		"too many blobs": {
+			expected:   TooManyBlobs,
+			blobs:      int(fixedgas.MaxBlobsPerBlock) + 1,
+			blobFeeCap: 1,
+		},
+		"no sidecar": {
+			expected:   NoBlobSidecar,
+			blobs:      1,
+			blobFeeCap: 1,
+			corrupt: func(txn *types.TxSlot) {
+				txn.Blobs, txn.Commitments, txn.Proofs = nil, nil, nil
+			},
+		},
+		"missing proof": {
+			expected:   NoBlobSidecar,
+			blobs:      2,
+			blobFeeCap: 1,
+			corrupt: func(txn *types.TxSlot) {
+				txn.Proofs = txn.Proofs[:1]
+			},
+		},
+		"wrong commitment": {
+			expected:   BlobHashMismatch,
+			blobs:      1,
+			blobFeeCap: 1,
+			corrupt: func(txn *types.TxSlot) {
+				txn.Commitments = []types.KZGCommitment{{4, 5, 6}}
+			},
+		},
+		"wrong blob": {
+			expected:   InvalidBlobProof,
+			blobs:      1,
+			blobFeeCap: 1,
+			corrupt: func(txn *types.TxSlot) {
+				txn.Blobs = [][]byte{common.Copy(txn.Blobs[0])}
+				txn.Blobs[0][31]++
+			},
+		},
+		"zero blob fee": {
+			expected: UnderPriced,
+			blobs:    1,
+		},
+		"pool is full": {
+			expected:    BlobsLimitReached,
+			blobs:       1,
+			blobFeeCap:  1,
+			blobsInPool: txpoolcfg.DefaultConfig.TotalBlobPoolLimit,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool, err := New(make(chan types.Announcements), nil, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil)
+			require.NoError(t, err)
+			pool.totalBlobsInPool = test.blobsInPool
+
+			txn := &types.TxSlot{
+				Type:       types.BlobTxType,
+				BlobFeeCap: *uint256.NewInt(test.blobFeeCap),
+			}
+			withBlobs(t, txn, test.blobs)
+			if test.corrupt != nil {
+				test.corrupt(txn)
+			}
+			assert.Equal(t, test.expected, pool.validateBlobTx(txn))
+		})
+	}
+}
+
+var testBlob struct {
+	once       sync.Once
+	blob       []byte
+	commitment types.KZGCommitment
+	proof      types.KZGProof
+}
+
+// withBlobs - adds `blobs` copies of valid blob with its versioned hash, KZG commitment and proof to txn
+func withBlobs(t testing.TB, txn *types.TxSlot, blobs int) {
+	t.Helper()
+	testBlob.once.Do(func() {
+		kzgCtx, err := kzg.Ctx()
+		require.NoError(t, err)
+		var blob gokzg4844.Blob
+		for i := 0; i < len(blob); i += gokzg4844.SerializedScalarSize {
+			blob[i+gokzg4844.SerializedScalarSize-1] = byte(i / gokzg4844.SerializedScalarSize)
+		}
+		commitment, err := kzgCtx.BlobToKZGCommitment(blob, 1)
+		require.NoError(t, err)
+		proof, err := kzgCtx.ComputeBlobKZGProof(blob, commitment, 1)
+		require.NoError(t, err)
+		testBlob.blob = blob[:]
+		testBlob.commitment, testBlob.proof = types.KZGCommitment(commitment), types.KZGProof(proof)
+	})
+	for i := 0; i < blobs; i++ {
+		txn.BlobHashes = append(txn.BlobHashes, kzgToVersionedHash(testBlob.commitment))
+		txn.Blobs = append(txn.Blobs, testBlob.blob)
+		txn.Commitments = append(txn.Commitments, testBlob.commitment)
+		txn.Proofs = append(txn.Proofs, testBlob.proof)
+	}
+}
@@ -46,16 +46,17 @@ import (
 )
 
 // TxPoolAPIVersion
-var TxPoolAPIVersion = &types2.VersionReply{Major: 1, Minor: 0, Patch: 0}
+// 1.1.0 - Added blob sub-pool: BLOB type of transactions in All, blobCount in Status
+var TxPoolAPIVersion = &types2.VersionReply{Major: 1, Minor: 1, Patch: 0}
 
 type txPool interface {
 	ValidateSerializedTxn(serializedTxn []byte) error
 
-	PeekBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas uint64) (bool, error)
+	PeekBest(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableGas, availableBlobGas uint64) (bool, error)
 	GetRlp(tx kv.Tx, hash []byte) ([]byte, error)
 	AddLocalTxs(ctx context.Context, newTxs types.TxSlots, tx kv.Tx) ([]DiscardReason, error)
 	deprecatedForEach(_ context.Context, f func(rlp []byte, sender common.Address, t SubPoolType), tx kv.Tx)
-	CountContent() (int, int, int)
+	CountContent() (int, int, int, int)
 	IdHashKnown(tx kv.Tx, hash []byte) (bool, error)
 	NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool)
 }
@@ -122,6 +123,8 @@ func convertSubPoolType(t SubPoolType) txpool_proto.AllReply_TxnType {
 		return txpool_proto.AllReply_BASE_FEE
 	case QueuedSubPool:
 		return txpool_proto.AllReply_QUEUED
+	case BlobSubPool:
+		return txpool_proto.AllReply_BLOB
 	default:
 		panic("unknown")
 	}
@@ -153,7 +156,7 @@ func (s *GrpcServer) Pending(ctx context.Context, _ *emptypb.Empty) (*txpool_pro
 	reply := &txpool_proto.PendingReply{}
 	reply.Txs = make([]*txpool_proto.PendingReply_Tx, 0, 32)
 	txSlots := types.TxsRlp{}
-	if _, err := s.txPool.PeekBest(math.MaxInt16, &txSlots, tx, 0 /* onTopOf */, math.MaxUint64 /* available gas */); err != nil {
+	if _, err := s.txPool.PeekBest(math.MaxInt16, &txSlots, tx, 0 /* onTopOf */, math.MaxUint64 /* available gas */, math.MaxUint64 /* available blob gas */); err != nil {
 		return nil, err
 	}
 	var senderArr [20]byte
@@ -190,7 +193,7 @@ func (s *GrpcServer) Add(ctx context.Context, in *txpool_proto.AddRequest) (*txp
 		slots.Resize(uint(j + 1))
 		slots.Txs[j] = &types.TxSlot{}
 		slots.IsLocal[j] = true
-		if _, err := parseCtx.ParseTransaction(in.RlpTxs[i], 0, slots.Txs[j], slots.Senders.At(j), false /* hasEnvelope */, func(hash []byte) error {
+		if _, err := parseCtx.ParseTransaction(in.RlpTxs[i], 0, slots.Txs[j], slots.Senders.At(j), false /* hasEnvelope */, true /* wrappedWithBlobs */, func(hash []byte) error {
 			if known, _ := s.txPool.IdHashKnown(tx, hash); known {
 				return types.ErrAlreadyKnown
 			}
@@ -283,11 +286,12 @@ func (s *GrpcServer) Transactions(ctx context.Context, in *txpool_proto.Transact
 }
 
 func (s *GrpcServer) Status(_ context.Context, _ *txpool_proto.StatusRequest) (*txpool_proto.StatusReply, error) {
-	pending, baseFee, queued := s.txPool.CountContent()
+	pending, baseFee, queued, blob := s.txPool.CountContent()
 	return &txpool_proto.StatusReply{
 		PendingCount: uint32(pending),
 		QueuedCount:  uint32(queued),
 		BaseFeeCount: uint32(baseFee),
+		BlobCount:    uint32(blob),
 	}, nil
 }
 
@@ -15,6 +15,8 @@ type Config struct {
 	PendingSubPoolLimit   int
 	BaseFeeSubPoolLimit   int
 	QueuedSubPoolLimit    int
+	BlobSubPoolLimit      int
+	TotalBlobPoolLimit    uint64 // Total number of blobs (not txs) allowed within the txpool
 	MinFeeCap             uint64
 	AccountSlots          uint64 // Number of executable transaction slots guaranteed per account
 	PriceBump             uint64 // Price bump percentage to replace an already existing transaction
@@ -30,6 +32,8 @@ var DefaultConfig = Config{
 	PendingSubPoolLimit: 10_000,
 	BaseFeeSubPoolLimit: 10_000,
 	QueuedSubPoolLimit:  10_000,
+	BlobSubPoolLimit:    1_000,
+	TotalBlobPoolLimit:  480, // 480 blobs of 128KB each ~ 60MB of blob data
 
 	MinFeeCap:           1,
 	AccountSlots:        16, //TODO: to choose right value (16 to be compatible with Geth)
@@ -31,6 +31,7 @@ import (
 	"golang.org/x/crypto/sha3"
 
 	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/common/u256"
 	"github.com/ledgerwatch/erigon-lib/crypto"
@@ -99,14 +100,27 @@ type TxSlot struct {
 	Creation       bool     // Set to true if "To" field of the transaction is not set
 	Type           byte     // Transaction type
 	Size           uint32   // Size of the payload
+
+	// EIP-4844: Shard Blob Transactions
+	BlobFeeCap  uint256.Int     // Maximum fee per blob gas (max_fee_per_blob_gas)
+	BlobHashes  []common.Hash   // Versioned hashes of the blobs (blob_versioned_hashes)
+	Blobs       [][]byte        // Blobs - present only if transaction was parsed from network wrapper form
+	Commitments []KZGCommitment // KZG commitments of the blobs - present only in network wrapper form
+	Proofs      []KZGProof      // KZG proofs of the blobs - present only in network wrapper form
 }
 
 const (
 	LegacyTxType     byte = 0
 	AccessListTxType byte = 1
 	DynamicFeeTxType byte = 2
+	BlobTxType       byte = 3
 )
 
+const LenKZG = 48 // Length of KZG commitment and KZG proof in bytes
+
+type KZGCommitment [LenKZG]byte
+type KZGProof [LenKZG]byte
+
 var ErrParseTxn = fmt.Errorf("%w transaction", rlp.ErrParse)
 
 var ErrRejected = errors.New("rejected")
@@ -123,7 +137,11 @@ func (ctx *TxParseContext) ChainIDRequired() *TxParseContext {
 
 // ParseTransaction extracts all the information from the transactions's payload (RLP) necessary to build TxSlot
 // it also performs syntactic validation of the transactions
-func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlot, sender []byte, hasEnvelope bool, validateHash func([]byte) error) (p int, err error) {
+//
+// wrappedWithBlobs - blob transactions (type 3) are expected in network wrapper form:
+// `0x03 || rlp([tx_payload_body, blobs, commitments, proofs])`, otherwise in canonical form `0x03 || rlp(tx_payload_body)`.
+// Ignored for other transaction types.
+func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlot, sender []byte, hasEnvelope, wrappedWithBlobs bool, validateHash func([]byte) error) (p int, err error) {
 	if len(payload) == 0 {
 		return 0, fmt.Errorf("%w: empty rlp", ErrParseTxn)
 	}
@@ -152,8 +170,12 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 	p = dataPos
 
 	// If it is non-legacy transaction, the transaction type follows, and then the the list
+	var wrapperDataPos, wrapperDataLen, bodyEnd int // position of blob transaction network wrapper and end of its body, if any
 	if !legacy {
 		slot.Type = payload[p]
+		if slot.Type > BlobTxType {
+			return 0, fmt.Errorf("%w: unknown transaction type: %d", ErrParseTxn, slot.Type)
+		}
 		if _, err = ctx.Keccak1.Write(payload[p : p+1]); err != nil {
 			return 0, fmt.Errorf("%w: computing IdHash (hashing type Prefix): %s", ErrParseTxn, err) //nolint
 		}
@@ -168,13 +190,24 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 		if err != nil {
 			return 0, fmt.Errorf("%w: envelope Prefix: %s", ErrParseTxn, err) //nolint
 		}
+		// For legacy transaction, the entire payload in expected to be in "rlp" field
+		// whereas for non-legacy, only the content of the envelope (start with position p)
+		slot.Rlp = payload[p-1 : dataPos+dataLen]
+		// Network wrapper of blob transaction: [tx_payload_body, blobs, commitments, proofs]
+		// Transaction hash and signature cover only tx_payload_body
+		if slot.Type == BlobTxType && wrappedWithBlobs {
+			wrapperDataPos, wrapperDataLen = dataPos, dataLen
+			p = dataPos
+			dataPos, dataLen, err = rlp.List(payload, p)
+			if err != nil {
+				return 0, fmt.Errorf("%w: blob tx body Prefix: %s", ErrParseTxn, err) //nolint
+			}
+			bodyEnd = dataPos + dataLen
+		}
 		// Hash the envelope, not the full payload
 		if _, err = ctx.Keccak1.Write(payload[p : dataPos+dataLen]); err != nil {
 			return 0, fmt.Errorf("%w: computing IdHash (hashing the envelope): %s", ErrParseTxn, err) //nolint
 		}
-		// For legacy transaction, the entire payload in expected to be in "rlp" field
-		// whereas for non-legacy, only the content of the envelope (start with position p)
-		slot.Rlp = payload[p-1 : dataPos+dataLen]
 		p = dataPos
 	} else {
 		slot.Type = LegacyTxType
@@ -239,6 +272,9 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 	if dataLen != 0 && dataLen != 20 {
 		return 0, fmt.Errorf("%w: unexpected length of to field: %d", ErrParseTxn, dataLen)
 	}
+	if dataLen == 0 && slot.Type == BlobTxType {
+		return 0, fmt.Errorf("%w: blob tx can't create contract", ErrParseTxn)
+	}
 
 	// Only note if To field is empty or not
 	slot.Creation = dataLen == 0
@@ -308,6 +344,37 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 		}
 		p = dataPos + dataLen
 	}
+	// Next follows blob fee cap and blob versioned hashes for blob transactions
+	if slot.Type == BlobTxType {
+		p, err = rlp.U256(payload, p, &slot.BlobFeeCap)
+		if err != nil {
+			return 0, fmt.Errorf("%w: blob fee cap: %s", ErrParseTxn, err) //nolint
+		}
+		dataPos, dataLen, err = rlp.List(payload, p)
+		if err != nil {
+			return 0, fmt.Errorf("%w: blob hashes len: %s", ErrParseTxn, err) //nolint
+		}
+		slot.BlobHashes = make([]common.Hash, 0, dataLen/(length.Hash+1))
+		hashPos := dataPos
+		for hashPos < dataPos+dataLen {
+			var hash common.Hash
+			hashPos, err = rlp.ParseHash(payload, hashPos, hash[:])
+			if err != nil {
+				return 0, fmt.Errorf("%w: blob hash: %s", ErrParseTxn, err) //nolint
+			}
+			slot.BlobHashes = append(slot.BlobHashes, hash)
+		}
+		if hashPos != dataPos+dataLen {
+			return 0, fmt.Errorf("%w: extraneous space in the blob hashes after all hashes", ErrParseTxn)
+		}
+		if len(slot.BlobHashes) == 0 {
+			return 0, fmt.Errorf("%w: blob tx must have at least one blob hash", ErrParseTxn)
+		}
+		p = dataPos + dataLen
+	} else {
+		slot.BlobFeeCap.Clear()
+		slot.BlobHashes = nil
+	}
 	// This is where the data for Sighash ends
 	// Next follows V of the signature
 	var vByte byte
@@ -369,6 +436,21 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 		return 0, fmt.Errorf("%w: S: %s", ErrParseTxn, err) //nolint
 	}
 
+	// Blobs, commitments and proofs follow the body of blob transaction in network wrapper form
+	slot.Blobs, slot.Commitments, slot.Proofs = nil, nil, nil
+	if wrapperDataLen > 0 {
+		if p != bodyEnd {
+			return 0, fmt.Errorf("%w: extraneous space in the blob tx body", ErrParseTxn)
+		}
+		p, err = ctx.parseBlobs(payload, p, slot)
+		if err != nil {
+			return 0, err
+		}
+		if p != wrapperDataPos+wrapperDataLen {
+			return 0, fmt.Errorf("%w: extraneous space in the blob tx wrapper", ErrParseTxn)
+		}
+	}
+
 	// For legacy transactions, hash the full payload
 	if legacy {
 		if _, err = ctx.Keccak1.Write(payload[pos:p]); err != nil {
@@ -465,6 +547,74 @@ func (ctx *TxParseContext) ParseTransaction(payload []byte, pos int, slot *TxSlo
 	return p, nil
 }
 
+// parseBlobs parses blobs, commitments and proofs of the network wrapper of blob transaction.
+// Only syntactic validation is performed: lengths of items and that number of items equals number of blob hashes
+func (ctx *TxParseContext) parseBlobs(payload []byte, pos int, slot *TxSlot) (p int, err error) {
+	dataPos, dataLen, err := rlp.List(payload, pos)
+	if err != nil {
+		return 0, fmt.Errorf("%w: blobs len: %s", ErrParseTxn, err) //nolint
+	}
+	blobPos := dataPos
+	for blobPos < dataPos+dataLen {
+		blobPos, err = rlp.StringOfLen(payload, blobPos, fixedgas.BlobSize)
+		if err != nil {
+			return 0, fmt.Errorf("%w: blob: %s", ErrParseTxn, err) //nolint
+		}
+		slot.Blobs = append(slot.Blobs, payload[blobPos:blobPos+fixedgas.BlobSize])
+		blobPos += fixedgas.BlobSize
+	}
+	if blobPos != dataPos+dataLen {
+		return 0, fmt.Errorf("%w: extraneous space in the blobs after all blobs", ErrParseTxn)
+	}
+	p = dataPos + dataLen
+
+	dataPos, dataLen, err = rlp.List(payload, p)
+	if err != nil {
+		return 0, fmt.Errorf("%w: commitments len: %s", ErrParseTxn, err) //nolint
+	}
+	commitmentPos := dataPos
+	for commitmentPos < dataPos+dataLen {
+		commitmentPos, err = rlp.StringOfLen(payload, commitmentPos, LenKZG)
+		if err != nil {
+			return 0, fmt.Errorf("%w: commitment: %s", ErrParseTxn, err) //nolint
+		}
+		var commitment KZGCommitment
+		copy(commitment[:], payload[commitmentPos:commitmentPos+LenKZG])
+		slot.Commitments = append(slot.Commitments, commitment)
+		commitmentPos += LenKZG
+	}
+	if commitmentPos != dataPos+dataLen {
+		return 0, fmt.Errorf("%w: extraneous space in the commitments after all commitments", ErrParseTxn)
+	}
+	p = dataPos + dataLen
+
+	dataPos, dataLen, err = rlp.List(payload, p)
+	if err != nil {
+		return 0, fmt.Errorf("%w: proofs len: %s", ErrParseTxn, err) //nolint
+	}
+	proofPos := dataPos
+	for proofPos < dataPos+dataLen {
+		proofPos, err = rlp.StringOfLen(payload, proofPos, LenKZG)
+		if err != nil {
+			return 0, fmt.Errorf("%w: proof: %s", ErrParseTxn, err) //nolint
+		}
+		var proof KZGProof
+		copy(proof[:], payload[proofPos:proofPos+LenKZG])
+		slot.Proofs = append(slot.Proofs, proof)
+		proofPos += LenKZG
+	}
+	if proofPos != dataPos+dataLen {
+		return 0, fmt.Errorf("%w: extraneous space in the proofs after all proofs", ErrParseTxn)
+	}
+	p = dataPos + dataLen
+
+	if len(slot.Blobs) != len(slot.BlobHashes) || len(slot.Commitments) != len(slot.BlobHashes) || len(slot.Proofs) != len(slot.BlobHashes) {
+		return 0, fmt.Errorf("%w: blob hashes=%d, blobs=%d, commitments=%d, proofs=%d: must be equal", ErrParseTxn,
+			len(slot.BlobHashes), len(slot.Blobs), len(slot.Commitments), len(slot.Proofs))
+	}
+	return p, nil
+}
+
 type PeerID *types.H512
 
 type Hashes []byte // flatten list of 32-byte hashes
@@ -758,36 +908,63 @@ func EncodeSender(nonce uint64, balance uint256.Int, buffer []byte) {
 	buffer[0] = byte(fieldSet)
 }
 func DecodeSender(enc []byte) (nonce uint64, balance uint256.Int, err error) {
+	nonce, balance, _, _, err = DecodeAccount(enc)
+	return nonce, balance, err
+}
+
+// DecodeAccount - decodes account in "storage" format of kv.PlainState. codeHash is nil if account has no code hash field
+func DecodeAccount(enc []byte) (nonce uint64, balance uint256.Int, incarnation uint64, codeHash []byte, err error) {
 	if len(enc) == 0 {
 		return
 	}
 
 	var fieldSet = enc[0]
 	var pos = 1
-
-	if fieldSet&1 > 0 {
+	field := func(name string) ([]byte, error) {
+		if len(enc) < pos+1 {
+			return nil, fmt.Errorf("malformed CBOR for Account.%s: no length", name)
+		}
 		decodeLength := int(enc[pos])
-
 		if len(enc) < pos+decodeLength+1 {
-			return nonce, balance, fmt.Errorf(
-				"malformed CBOR for Account.Nonce: %s, Length %d",
-				enc[pos+1:], decodeLength)
+			return nil, fmt.Errorf(
+				"malformed CBOR for Account.%s: %s, Length %d",
+				name, enc[pos+1:], decodeLength)
 		}
-
-		nonce = bytesToUint64(enc[pos+1 : pos+decodeLength+1])
+		v := enc[pos+1 : pos+decodeLength+1]
 		pos += decodeLength + 1
+		return v, nil
+	}
+
+	if fieldSet&1 > 0 {
+		v, err := field("Nonce")
+		if err != nil {
+			return nonce, balance, incarnation, codeHash, err
+		}
+		nonce = bytesToUint64(v)
 	}
 
 	if fieldSet&2 > 0 {
-		decodeLength := int(enc[pos])
+		v, err := field("Balance")
+		if err != nil {
+			return nonce, balance, incarnation, codeHash, err
+		}
+		(&balance).SetBytes(v)
+	}
 
-		if len(enc) < pos+decodeLength+1 {
-			return nonce, balance, fmt.Errorf(
-				"malformed CBOR for Account.Nonce: %s, Length %d",
-				enc[pos+1:], decodeLength)
+	if fieldSet&4 > 0 {
+		v, err := field("Incarnation")
+		if err != nil {
+			return nonce, balance, incarnation, codeHash, err
 		}
+		incarnation = bytesToUint64(v)
+	}
 
-		(&balance).SetBytes(enc[pos+1 : pos+decodeLength+1])
+	if fieldSet&8 > 0 {
+		v, err := field("CodeHash")
+		if err != nil {
+			return nonce, balance, incarnation, codeHash, err
+		}
+		codeHash = v
 	}
 	return
 }
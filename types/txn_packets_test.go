@@ -231,3 +231,27 @@ func TestTransactionsPacket(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionsPacketDropsBlobTxs(t *testing.T) {
+	require := require.New(t)
+	legacy := hexutility.MustDecodeHex("f867088504a817c8088302e2489435353535353535353535353535353535353535358202008025a064b1702d9298fee62dfeccc57d322a463ad55ca201256d01f62b45b2e1c21c12a064b1702d9298fee62dfeccc57d322a463ad55ca201256d01f62b45b2e1c21c10")
+	canonical, wrapped := blobTxRlp(t, 1, 1)
+	ctx := NewTxParseContext(*uint256.NewInt(1))
+
+	// broadcasted blob tx has no blobs - must not reach the pool
+	slots := &TxSlots{}
+	_, err := ParseTransactions(EncodeTransactions([][]byte{canonical, legacy, canonical}, nil), 0, ctx, slots, nil)
+	require.NoError(err)
+	require.Equal(1, len(slots.Txs))
+	require.Equal(1, slots.Senders.Len())
+	require.Equal(legacy, slots.Txs[0].Rlp)
+
+	// blob tx with blobs is accepted via PooledTransactions
+	slots = &TxSlots{}
+	encodeBuf := EncodePooledTransactions66([][]byte{wrapped, legacy}, 1, nil)
+	_, _, err = ParsePooledTransactions66(encodeBuf, 0, ctx, slots, nil)
+	require.NoError(err)
+	require.Equal(2, len(slots.Txs))
+	require.Equal(BlobTxType, slots.Txs[0].Type)
+	require.Equal(1, len(slots.Txs[0].Blobs))
+}
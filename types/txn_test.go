@@ -22,10 +22,15 @@ import (
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/secp256k1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
 
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/rlp"
 )
 
 func TestParseTransactionRLP(t *testing.T) {
@@ -39,7 +44,7 @@ func TestParseTransactionRLP(t *testing.T) {
 				tt := tt
 				t.Run(strconv.Itoa(i), func(t *testing.T) {
 					payload := hexutility.MustDecodeHex(tt.PayloadStr)
-					parseEnd, err := ctx.ParseTransaction(payload, 0, tx, txSender[:], false /* hasEnvelope */, nil)
+					parseEnd, err := ctx.ParseTransaction(payload, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 					require.NoError(err)
 					require.Equal(len(payload), parseEnd)
 					if tt.SignHashStr != "" {
@@ -74,19 +79,19 @@ func TestTransactionSignatureValidity1(t *testing.T) {
 
 	tx, txSender := &TxSlot{}, [20]byte{}
 	validTxn := hexutility.MustDecodeHex("f83f800182520894095e7baea6a6c7c4c2dfeb977efac326af552d870b801ba048b55bfa915ac795c431978d8a6a992b628d557da5ff759b307d495a3664935301")
-	_, err := ctx.ParseTransaction(validTxn, 0, tx, txSender[:], false /* hasEnvelope */, nil)
+	_, err := ctx.ParseTransaction(validTxn, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.NoError(t, err)
 
 	preEip2Txn := hexutility.MustDecodeHex("f85f800182520894095e7baea6a6c7c4c2dfeb977efac326af552d870b801ba048b55bfa915ac795c431978d8a6a992b628d557da5ff759b307d495a36649353a07fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a1")
-	_, err = ctx.ParseTransaction(preEip2Txn, 0, tx, txSender[:], false /* hasEnvelope */, nil)
+	_, err = ctx.ParseTransaction(preEip2Txn, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.NoError(t, err)
 
 	// Now enforce EIP-2
 	ctx.WithAllowPreEip2s(false)
-	_, err = ctx.ParseTransaction(validTxn, 0, tx, txSender[:], false /* hasEnvelope */, nil)
+	_, err = ctx.ParseTransaction(validTxn, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.NoError(t, err)
 
-	_, err = ctx.ParseTransaction(preEip2Txn, 0, tx, txSender[:], false /* hasEnvelope */, nil)
+	_, err = ctx.ParseTransaction(preEip2Txn, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.Error(t, err)
 }
 
@@ -96,15 +101,139 @@ func TestTransactionSignatureValidity2(t *testing.T) {
 	ctx := NewTxParseContext(*chainId)
 	slot, sender := &TxSlot{}, [20]byte{}
 	rlp := hexutility.MustDecodeHex("02f8720513844190ab00848321560082520894cab441d2f45a3fee83d15c6b6b6c36a139f55b6288054607fc96a6000080c001a0dffe4cb5651e663d0eac8c4d002de734dd24db0f1109b062d17da290a133cc02a0913fb9f53f7a792bcd9e4d7cced1b8545d1ab82c77432b0bc2e9384ba6c250c5")
-	_, err := ctx.ParseTransaction(rlp, 0, slot, sender[:], false /* hasEnvelope */, nil)
+	_, err := ctx.ParseTransaction(rlp, 0, slot, sender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.Error(t, err)
 
 	// Only legacy transactions can happen before EIP-2
 	ctx.WithAllowPreEip2s(true)
-	_, err = ctx.ParseTransaction(rlp, 0, slot, sender[:], false /* hasEnvelope */, nil)
+	_, err = ctx.ParseTransaction(rlp, 0, slot, sender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
 	assert.Error(t, err)
 }
 
+func rlpString(s []byte) []byte {
+	buf := make([]byte, rlp.StringLen(s)+8)
+	return buf[:rlp.EncodeString(s, buf)]
+}
+
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	buf := make([]byte, 10)
+	return append(buf[:rlp.EncodeListPrefix(len(payload), buf)], payload...)
+}
+
+// blobTxRlp - builds signed blob transaction with `blobs` blobs, returns it in canonical and network wrapper forms
+func blobTxRlp(t testing.TB, nonce byte, blobs int) (canonical, wrapped []byte) {
+	t.Helper()
+	var hashes, blobItems, commitments, proofs [][]byte
+	for i := 0; i < blobs; i++ {
+		hash := [32]byte{0x01, byte(i)}
+		hashes = append(hashes, rlpString(hash[:]))
+		blob := make([]byte, fixedgas.BlobSize)
+		blob[0] = byte(i)
+		blobItems = append(blobItems, rlpString(blob))
+		var kzg [LenKZG]byte
+		kzg[0] = byte(i)
+		commitments = append(commitments, rlpString(kzg[:]))
+		proofs = append(proofs, rlpString(kzg[:]))
+	}
+	to := [20]byte{0x11}
+	fields := [][]byte{
+		{0x01},             // chainId
+		{nonce},            // nonce
+		{0x01},             // tip
+		{0x02},             // feeCap
+		{0x82, 0x52, 0x08}, // gas
+		rlpString(to[:]),
+		{0x80},    // value
+		{0x80},    // data
+		rlpList(), // access list
+		{0x05},    // blob fee cap
+		rlpList(hashes...),
+	}
+
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write([]byte{BlobTxType})
+	keccak.Write(rlpList(fields...))
+	key := make([]byte, 32)
+	key[31] = 1
+	sig, err := secp256k1.Sign(keccak.Sum(nil), key)
+	require.NoError(t, err)
+	fields = append(fields, []byte{sig[64]}, rlpString(sig[:32]), rlpString(sig[32:64]))
+	if sig[64] == 0 {
+		fields[len(fields)-3] = []byte{0x80}
+	}
+
+	body := rlpList(fields...)
+	canonical = append([]byte{BlobTxType}, body...)
+	wrapped = append([]byte{BlobTxType}, rlpList(body, rlpList(blobItems...), rlpList(commitments...), rlpList(proofs...))...)
+	return canonical, wrapped
+}
+
+func TestParseBlobTransaction(t *testing.T) {
+	require := require.New(t)
+	ctx := NewTxParseContext(*uint256.NewInt(1))
+	canonical, wrapped := blobTxRlp(t, 3, 2)
+
+	tx, txSender := &TxSlot{}, [20]byte{}
+	p, err := ctx.ParseTransaction(canonical, 0, tx, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
+	require.NoError(err)
+	require.Equal(len(canonical), p)
+	require.Equal(BlobTxType, tx.Type)
+	require.Equal(uint64(3), tx.Nonce)
+	require.Equal(uint64(5), tx.BlobFeeCap.Uint64())
+	require.Equal(2, len(tx.BlobHashes))
+	require.Equal(byte(0x01), tx.BlobHashes[1][0])
+	require.Equal(byte(1), tx.BlobHashes[1][1])
+	require.Nil(tx.Blobs)
+	require.Equal(canonical, tx.Rlp)
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write(canonical)
+	require.Equal(keccak.Sum(nil), tx.IDHash[:])
+
+	wrappedTx, wrappedSender := &TxSlot{}, [20]byte{}
+	p, err = ctx.ParseTransaction(wrapped, 0, wrappedTx, wrappedSender[:], false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
+	require.NoError(err)
+	require.Equal(len(wrapped), p)
+	require.Equal(tx.IDHash, wrappedTx.IDHash) // hash doesn't depend on wrapper
+	require.Equal(txSender, wrappedSender)
+	require.NotEqual([20]byte{}, wrappedSender)
+	require.Equal(tx.BlobHashes, wrappedTx.BlobHashes)
+	require.Equal(2, len(wrappedTx.Blobs))
+	require.Equal(fixedgas.BlobSize, len(wrappedTx.Blobs[1]))
+	require.Equal(byte(1), wrappedTx.Blobs[1][0])
+	require.Equal(2, len(wrappedTx.Commitments))
+	require.Equal(2, len(wrappedTx.Proofs))
+	require.Equal(wrapped, wrappedTx.Rlp)
+
+	// form doesn't match expectation
+	_, err = ctx.ParseTransaction(canonical, 0, &TxSlot{}, txSender[:], false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
+	require.Error(err)
+	_, err = ctx.ParseTransaction(wrapped, 0, &TxSlot{}, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
+	require.Error(err)
+
+	// blob tx without blobs is invalid
+	canonical, _ = blobTxRlp(t, 3, 0)
+	_, err = ctx.ParseTransaction(canonical, 0, &TxSlot{}, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
+	require.Error(err)
+
+	// unknown tx type
+	unknown := common.Copy(canonical)
+	unknown[0] = BlobTxType + 1
+	_, err = ctx.ParseTransaction(unknown, 0, &TxSlot{}, txSender[:], false /* hasEnvelope */, false /* wrappedWithBlobs */, nil)
+	require.Error(err)
+
+	// slot re-use must not keep blob fields of previous tx
+	legacy := hexutility.MustDecodeHex("f86a808459682f0082520894fe3b557e8fb62b89f4916b721be55ceb828dbd73872386f26fc10000801ca0d22fc3eed9b9b9dbef9eec230aa3fb849eff60356c6b34e86155dca5c03554c7a05e3903d7375337f103cb9583d97a59dcca7472908c31614ae240c6a8311b02d6")
+	_, err = ctx.ParseTransaction(legacy, 0, wrappedTx, wrappedSender[:], false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
+	require.NoError(err)
+	require.Nil(wrappedTx.BlobHashes)
+	require.Nil(wrappedTx.Blobs)
+	require.True(wrappedTx.BlobFeeCap.IsZero())
+}
+
 func TestTxSlotsGrowth(t *testing.T) {
 	assert := assert.New(t)
 	s := &TxSlots{}
@@ -168,6 +297,38 @@ func TestDedupHashes(t *testing.T) {
 
 }
 
+func TestDecodeAccount(t *testing.T) {
+	require := require.New(t)
+	codeHash := common.HexToHash("0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
+	// fieldSet: nonce, balance, incarnation, code hash
+	enc := append([]byte{0x0f, 1, 5, 2, 0x03, 0xe8, 1, 2, 32}, codeHash[:]...)
+	nonce, balance, incarnation, hash, err := DecodeAccount(enc)
+	require.NoError(err)
+	require.Equal(uint64(5), nonce)
+	require.Equal(uint64(1000), balance.Uint64())
+	require.Equal(uint64(2), incarnation)
+	require.Equal(codeHash[:], hash)
+
+	nonce, balance, err = DecodeSender(enc)
+	require.NoError(err)
+	require.Equal(uint64(5), nonce)
+	require.Equal(uint64(1000), balance.Uint64())
+
+	_, _, _, _, err = DecodeAccount(enc[:len(enc)-1])
+	require.Error(err)
+	_, _, _, _, err = DecodeAccount(enc[:7])
+	require.Error(err)
+
+	enc = make([]byte, EncodeSenderLengthForStorage(7, *uint256.NewInt(1)))
+	EncodeSender(7, *uint256.NewInt(1), enc)
+	nonce, balance, incarnation, hash, err = DecodeAccount(enc)
+	require.NoError(err)
+	require.Equal(uint64(7), nonce)
+	require.Equal(uint64(1), balance.Uint64())
+	require.Equal(uint64(0), incarnation)
+	require.Nil(hash)
+}
+
 func toHashes(h ...byte) (out Hashes) {
 	for i := range h {
 		hash := [32]byte{h[i]}
@@ -21,12 +21,17 @@ import (
 //}
 
 func FuzzParseTx(f *testing.F) {
-	f.Add([]byte{1}, 0)
-	f.Fuzz(func(t *testing.T, in []byte, pos int) {
+	f.Add([]byte{1}, 0, false)
+	canonical, wrapped := blobTxRlp(f, 1, 1)
+	f.Add(canonical, 0, false)
+	f.Add(canonical, 0, true)
+	f.Add(wrapped, 0, true)
+	f.Add(wrapped, 0, false)
+	f.Fuzz(func(t *testing.T, in []byte, pos int, wrappedWithBlobs bool) {
 		t.Parallel()
 		ctx := NewTxParseContext(*u256.N1)
 		txn := &TxSlot{}
 		sender := make([]byte, 20)
-		_, _ = ctx.ParseTransaction(in, pos, txn, sender, false, nil)
+		_, _ = ctx.ParseTransaction(in, pos, txn, sender, false, wrappedWithBlobs, nil)
 	})
 }